@@ -4,25 +4,26 @@ import BooksPage from './pages/BooksPage';
 import BorrowPage from './pages/BorrowPage';
 import BorrowHistoryPage from './pages/BorrowHistoryPage';
 import BookDetailPage from './pages/BookDetailPage';
+import AuthorPage from './pages/AuthorPage';
 import ReservationsPage from './pages/ReservationsPage';
 import AdminDashboardPage from './pages/AdminDashboardPage';
 import AdminBorrowManagePage from './pages/AdminBorrowManagePage';
 import AdminCopyManagePage from './pages/AdminCopyManagePage';
+import { PAGE_PERMISSIONS, hasPermission } from './services/permissions';
 
 function ProtectedRoute({ children }: { children: JSX.Element }) {
   const isAuthenticated = !!localStorage.getItem('token');
   return isAuthenticated ? children : <Navigate to="/login" replace />;
 }
 
-function AdminRoute({ children }: { children: JSX.Element }) {
+function AdminRoute({ permission, children }: { permission: string; children: JSX.Element }) {
   const isAuthenticated = !!localStorage.getItem('token');
-  const role = localStorage.getItem('role');
 
   if (!isAuthenticated) {
     return <Navigate to="/login" replace />;
   }
 
-  if (role !== 'admin') {
+  if (!hasPermission(permission)) {
     return <Navigate to="/books" replace />;
   }
 
@@ -51,6 +52,14 @@ function App() {
           </ProtectedRoute>
         }
       />
+      <Route
+        path="/authors/:id"
+        element={
+          <ProtectedRoute>
+            <AuthorPage />
+          </ProtectedRoute>
+        }
+      />
       <Route
         path="/borrows"
         element={
@@ -78,7 +87,7 @@ function App() {
       <Route
         path="/admin/dashboard"
         element={
-          <AdminRoute>
+          <AdminRoute permission={PAGE_PERMISSIONS.dashboard}>
             <AdminDashboardPage />
           </AdminRoute>
         }
@@ -86,7 +95,7 @@ function App() {
       <Route
         path="/admin/borrow-manage"
         element={
-          <AdminRoute>
+          <AdminRoute permission={PAGE_PERMISSIONS.borrowManage}>
             <AdminBorrowManagePage />
           </AdminRoute>
         }
@@ -94,7 +103,7 @@ function App() {
       <Route
         path="/admin/copy-manage"
         element={
-          <AdminRoute>
+          <AdminRoute permission={PAGE_PERMISSIONS.copyManage}>
             <AdminCopyManagePage />
           </AdminRoute>
         }
@@ -0,0 +1,90 @@
+import { useState, useEffect } from 'react';
+import { bookAPI } from '../services/api';
+import type { Book } from '../types';
+
+interface BookPickerProps {
+  value: string;
+  onChange: (book: Book | null) => void;
+  emptyLabel: string;
+  // 폼으로 제출할 때 쓰는 select 이름
+  name?: string;
+  required?: boolean;
+}
+
+const PICKER_PAGE_SIZE = 20;
+
+// 검색어로 도서를 찾아 고르는 선택 상자 (전체 도서를 한 번에 불러오지 않고 검색 결과를 페이지로 이어 받는다)
+export default function BookPicker({ value, onChange, emptyLabel, name, required }: BookPickerProps) {
+  const [term, setTerm] = useState('');
+  const [results, setResults] = useState<Book[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [selected, setSelected] = useState<Book | null>(null);
+
+  // 입력이 멈춘 뒤에 검색한다
+  useEffect(() => {
+    const timer = setTimeout(() => search(), 300);
+    return () => clearTimeout(timer);
+  }, [term]);
+
+  const search = async (cursor?: string) => {
+    try {
+      const q = term.trim();
+      const data = await bookAPI.getBooks(
+        { q: q || undefined, sort: q ? 'relevance' : 'title' },
+        { limit: PICKER_PAGE_SIZE, cursor }
+      );
+      setResults((prev) => (cursor ? [...prev, ...data.items] : data.items));
+      setTotal(data.total);
+      setNextCursor(data.next_cursor);
+    } catch (error) {
+      console.error('Failed to search books:', error);
+    }
+  };
+
+  const handleSelect = (bookId: string) => {
+    const book = results.find((b) => b.id === bookId) || null;
+    setSelected(book);
+    onChange(book);
+  };
+
+  // 고른 도서가 새 검색 결과에 없어도 선택 상자에 남겨 둔다
+  const options = selected && value === selected.id && !results.some((b) => b.id === selected.id)
+    ? [selected, ...results]
+    : results;
+
+  return (
+    <div className="space-y-2">
+      <input
+        type="text"
+        value={term}
+        onChange={(e) => setTerm(e.target.value)}
+        placeholder="제목, 저자, ISBN으로 검색"
+        className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
+      />
+      <select
+        name={name}
+        required={required}
+        value={value}
+        onChange={(e) => handleSelect(e.target.value)}
+        className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
+      >
+        <option value="">{emptyLabel}</option>
+        {options.map((book) => (
+          <option key={book.id} value={book.id}>
+            {book.title} - {book.author}
+          </option>
+        ))}
+      </select>
+      {nextCursor && (
+        <button
+          type="button"
+          onClick={() => search(nextCursor)}
+          className="text-sm text-blue-600 hover:text-blue-800"
+        >
+          검색 결과 더 불러오기 ({results.length}/{total})
+        </button>
+      )}
+    </div>
+  );
+}
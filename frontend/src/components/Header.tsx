@@ -1,6 +1,7 @@
 import { Link, useNavigate, useLocation } from 'react-router-dom';
 import { useState, useEffect } from 'react';
 import { authAPI, borrowAPI } from '../services/api';
+import { PAGE_PERMISSIONS, hasPermission, isStaff } from '../services/permissions';
 import NotificationDropdown from './NotificationDropdown';
 
 export default function Header() {
@@ -8,8 +9,7 @@ export default function Header() {
   const location = useLocation();
   const isAuthenticated = !!localStorage.getItem('token');
   const userId = localStorage.getItem('user_id');
-  const role = localStorage.getItem('role');
-  const isAdmin = role === 'admin';
+  const isAdmin = isStaff();
   const [cartCount, setCartCount] = useState(0);
 
   // 대여 개수 로드
@@ -47,6 +47,7 @@ export default function Header() {
       localStorage.removeItem('token');
       localStorage.removeItem('user_id');
       localStorage.removeItem('role');
+      localStorage.removeItem('permissions');
       navigate('/login');
     }
   };
@@ -89,24 +90,30 @@ export default function Header() {
                 )}
                 {isAdmin && (
                   <>
-                    <Link
-                      to="/admin/dashboard"
-                      className="hover:text-blue-200 transition-colors"
-                    >
-                      대시보드
-                    </Link>
-                    <Link
-                      to="/admin/borrow-manage"
-                      className="hover:text-blue-200 transition-colors"
-                    >
-                      대여 관리
-                    </Link>
-                    <Link
-                      to="/admin/copy-manage"
-                      className="hover:text-blue-200 transition-colors"
-                    >
-                      복본 관리
-                    </Link>
+                    {hasPermission(PAGE_PERMISSIONS.dashboard) && (
+                      <Link
+                        to="/admin/dashboard"
+                        className="hover:text-blue-200 transition-colors"
+                      >
+                        대시보드
+                      </Link>
+                    )}
+                    {hasPermission(PAGE_PERMISSIONS.borrowManage) && (
+                      <Link
+                        to="/admin/borrow-manage"
+                        className="hover:text-blue-200 transition-colors"
+                      >
+                        대여 관리
+                      </Link>
+                    )}
+                    {hasPermission(PAGE_PERMISSIONS.copyManage) && (
+                      <Link
+                        to="/admin/copy-manage"
+                        className="hover:text-blue-200 transition-colors"
+                      >
+                        복본 관리
+                      </Link>
+                    )}
                   </>
                 )}
               </nav>
@@ -115,7 +122,7 @@ export default function Header() {
           {isAuthenticated && (
             <div className="flex items-center space-x-4">
               <span className="text-sm">
-                환영합니다, {userId}님{isAdmin && ' (관리자)'}
+                환영합니다, {userId}님{isAdmin && ' (직원)'}
               </span>
 
               {/* 알림 드롭다운 - 일반 사용자만 표시 */}
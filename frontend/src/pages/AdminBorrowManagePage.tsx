@@ -1,13 +1,16 @@
 import { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import Header from '../components/Header';
+import BookPicker from '../components/BookPicker';
 import { borrowAPI, bookAPI } from '../services/api';
+import { PAGE_PERMISSIONS, hasPermission } from '../services/permissions';
 import type { BorrowItem, Book } from '../types';
 
 export default function AdminBorrowManagePage() {
   const navigate = useNavigate();
   const [borrows, setBorrows] = useState<BorrowItem[]>([]);
-  const [books, setBooks] = useState<Book[]>([]);
+  const [bookTotal, setBookTotal] = useState(0);
+  const [pickedBook, setPickedBook] = useState<Book | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [showBorrowModal, setShowBorrowModal] = useState(false);
@@ -16,9 +19,8 @@ export default function AdminBorrowManagePage() {
   const [processing, setProcessing] = useState(false);
 
   useEffect(() => {
-    const role = localStorage.getItem('role');
-    if (role !== 'admin') {
-      alert('관리자 권한이 필요합니다.');
+    if (!hasPermission(PAGE_PERMISSIONS.borrowManage)) {
+      alert('이 화면을 볼 권한이 없습니다.');
       navigate('/books');
       return;
     }
@@ -30,10 +32,10 @@ export default function AdminBorrowManagePage() {
       setLoading(true);
       const [borrowsData, booksData] = await Promise.all([
         borrowAPI.adminGetAllBorrows(),
-        bookAPI.getBooks(),
+        bookAPI.getBooks(undefined, { limit: 1 }), // 보유 도서 수는 total만 쓴다
       ]);
       setBorrows(borrowsData);
-      setBooks(booksData);
+      setBookTotal(booksData.total);
     } catch (err: any) {
       setError(err.response?.data?.error || '데이터를 불러오는데 실패했습니다.');
     } finally {
@@ -132,7 +134,7 @@ export default function AdminBorrowManagePage() {
           </div>
           <div className="bg-white rounded-lg shadow p-6">
             <div className="text-sm text-green-600 mb-1">보유 도서</div>
-            <div className="text-3xl font-bold text-green-800">{books.length}</div>
+            <div className="text-3xl font-bold text-green-800">{bookTotal}</div>
           </div>
         </div>
 
@@ -215,31 +217,23 @@ export default function AdminBorrowManagePage() {
         {/* 신규 대여 등록 */}
         <div className="bg-white rounded-lg shadow-md p-6">
           <h3 className="text-xl font-bold text-gray-800 mb-4">신규 대여 등록</h3>
-          <p className="text-gray-600 mb-4">도서를 선택하여 사용자에게 대여를 등록하세요.</p>
-          <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4 gap-4">
-            {books.slice(0, 12).map((book) => (
-              <div key={book.id} className="border rounded-lg p-4 hover:shadow-lg transition-shadow">
-                <h4 className="font-bold text-gray-800 mb-1 line-clamp-1">{book.title}</h4>
-                <p className="text-sm text-gray-600 mb-2">{book.author}</p>
-                <button
-                  onClick={() => handleOpenBorrowModal(book)}
-                  className="w-full bg-blue-600 hover:bg-blue-700 text-white px-4 py-2 rounded-lg text-sm font-medium transition-colors"
-                >
-                  대여 등록
-                </button>
-              </div>
-            ))}
-          </div>
-          {books.length > 12 && (
-            <div className="mt-4 text-center">
-              <button
-                onClick={() => navigate('/books')}
-                className="text-blue-600 hover:text-blue-800 font-medium"
-              >
-                전체 도서 보기 →
-              </button>
+          <p className="text-gray-600 mb-4">도서를 검색해 선택한 뒤 사용자에게 대여를 등록하세요.</p>
+          <div className="flex flex-col md:flex-row md:items-start gap-4">
+            <div className="flex-1">
+              <BookPicker
+                value={pickedBook?.id || ''}
+                onChange={setPickedBook}
+                emptyLabel="도서를 선택하세요"
+              />
             </div>
-          )}
+            <button
+              onClick={() => pickedBook && handleOpenBorrowModal(pickedBook)}
+              disabled={!pickedBook}
+              className="bg-blue-600 hover:bg-blue-700 text-white px-6 py-2 rounded-lg text-sm font-medium transition-colors disabled:bg-gray-400"
+            >
+              대여 등록
+            </button>
+          </div>
         </div>
 
         {/* 대여 등록 모달 */}
@@ -1,13 +1,14 @@
 import { useState, useEffect } from 'react';
 import Header from '../components/Header';
+import BookPicker from '../components/BookPicker';
 import { bookAPI } from '../services/api';
-import type { Book } from '../types';
+import type { CopyStatus, CopyStatusChange, CopyStatusInfo } from '../types';
 
 interface CopyWithBook {
   id: number;
   book_id: string;
   copy_number: number;
-  status: 'available' | 'borrowed' | 'maintenance' | 'lost';
+  status: CopyStatus;
   location: string;
   acquired_date: string;
   notes: string;
@@ -17,27 +18,37 @@ interface CopyWithBook {
 
 export default function AdminCopyManagePage() {
   const [copies, setCopies] = useState<CopyWithBook[]>([]);
-  const [books, setBooks] = useState<Book[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
   const [filterBookId, setFilterBookId] = useState('');
+  const [addBookId, setAddBookId] = useState('');
   const [editingCopy, setEditingCopy] = useState<CopyWithBook | null>(null);
+  const [editingFrom, setEditingFrom] = useState<CopyStatus | null>(null);
+  const [reason, setReason] = useState('');
+  const [reasonNote, setReasonNote] = useState('');
   const [showAddModal, setShowAddModal] = useState(false);
+  const [statusInfo, setStatusInfo] = useState<CopyStatusInfo | null>(null);
+  const [historyCopy, setHistoryCopy] = useState<CopyWithBook | null>(null);
+  const [history, setHistory] = useState<CopyStatusChange[]>([]);
+
+  useEffect(() => {
+    bookAPI.adminGetCopyStatuses().then(setStatusInfo).catch(() => setStatusInfo(null));
+  }, []);
 
   useEffect(() => {
     loadData();
   }, [filterBookId]);
 
-  const loadData = async () => {
+  const loadData = async (cursor?: string) => {
     try {
-      setLoading(true);
-      const [copiesData, booksData] = await Promise.all([
-        bookAPI.adminGetAllCopies(filterBookId || undefined),
-        bookAPI.getBooks(),
-      ]);
-      setCopies(copiesData);
-      setBooks(booksData);
+      if (!cursor) setLoading(true);
+      const data = await bookAPI.adminGetAllCopies(filterBookId || undefined, { cursor });
+      setCopies((prev) => (cursor ? [...prev, ...data.items] : data.items));
+      setTotal(data.total);
+      setNextCursor(data.next_cursor);
     } catch (err: any) {
       setError(err.response?.data?.error || '데이터를 불러오는데 실패했습니다.');
     } finally {
@@ -49,12 +60,15 @@ export default function AdminCopyManagePage() {
     try {
       await bookAPI.adminUpdateCopy(copy.id, {
         status: copy.status,
+        reason: copy.status !== editingFrom ? reason : undefined,
+        reason_note: copy.status !== editingFrom ? reasonNote : undefined,
         location: copy.location,
         acquired_date: copy.acquired_date,
         notes: copy.notes,
       });
       setSuccess('복본이 수정되었습니다.');
       setEditingCopy(null);
+      setEditingFrom(null);
       loadData();
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
@@ -63,16 +77,44 @@ export default function AdminCopyManagePage() {
     }
   };
 
+  const startEditing = (copy: CopyWithBook) => {
+    setEditingCopy(copy);
+    setEditingFrom(copy.status);
+    setReason('');
+    setReasonNote('');
+  };
+
+  // 현재 상태에서 옮겨 갈 수 있는 상태 (대출과 제적은 각각 대출 처리, 제적 처리에서만 가능)
+  const nextStatuses = (from: CopyStatus): CopyStatus[] =>
+    (statusInfo?.transitions[from] || []).filter((s) => s !== 'borrowed' && s !== 'withdrawn');
+
+  // 목표 상태에 쓸 수 있는 사유 코드
+  const reasonsFor = (to: CopyStatus) =>
+    Object.entries(statusInfo?.reasons || {}).filter(
+      ([code, r]) => code !== 'acquired' && code !== 'checkout' && code !== 'checkin' && (!r.to || r.to.includes(to))
+    );
+
+  const openHistory = async (copy: CopyWithBook) => {
+    try {
+      setHistory(await bookAPI.adminGetCopyHistory(copy.id));
+      setHistoryCopy(copy);
+    } catch (err: any) {
+      setError(err.response?.data?.error || '상태 이력을 불러오지 못했습니다.');
+      setTimeout(() => setError(''), 3000);
+    }
+  };
+
   const handleDeleteCopy = async (id: number) => {
-    if (!confirm('정말 이 복본을 삭제하시겠습니까?')) return;
+    const note = prompt('이 복본을 제적합니다. 제적 메모를 입력하세요 (취소하면 제적하지 않습니다).', '');
+    if (note === null) return;
 
     try {
-      await bookAPI.adminDeleteCopy(id);
-      setSuccess('복본이 삭제되었습니다.');
+      await bookAPI.adminDeleteCopy(id, 'other', note.trim() || undefined);
+      setSuccess('복본이 제적되었습니다.');
       loadData();
       setTimeout(() => setSuccess(''), 3000);
     } catch (err: any) {
-      setError(err.response?.data?.error || '복본 삭제에 실패했습니다.');
+      setError(err.response?.data?.error || '복본 제적에 실패했습니다.');
       setTimeout(() => setError(''), 3000);
     }
   };
@@ -106,6 +148,9 @@ export default function AdminCopyManagePage() {
         return 'bg-green-100 text-green-800';
       case 'borrowed':
         return 'bg-blue-100 text-blue-800';
+      case 'on_hold':
+      case 'in_transit':
+        return 'bg-purple-100 text-purple-800';
       case 'maintenance':
         return 'bg-yellow-100 text-yellow-800';
       case 'lost':
@@ -116,6 +161,8 @@ export default function AdminCopyManagePage() {
   };
 
   const getStatusLabel = (status: string) => {
+    const label = statusInfo?.statuses[status as CopyStatus];
+    if (label) return label;
     switch (status) {
       case 'available':
         return '대여 가능';
@@ -150,7 +197,10 @@ export default function AdminCopyManagePage() {
         <div className="flex justify-between items-center mb-6">
           <h1 className="text-3xl font-bold text-gray-800">복본 관리</h1>
           <button
-            onClick={() => setShowAddModal(true)}
+            onClick={() => {
+              setAddBookId('');
+              setShowAddModal(true);
+            }}
             className="bg-blue-600 hover:bg-blue-700 text-white px-6 py-3 rounded-lg transition-colors"
           >
             + 복본 추가
@@ -170,18 +220,11 @@ export default function AdminCopyManagePage() {
           <label className="block text-sm font-medium text-gray-700 mb-2">
             도서로 필터
           </label>
-          <select
+          <BookPicker
             value={filterBookId}
-            onChange={(e) => setFilterBookId(e.target.value)}
-            className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
-          >
-            <option value="">전체 도서</option>
-            {books.map((book) => (
-              <option key={book.id} value={book.id}>
-                {book.title} - {book.author}
-              </option>
-            ))}
-          </select>
+            onChange={(book) => setFilterBookId(book?.id || '')}
+            emptyLabel="전체 도서"
+          />
         </div>
 
         {/* 복본 테이블 */}
@@ -237,19 +280,45 @@ export default function AdminCopyManagePage() {
                           <td className="px-6 py-4">
                             <select
                               value={editingCopy.status}
-                              onChange={(e) =>
+                              onChange={(e) => {
                                 setEditingCopy({
                                   ...editingCopy,
-                                  status: e.target.value as any,
-                                })
-                              }
+                                  status: e.target.value as CopyStatus,
+                                });
+                                setReason('');
+                              }}
                               className="px-2 py-1 border rounded"
                             >
-                              <option value="available">대여 가능</option>
-                              <option value="borrowed">대여 중</option>
-                              <option value="maintenance">정비 중</option>
-                              <option value="lost">분실</option>
+                              <option value={copy.status}>{getStatusLabel(copy.status)}</option>
+                              {nextStatuses(copy.status).map((s) => (
+                                <option key={s} value={s}>
+                                  {getStatusLabel(s)}
+                                </option>
+                              ))}
                             </select>
+                            {editingCopy.status !== copy.status && (
+                              <div className="mt-2 space-y-1">
+                                <select
+                                  value={reason}
+                                  onChange={(e) => setReason(e.target.value)}
+                                  className="px-2 py-1 border rounded w-full"
+                                >
+                                  <option value="">사유 선택</option>
+                                  {reasonsFor(editingCopy.status).map(([code, r]) => (
+                                    <option key={code} value={code}>
+                                      {r.label}
+                                    </option>
+                                  ))}
+                                </select>
+                                <input
+                                  type="text"
+                                  value={reasonNote}
+                                  placeholder="메모 (선택)"
+                                  onChange={(e) => setReasonNote(e.target.value)}
+                                  className="px-2 py-1 border rounded w-full"
+                                />
+                              </div>
+                            )}
                           </td>
                           <td className="px-6 py-4">
                             <input
@@ -292,7 +361,10 @@ export default function AdminCopyManagePage() {
                               저장
                             </button>
                             <button
-                              onClick={() => setEditingCopy(null)}
+                              onClick={() => {
+                                setEditingCopy(null);
+                                setEditingFrom(null);
+                              }}
                               className="text-gray-600 hover:text-gray-900"
                             >
                               취소
@@ -326,16 +398,22 @@ export default function AdminCopyManagePage() {
                           <td className="px-6 py-4 text-sm text-gray-500">{copy.notes}</td>
                           <td className="px-6 py-4 text-sm">
                             <button
-                              onClick={() => setEditingCopy(copy)}
+                              onClick={() => startEditing(copy)}
                               className="text-blue-600 hover:text-blue-900 mr-3"
                             >
                               수정
                             </button>
+                            <button
+                              onClick={() => openHistory(copy)}
+                              className="text-gray-600 hover:text-gray-900 mr-3"
+                            >
+                              이력
+                            </button>
                             <button
                               onClick={() => handleDeleteCopy(copy.id)}
                               className="text-red-600 hover:text-red-900"
                             >
-                              삭제
+                              제적
                             </button>
                           </td>
                         </>
@@ -347,6 +425,17 @@ export default function AdminCopyManagePage() {
             </table>
           </div>
         </div>
+
+        {nextCursor && (
+          <div className="flex justify-center mt-6">
+            <button
+              onClick={() => loadData(nextCursor)}
+              className="bg-gray-600 hover:bg-gray-700 text-white px-6 py-2 rounded-lg font-medium transition-colors"
+            >
+              더 보기 ({copies.length}/{total})
+            </button>
+          </div>
+        )}
       </div>
 
       {/* 추가 모달 */}
@@ -360,18 +449,13 @@ export default function AdminCopyManagePage() {
                   <label className="block text-sm font-medium text-gray-700 mb-2">
                     도서 선택
                   </label>
-                  <select
+                  <BookPicker
                     name="book_id"
                     required
-                    className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
-                  >
-                    <option value="">도서를 선택하세요</option>
-                    {books.map((book) => (
-                      <option key={book.id} value={book.id}>
-                        {book.title} - {book.author}
-                      </option>
-                    ))}
-                  </select>
+                    value={addBookId}
+                    onChange={(book) => setAddBookId(book?.id || '')}
+                    emptyLabel="도서를 선택하세요"
+                  />
                 </div>
                 <div>
                   <label className="block text-sm font-medium text-gray-700 mb-2">
@@ -394,10 +478,9 @@ export default function AdminCopyManagePage() {
                     required
                     className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
                   >
-                    <option value="available">대여 가능</option>
-                    <option value="borrowed">대여 중</option>
-                    <option value="maintenance">정비 중</option>
-                    <option value="lost">분실</option>
+                    <option value="available">{getStatusLabel('available')}</option>
+                    <option value="in_transit">{getStatusLabel('in_transit')}</option>
+                    <option value="maintenance">{getStatusLabel('maintenance')}</option>
                   </select>
                 </div>
                 <div>
@@ -452,6 +535,48 @@ export default function AdminCopyManagePage() {
           </div>
         </div>
       )}
+
+      {/* 상태 이력 모달 */}
+      {historyCopy && (
+        <div className="fixed inset-0 bg-black bg-opacity-50 flex items-center justify-center z-50">
+          <div className="bg-white rounded-lg p-8 max-w-2xl w-full mx-4 max-h-[80vh] overflow-y-auto">
+            <h2 className="text-2xl font-bold mb-2">상태 이력</h2>
+            <p className="text-sm text-gray-500 mb-6">
+              {historyCopy.book_title} · 복본 {historyCopy.copy_number}
+            </p>
+            {history.length === 0 ? (
+              <p className="text-gray-500">기록된 이력이 없습니다.</p>
+            ) : (
+              <ul className="divide-y divide-gray-200">
+                {history.map((h) => (
+                  <li key={h.id} className="py-3 text-sm">
+                    <div className="flex justify-between">
+                      <span className="font-medium text-gray-900">
+                        {h.from_status ? `${getStatusLabel(h.from_status)} → ` : ''}
+                        {getStatusLabel(h.to_status)}
+                      </span>
+                      <span className="text-gray-500">
+                        {new Date(h.changed_at).toLocaleString('ko-KR')}
+                      </span>
+                    </div>
+                    <div className="text-gray-600">
+                      {h.reason_label || h.reason} · {h.actor}
+                      {h.note && <span className="text-gray-500"> — {h.note}</span>}
+                    </div>
+                  </li>
+                ))}
+              </ul>
+            )}
+            <button
+              type="button"
+              onClick={() => setHistoryCopy(null)}
+              className="mt-6 w-full bg-gray-300 hover:bg-gray-400 text-gray-800 py-2 rounded-lg transition-colors"
+            >
+              닫기
+            </button>
+          </div>
+        </div>
+      )}
     </div>
   );
 }
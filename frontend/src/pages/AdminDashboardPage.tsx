@@ -3,20 +3,23 @@ import { useNavigate } from 'react-router-dom';
 import { LineChart, Line, BarChart, Bar, PieChart, Pie, Cell, XAxis, YAxis, CartesianGrid, Tooltip, Legend, ResponsiveContainer } from 'recharts';
 import Header from '../components/Header';
 import { borrowAPI, bookAPI } from '../services/api';
-import type { BorrowItem, Book } from '../types';
+import { PAGE_PERMISSIONS, hasPermission } from '../services/permissions';
+import type { BorrowItem } from '../types';
 
 export default function AdminDashboardPage() {
   const navigate = useNavigate();
   const [allBorrows, setAllBorrows] = useState<BorrowItem[]>([]);
   const [borrowHistory, setBorrowHistory] = useState<BorrowItem[]>([]);
-  const [books, setBooks] = useState<Book[]>([]);
+  const [bookTotal, setBookTotal] = useState(0);
+  const [totalCopies, setTotalCopies] = useState(0);
+  const [borrowedCopies, setBorrowedCopies] = useState(0);
+  const [returnedTotal, setReturnedTotal] = useState(0);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
 
   useEffect(() => {
-    const role = localStorage.getItem('role');
-    if (role !== 'admin') {
-      alert('관리자 권한이 필요합니다.');
+    if (!hasPermission(PAGE_PERMISSIONS.dashboard)) {
+      alert('이 화면을 볼 권한이 없습니다.');
       navigate('/books');
       return;
     }
@@ -27,14 +30,21 @@ export default function AdminDashboardPage() {
     try {
       setLoading(true);
       const userId = localStorage.getItem('user_id') || 'admin';
-      const [activeBorrows, history, booksData] = await Promise.all([
+      // 개수는 limit 1로 불러 페이지 응답의 total을 쓴다
+      const [activeBorrows, history, returned, booksData, copiesData, borrowedData] = await Promise.all([
         borrowAPI.adminGetAllBorrows(),
-        borrowAPI.getBorrowHistory(), // 관리자도 자신의 이력을 조회할 수 있음
-        bookAPI.getBooks(),
+        loadRecentHistory(),
+        borrowAPI.adminGetAllHistory({ limit: 1 }, 'returned'),
+        bookAPI.getBooks(undefined, { limit: 1 }),
+        bookAPI.adminGetAllCopies(undefined, { limit: 1 }),
+        bookAPI.adminGetAllCopies(undefined, { limit: 1 }, 'borrowed'),
       ]);
       setAllBorrows(activeBorrows);
       setBorrowHistory(history);
-      setBooks(booksData);
+      setReturnedTotal(returned.total);
+      setBookTotal(booksData.total);
+      setTotalCopies(copiesData.total);
+      setBorrowedCopies(borrowedData.total);
     } catch (err: any) {
       setError(err.response?.data?.error || '데이터를 불러오는데 실패했습니다.');
     } finally {
@@ -42,6 +52,22 @@ export default function AdminDashboardPage() {
     }
   };
 
+  // 월별 통계에 쓰는 최근 6개월 대여 이력 (최근 대여 순이므로 기간을 벗어나면 그만 받는다)
+  const loadRecentHistory = async () => {
+    const now = new Date();
+    const since = new Date(now.getFullYear(), now.getMonth() - 5, 1);
+    const items: BorrowItem[] = [];
+    let cursor: string | undefined;
+    do {
+      const page = await borrowAPI.adminGetAllHistory({ limit: 100, cursor });
+      items.push(...page.items);
+      const last = page.items[page.items.length - 1];
+      if (!last || new Date(last.borrowed_at) < since) break;
+      cursor = page.next_cursor ?? undefined;
+    } while (cursor);
+    return items;
+  };
+
   const isOverdue = (dueDate: string) => {
     return new Date(dueDate) < new Date();
   };
@@ -86,7 +112,7 @@ export default function AdminDashboardPage() {
   const getStatusStats = () => {
     const borrowed = allBorrows.filter(b => !isOverdue(b.due_date)).length;
     const overdue = overdueCount;
-    const returned = borrowHistory.filter(b => b.status === 'returned').length;
+    const returned = returnedTotal;
 
     return [
       { name: '대여 중', value: borrowed, color: '#3b82f6' },
@@ -108,11 +134,6 @@ export default function AdminDashboardPage() {
     .sort(([, a], [, b]) => b - a)
     .slice(0, 5);
 
-  // 복본 가용성 통계
-  const totalCopies = books.reduce((sum, book) => sum + book.total_copies, 0);
-  const availableCopies = books.reduce((sum, book) => sum + book.available_copies, 0);
-  const borrowedCopies = totalCopies - availableCopies;
-
   const COLORS = ['#3b82f6', '#ef4444', '#10b981'];
 
   return (
@@ -143,7 +164,7 @@ export default function AdminDashboardPage() {
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M12 6.253v13m0-13C10.832 5.477 9.246 5 7.5 5S4.168 5.477 3 6.253v13C4.168 18.477 5.754 18 7.5 18s3.332.477 4.5 1.253m0-13C13.168 5.477 14.754 5 16.5 5c1.747 0 3.332.477 4.5 1.253v13C19.832 18.477 18.247 18 16.5 18c-1.746 0-3.332.477-4.5 1.253" />
                   </svg>
                 </div>
-                <div className="text-4xl font-bold">{books.length}</div>
+                <div className="text-4xl font-bold">{bookTotal}</div>
                 <div className="text-xs opacity-80 mt-1">총 {totalCopies}권의 복본</div>
               </div>
 
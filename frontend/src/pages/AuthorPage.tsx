@@ -0,0 +1,98 @@
+import { useState, useEffect } from 'react';
+import { useParams, useNavigate } from 'react-router-dom';
+import Header from '../components/Header';
+import { bookAPI } from '../services/api';
+import type { Author, AuthorWork, ContributorRole } from '../types';
+
+const roleLabels: Record<ContributorRole, string> = {
+  author: '지은이',
+  translator: '옮긴이',
+  editor: '엮은이',
+  illustrator: '그린이',
+};
+
+export default function AuthorPage() {
+  const { id } = useParams<{ id: string }>();
+  const navigate = useNavigate();
+  const [author, setAuthor] = useState<Author | null>(null);
+  const [works, setWorks] = useState<AuthorWork[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    if (id) {
+      loadAuthor(id);
+    }
+  }, [id]);
+
+  const loadAuthor = async (authorId: string) => {
+    try {
+      setLoading(true);
+      const data = await bookAPI.getAuthor(authorId);
+      setAuthor(data.author);
+      setWorks(data.works);
+    } catch (err: any) {
+      setError(err.response?.data?.error || '저자 정보를 불러오는데 실패했습니다.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-gray-100">
+      <Header />
+      <div className="container mx-auto px-4 py-8">
+        {loading ? (
+          <div className="flex justify-center items-center h-64">
+            <div className="text-gray-600">로딩 중...</div>
+          </div>
+        ) : error || !author ? (
+          <div className="bg-red-50 text-red-600 p-4 rounded-lg">
+            {error || '저자를 찾을 수 없습니다.'}
+          </div>
+        ) : (
+          <>
+            <div className="bg-white rounded-lg shadow-md p-6 mb-6">
+              <h2 className="text-3xl font-bold text-gray-800">{author.name}</h2>
+              {(author.birth_year || author.death_year) && (
+                <p className="text-gray-500 mt-1">
+                  {author.birth_year ?? '?'} - {author.death_year ?? ''}
+                </p>
+              )}
+              {author.variant_names && (
+                <p className="text-gray-600 mt-2">
+                  <span className="font-medium">다른 표기:</span> {author.variant_names}
+                </p>
+              )}
+              {author.note && <p className="text-gray-700 mt-2">{author.note}</p>}
+            </div>
+
+            <h3 className="text-xl font-bold text-gray-800 mb-4">작품 ({works.length})</h3>
+            <div className="bg-white rounded-lg shadow-md divide-y">
+              {works.map((work) => (
+                <div
+                  key={work.id}
+                  onClick={() => navigate(`/books/${work.id}`)}
+                  className="p-4 flex items-center justify-between hover:bg-gray-50 cursor-pointer"
+                >
+                  <div>
+                    <p className="font-semibold text-gray-800">{work.title}</p>
+                    <p className="text-sm text-gray-500">
+                      {work.publisher} ({work.year}) · {work.roles.map((r) => roleLabels[r]).join(', ')}
+                    </p>
+                  </div>
+                  <span className="text-sm text-gray-600">
+                    대여 가능 {work.available_copies}/{work.total_copies}
+                  </span>
+                </div>
+              ))}
+              {works.length === 0 && (
+                <div className="p-8 text-center text-gray-500">등록된 작품이 없습니다.</div>
+              )}
+            </div>
+          </>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -2,6 +2,7 @@ import { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import Header from '../components/Header';
 import { bookAPI, reservationAPI } from '../services/api';
+import { isStaff } from '../services/permissions';
 import type { Book } from '../types';
 
 export default function BookDetailPage() {
@@ -12,7 +13,7 @@ export default function BookDetailPage() {
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
   const userId = localStorage.getItem('user_id');
-  const role = localStorage.getItem('role');
+  const staff = isStaff();
 
   useEffect(() => {
     if (id) {
@@ -142,7 +143,24 @@ export default function BookDetailPage() {
                 </div>
                 <div className="flex">
                   <span className="font-semibold text-gray-700 w-24">저자:</span>
-                  <span className="text-gray-600">{book.author}</span>
+                  {book.contributors && book.contributors.length > 0 ? (
+                    <span className="text-gray-600">
+                      {book.contributors.map((ct, i) => (
+                        <span key={`${ct.author_id}-${ct.role}`}>
+                          {i > 0 && ', '}
+                          <button
+                            onClick={() => navigate(`/authors/${ct.author_id}`)}
+                            className="text-blue-600 hover:underline"
+                          >
+                            {ct.name}
+                          </button>{' '}
+                          <span className="text-gray-400 text-sm">({ct.role_label})</span>
+                        </span>
+                      ))}
+                    </span>
+                  ) : (
+                    <span className="text-gray-600">{book.author}</span>
+                  )}
                 </div>
                 <div className="flex">
                   <span className="font-semibold text-gray-700 w-24">출판사:</span>
@@ -152,12 +170,32 @@ export default function BookDetailPage() {
                   <span className="font-semibold text-gray-700 w-24">출판연도:</span>
                   <span className="text-gray-600">{book.year}</span>
                 </div>
+                {book.subjects && book.subjects.length > 0 && (
+                  <div className="flex">
+                    <span className="font-semibold text-gray-700 w-24">주제:</span>
+                    <span className="text-gray-600">
+                      {book.subjects.map((s) => `${s.code} ${s.name}`).join(', ')}
+                    </span>
+                  </div>
+                )}
                 <div className="flex">
                   <span className="font-semibold text-gray-700 w-24">소장:</span>
                   <span className="text-gray-600">
                     전체 {book.total_copies}권 / 대여 가능 {book.available_copies}권
                   </span>
                 </div>
+                {book.holdings && book.holdings.length > 1 && (
+                  <div className="flex">
+                    <span className="font-semibold text-gray-700 w-24">분관별:</span>
+                    <ul className="text-gray-600">
+                      {book.holdings.map((h) => (
+                        <li key={h.branch_id}>
+                          {h.name} {h.total_copies}권 / 대여 가능 {h.available_copies}권
+                        </li>
+                      ))}
+                    </ul>
+                  </div>
+                )}
                 <div className="flex">
                   <span className="font-semibold text-gray-700 w-24">대여 상태:</span>
                   {book.available_copies > 0 ? (
@@ -176,7 +214,7 @@ export default function BookDetailPage() {
               </div>
 
               {/* 예약 버튼 - 일반 사용자이고 도서가 모두 대여 중일 때만 표시 */}
-              {role !== 'admin' && book.available_copies === 0 && (
+              {!staff && book.available_copies === 0 && (
                 <div className="mb-6">
                   <button
                     onClick={handleReserve}
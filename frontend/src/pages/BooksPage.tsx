@@ -2,32 +2,80 @@ import { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import Header from '../components/Header';
 import { bookAPI, BookFilters } from '../services/api';
-import type { Book } from '../types';
+import type { Book, BookPage, FacetName, Subject, Suggestion } from '../types';
+
+const facetTitles: Record<FacetName, string> = {
+  availability: '대여 상태',
+  year: '출판년도',
+  subject: '주제',
+  publisher: '출판사',
+  author: '저자',
+};
+
+const escapeHtml = (text: string) =>
+  text.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
 
 export default function BooksPage() {
   const navigate = useNavigate();
   const [books, setBooks] = useState<Book[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [activeFilters, setActiveFilters] = useState<BookFilters>({});
+  const [facets, setFacets] = useState<BookPage['facets']>({});
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
 
   // 필터 상태
   const [filters, setFilters] = useState<BookFilters>({
-    search: '',
+    q: '',
     author: '',
     publisher: '',
     year: '',
+    subject: '',
+    sort: 'relevance',
   });
   const [showFilters, setShowFilters] = useState(false);
+  const [subjects, setSubjects] = useState<Subject[]>([]);
+  const [suggestions, setSuggestions] = useState<Suggestion[]>([]);
 
   useEffect(() => {
     loadBooks();
+    // 주류와 강목까지만 선택 목록에 표시
+    bookAPI.getSubjects(2).then(setSubjects).catch(() => setSubjects([]));
   }, []);
 
-  const loadBooks = async (searchFilters?: BookFilters) => {
+  // 입력이 멈추면 자동완성 후보 조회
+  useEffect(() => {
+    const q = filters.q?.trim() ?? '';
+    if (!q) {
+      setSuggestions([]);
+      return;
+    }
+    const timer = setTimeout(() => {
+      bookAPI.suggest(q).then(setSuggestions).catch(() => setSuggestions([]));
+    }, 150);
+    return () => clearTimeout(timer);
+  }, [filters.q]);
+
+  const handleSelectSuggestion = (suggestion: Suggestion) => {
+    setSuggestions([]);
+    if (suggestion.book_id) {
+      navigate(`/books/${suggestion.book_id}`);
+      return;
+    }
+    setFilters({ ...filters, q: suggestion.text });
+    loadBooks({ q: suggestion.text });
+  };
+
+  const loadBooks = async (searchFilters: BookFilters = {}, cursor?: string) => {
     try {
-      setLoading(true);
-      const data = await bookAPI.getBooks(searchFilters);
-      setBooks(data);
+      if (!cursor) setLoading(true);
+      const data = await bookAPI.getBooks({ ...searchFilters, withFacets: !cursor }, { cursor });
+      setBooks((prev) => (cursor ? [...prev, ...data.items] : data.items));
+      if (!cursor) setFacets(data.facets ?? {});
+      setTotal(data.total);
+      setNextCursor(data.next_cursor);
+      setActiveFilters(searchFilters);
     } catch (err: any) {
       setError(err.response?.data?.error || '도서 목록을 불러오는데 실패했습니다.');
     } finally {
@@ -36,21 +84,33 @@ export default function BooksPage() {
   };
 
   const handleSearch = () => {
+    setSuggestions([]);
     const activeFilters: BookFilters = {};
-    if (filters.search) activeFilters.search = filters.search;
+    if (filters.q) activeFilters.q = filters.q;
     if (filters.author) activeFilters.author = filters.author;
     if (filters.publisher) activeFilters.publisher = filters.publisher;
     if (filters.year) activeFilters.year = filters.year;
+    if (filters.subject) activeFilters.subject = filters.subject;
+    // 검색어가 없으면 관련도 정렬을 사용할 수 없음
+    if (filters.sort && (filters.q || filters.sort !== 'relevance')) activeFilters.sort = filters.sort;
 
     loadBooks(activeFilters);
   };
 
+  const handleToggleFacet = (name: FacetName, value: string) => {
+    const selected = activeFilters.facets?.[name] ?? [];
+    const values = selected.includes(value) ? selected.filter((v) => v !== value) : [...selected, value];
+    loadBooks({ ...activeFilters, facets: { ...activeFilters.facets, [name]: values } });
+  };
+
   const handleReset = () => {
     setFilters({
-      search: '',
+      q: '',
       author: '',
       publisher: '',
       year: '',
+      subject: '',
+      sort: 'relevance',
     });
     loadBooks();
   };
@@ -73,16 +133,43 @@ export default function BooksPage() {
         {/* 검색 및 필터 영역 */}
         <div className="bg-white rounded-lg shadow-md p-6 mb-6">
           <div className="flex items-center gap-4 mb-4">
-            <div className="flex-1">
+            <div className="flex-1 relative">
               <input
                 type="text"
-                placeholder="도서 제목으로 검색..."
-                value={filters.search}
-                onChange={(e) => setFilters({ ...filters, search: e.target.value })}
+                placeholder="제목, 저자, 출판사, ISBN으로 검색..."
+                value={filters.q}
+                onChange={(e) => setFilters({ ...filters, q: e.target.value })}
                 onKeyPress={(e) => e.key === 'Enter' && handleSearch()}
+                onBlur={() => setTimeout(() => setSuggestions([]), 150)}
                 className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-blue-500"
               />
+              {suggestions.length > 0 && (
+                <ul className="absolute z-10 mt-1 w-full bg-white border border-gray-200 rounded-lg shadow-lg">
+                  {suggestions.map((s) => (
+                    <li
+                      key={`${s.type}-${s.text}`}
+                      onMouseDown={() => handleSelectSuggestion(s)}
+                      className="px-4 py-2 hover:bg-blue-50 cursor-pointer flex items-center gap-2"
+                    >
+                      <span className="text-xs text-gray-500 bg-gray-100 rounded px-2 py-0.5">{s.label}</span>
+                      <span className="text-gray-800">{s.text}</span>
+                    </li>
+                  ))}
+                </ul>
+              )}
             </div>
+            <select
+              value={filters.sort}
+              onChange={(e) => setFilters({ ...filters, sort: e.target.value as BookFilters['sort'] })}
+              className="px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-blue-500"
+            >
+              <option value="relevance">정확도순</option>
+              <option value="newest">최신 등록순</option>
+              <option value="title">제목순</option>
+              <option value="author">저자순</option>
+              <option value="year">출판년도순</option>
+              <option value="availability">대여 가능순</option>
+            </select>
             <button
               onClick={handleSearch}
               className="bg-blue-600 hover:bg-blue-700 text-white px-6 py-3 rounded-lg font-medium transition-colors"
@@ -102,7 +189,7 @@ export default function BooksPage() {
 
           {/* 상세 필터 */}
           {showFilters && (
-            <div className="border-t pt-4 grid grid-cols-1 md:grid-cols-3 gap-4">
+            <div className="border-t pt-4 grid grid-cols-1 md:grid-cols-4 gap-4">
               <div>
                 <label className="block text-sm font-medium text-gray-700 mb-2">
                   저자
@@ -139,7 +226,29 @@ export default function BooksPage() {
                   className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-blue-500"
                 />
               </div>
-              <div className="md:col-span-3 flex gap-2 justify-end">
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-2">
+                  주제 분류
+                </label>
+                <select
+                  value={filters.subject}
+                  onChange={(e) => setFilters({ ...filters, subject: e.target.value })}
+                  className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-blue-500"
+                >
+                  <option value="">전체</option>
+                  {subjects.map((main) => [
+                    <option key={main.code} value={main.code}>
+                      {main.code} {main.name} ({main.book_count})
+                    </option>,
+                    ...(main.children ?? []).map((sub) => (
+                      <option key={sub.code} value={sub.code}>
+                        {'\u00a0\u00a0'}{sub.code} {sub.name} ({sub.book_count})
+                      </option>
+                    )),
+                  ])}
+                </select>
+              </div>
+              <div className="md:col-span-4 flex gap-2 justify-end">
                 <button
                   onClick={handleReset}
                   className="bg-gray-500 hover:bg-gray-600 text-white px-6 py-2 rounded-lg font-medium transition-colors"
@@ -157,6 +266,36 @@ export default function BooksPage() {
           )}
         </div>
 
+        {/* 패싯: 같은 항목 안에서는 하나라도 일치, 항목끼리는 모두 일치 */}
+        {!error && Object.values(facets ?? {}).some((buckets) => buckets && buckets.length > 0) && (
+          <div className="bg-white rounded-lg shadow-md p-4 mb-6 grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-4">
+            {(Object.keys(facetTitles) as FacetName[]).map((name) => {
+              const buckets = facets?.[name];
+              if (!buckets || buckets.length === 0) return null;
+              return (
+                <div key={name}>
+                  <h4 className="text-sm font-semibold text-gray-700 mb-2">{facetTitles[name]}</h4>
+                  <ul className="space-y-1 max-h-48 overflow-y-auto">
+                    {buckets.map((bucket) => (
+                      <li key={bucket.value}>
+                        <label className="flex items-center gap-2 text-sm text-gray-700 cursor-pointer">
+                          <input
+                            type="checkbox"
+                            checked={bucket.selected}
+                            onChange={() => handleToggleFacet(name, bucket.value)}
+                          />
+                          <span className="flex-1 truncate">{bucket.label}</span>
+                          <span className="text-gray-400">{bucket.count}</span>
+                        </label>
+                      </li>
+                    ))}
+                  </ul>
+                </div>
+              );
+            })}
+          </div>
+        )}
+
         {loading ? (
           <div className="flex justify-center items-center h-64">
             <div className="text-gray-600">로딩 중...</div>
@@ -199,16 +338,22 @@ export default function BooksPage() {
                   )}
                 </div>
                 <div className="p-4">
-                  <h3 className="font-bold text-lg mb-1 line-clamp-1">
-                    {book.title}
-                  </h3>
-                  <p className="text-gray-600 text-sm mb-2">{book.author}</p>
+                  {/* 검색 하이라이트는 서버에서 HTML 이스케이프 후 <mark>만 추가됨 */}
+                  <h3
+                    className="font-bold text-lg mb-1 line-clamp-1"
+                    dangerouslySetInnerHTML={{ __html: book.highlights?.title ?? escapeHtml(book.title) }}
+                  />
+                  <p
+                    className="text-gray-600 text-sm mb-2"
+                    dangerouslySetInnerHTML={{ __html: book.highlights?.author ?? escapeHtml(book.author) }}
+                  />
                   <p className="text-gray-500 text-xs mb-2">
                     {book.publisher} ({book.year})
                   </p>
-                  <p className="text-gray-700 text-sm mb-3 line-clamp-2">
-                    {book.description}
-                  </p>
+                  <p
+                    className="text-gray-700 text-sm mb-3 line-clamp-2"
+                    dangerouslySetInnerHTML={{ __html: book.highlights?.description ?? escapeHtml(book.description) }}
+                  />
                   <div className="flex items-center justify-between">
                     <div className="text-gray-600 text-sm">
                       <span className="font-medium">ISBN:</span> {book.isbn}
@@ -226,6 +371,17 @@ export default function BooksPage() {
           </div>
         )}
 
+        {!loading && !error && nextCursor && (
+          <div className="flex justify-center mt-8">
+            <button
+              onClick={() => loadBooks(activeFilters, nextCursor)}
+              className="bg-gray-600 hover:bg-gray-700 text-white px-6 py-2 rounded-lg font-medium transition-colors"
+            >
+              더 보기 ({books.length}/{total})
+            </button>
+          </div>
+        )}
+
         {!loading && !error && books.length === 0 && (
           <div className="text-center py-12 text-gray-500">
             등록된 도서가 없습니다.
@@ -10,16 +10,20 @@ export default function BorrowHistoryPage() {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [filterStatus, setFilterStatus] = useState<string>('all');
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
 
   useEffect(() => {
     loadHistory();
   }, []);
 
-  const loadHistory = async () => {
+  const loadHistory = async (cursor?: string) => {
     try {
-      setLoading(true);
-      const data = await borrowAPI.getBorrowHistory();
-      setHistoryItems(data);
+      if (!cursor) setLoading(true);
+      const data = await borrowAPI.getBorrowHistory({ cursor });
+      setHistoryItems((prev) => (cursor ? [...prev, ...data.items] : data.items));
+      setTotal(data.total);
+      setNextCursor(data.next_cursor);
     } catch (err: any) {
       setError(err.response?.data?.error || '대여 이력을 불러오는데 실패했습니다.');
     } finally {
@@ -84,7 +88,7 @@ export default function BorrowHistoryPage() {
   });
 
   const stats = {
-    total: historyItems.length,
+    total: total,
     borrowed: historyItems.filter((item) => item.status === 'borrowed').length,
     returned: historyItems.filter((item) => item.status === 'returned').length,
     overdue: historyItems.filter(
@@ -279,6 +283,17 @@ export default function BorrowHistoryPage() {
             ))}
           </div>
         )}
+
+        {nextCursor && (
+          <div className="flex justify-center mt-6">
+            <button
+              onClick={() => loadHistory(nextCursor)}
+              className="bg-gray-600 hover:bg-gray-700 text-white px-6 py-2 rounded-lg font-medium transition-colors"
+            >
+              더 보기 ({historyItems.length}/{total})
+            </button>
+          </div>
+        )}
       </div>
     </div>
   );
@@ -1,6 +1,7 @@
 import { useState } from 'react';
 import { useNavigate } from 'react-router-dom';
-import { authAPI } from '../services/api';
+import { authAPI, userAPI } from '../services/api';
+import { savePermissions } from '../services/permissions';
 
 export default function LoginPage() {
   const navigate = useNavigate();
@@ -18,6 +19,14 @@ export default function LoginPage() {
       localStorage.setItem('token', response.token);
       localStorage.setItem('user_id', response.user_id);
       localStorage.setItem('role', response.role);
+      // 메뉴와 관리 화면은 역할 이름이 아니라 /users/me의 권한으로 가린다
+      try {
+        const me = await userAPI.getMe();
+        savePermissions(me.permissions);
+      } catch (err) {
+        console.error('Failed to load permissions:', err);
+        savePermissions([]);
+      }
       navigate('/books');
     } catch (err: any) {
       setError(err.response?.data?.error || '로그인에 실패했습니다.');
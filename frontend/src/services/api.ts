@@ -1,5 +1,5 @@
 import axios from 'axios';
-import type { Book, BorrowItem, LoginRequest, LoginResponse } from '../types';
+import type { Author, AuthorWork, BarcodeReturnResult, DuplicateList, DuplicatePair, DuplicateScan, MergeResult, InventoryReport, InventoryScanResult, InventorySession, InventorySessionInput, Book, Branch, BranchInput, BookCover, CopyLookup, CopyStatus, CopyStatusChange, CopyStatusInfo, LabelRequest, BookInput, BookPage, BorrowItem, ISBNReport, FacetName, ImportJob, ImportOptions, LoginRequest, LoginResponse, MARCImportReport, MyProfile, Page, PageQuery, ReconcileReport, ReturnedCopy, Subject, Suggestion, Transfer, TransferFilters, TransferManifest, TransferRequest, WeedingReport, WithdrawalReason, WithdrawRequest, WithdrawResult } from '../types';
 
 // API Gateway URL
 // 프로덕션: Nginx가 /api를 API Gateway로 프록시
@@ -30,6 +30,7 @@ api.interceptors.response.use(
       localStorage.removeItem('token');
       localStorage.removeItem('user_id');
       localStorage.removeItem('role');
+      localStorage.removeItem('permissions');
       window.location.href = '/login';
     }
     return Promise.reject(error);
@@ -55,26 +56,184 @@ export const authAPI = {
   },
 };
 
+// 내 계정 API
+export const userAPI = {
+  getMe: async (): Promise<MyProfile> => {
+    const response = await api.get<MyProfile>('/users/me');
+    return response.data;
+  },
+  updateMe: async (data: {
+    display_name: string;
+    email: string;
+    phone: string;
+    preferred_language: string;
+  }): Promise<MyProfile> => {
+    const response = await api.put<MyProfile>('/users/me', data);
+    return response.data;
+  },
+};
+
+// 페이지 파라미터를 쿼리 문자열에 추가
+const appendPageParams = (params: URLSearchParams, query?: PageQuery) => {
+  if (query?.cursor) params.append('cursor', query.cursor);
+  else if (query?.page) params.append('page', String(query.page));
+  if (query?.limit) params.append('limit', String(query.limit));
+};
+
 // 도서 API
 export interface BookFilters {
+  q?: string;
   search?: string;
   author?: string;
   publisher?: string;
   year?: string;
+  subject?: string;
+  // 분관 코드 (복본 수도 그 분관 기준)
+  branch?: string;
+  sort?: 'relevance' | 'newest' | 'title' | 'author' | 'year' | 'availability';
+  // 패싯별 선택 값 (같은 패싯은 OR, 패싯끼리는 AND)
+  facets?: Partial<Record<FacetName, string[]>>;
+  withFacets?: boolean;
 }
 
 export const bookAPI = {
-  getBooks: async (filters?: BookFilters): Promise<Book[]> => {
+  getBooks: async (filters?: BookFilters, pageQuery?: PageQuery): Promise<BookPage> => {
     const params = new URLSearchParams();
+    if (filters?.q) params.append('q', filters.q);
     if (filters?.search) params.append('search', filters.search);
     if (filters?.author) params.append('author', filters.author);
     if (filters?.publisher) params.append('publisher', filters.publisher);
     if (filters?.year) params.append('year', filters.year);
+    if (filters?.subject) params.append('subject', filters.subject);
+    if (filters?.branch) params.append('branch', filters.branch);
+    if (filters?.sort) params.append('sort', filters.sort);
+    if (filters?.withFacets) params.append('facets', 'all');
+    Object.entries(filters?.facets ?? {}).forEach(([name, values]) => {
+      values?.forEach((value) => params.append(`facet_${name}`, value));
+    });
+    appendPageParams(params, pageQuery);
 
     const queryString = params.toString();
     const url = queryString ? `/books?${queryString}` : '/books';
 
-    const response = await api.get<Book[]>(url);
+    const response = await api.get<BookPage>(url);
+    return response.data;
+  },
+  suggest: async (q: string): Promise<Suggestion[]> => {
+    const response = await api.get<{ q: string; suggestions: Suggestion[] }>(
+      `/books/suggest?q=${encodeURIComponent(q)}`
+    );
+    return response.data.suggestions;
+  },
+  getBranches: async (): Promise<Branch[]> => {
+    const response = await api.get<{ branches: Branch[] }>('/branches');
+    return response.data.branches;
+  },
+  adminCreateBranch: async (input: BranchInput): Promise<Branch> => {
+    const response = await api.post<Branch>('/admin/branches', input);
+    return response.data;
+  },
+  adminUpdateBranch: async (id: number, input: BranchInput): Promise<Branch> => {
+    const response = await api.put<Branch>(`/admin/branches/${id}`, input);
+    return response.data;
+  },
+  adminDeleteBranch: async (id: number): Promise<void> => {
+    await api.delete(`/admin/branches/${id}`);
+  },
+  // 관리자: 분관 간 이관
+  adminGetTransfers: async (filters?: TransferFilters, pageQuery?: PageQuery): Promise<Page<Transfer>> => {
+    const params = new URLSearchParams();
+    if (filters?.status) params.append('status', filters.status);
+    if (filters?.from_branch_id) params.append('from_branch_id', String(filters.from_branch_id));
+    if (filters?.to_branch_id) params.append('to_branch_id', String(filters.to_branch_id));
+    if (filters?.copy_id) params.append('copy_id', String(filters.copy_id));
+    appendPageParams(params, pageQuery);
+    const response = await api.get<Page<Transfer>>(`/admin/transfers?${params.toString()}`);
+    return response.data;
+  },
+  adminCreateTransfer: async (request: TransferRequest): Promise<Transfer> => {
+    const response = await api.post<Transfer>('/admin/transfers', request);
+    return response.data;
+  },
+  adminShipTransfer: async (id: number): Promise<Transfer> => {
+    const response = await api.post<{ transfer: Transfer }>(`/admin/transfers/${id}/ship`);
+    return response.data.transfer;
+  },
+  // 예약이 끝난 예약 도서는 소속 분관으로 다시 보내며, 그 이관 ID가 next_transfer_id로 온다
+  adminReceiveTransfer: async (id: number): Promise<{ transfer: Transfer; next_transfer_id?: number }> => {
+    const response = await api.post<{ transfer: Transfer; next_transfer_id?: number }>(`/admin/transfers/${id}/receive`);
+    return response.data;
+  },
+  adminCancelTransfer: async (id: number): Promise<void> => {
+    await api.post(`/admin/transfers/${id}/cancel`);
+  },
+  // status: requested(발송할 복본) | in_transit | open
+  adminGetTransferManifest: async (fromBranchId?: number, status = 'requested'): Promise<TransferManifest> => {
+    const params = new URLSearchParams({ status });
+    if (fromBranchId) params.append('from_branch_id', String(fromBranchId));
+    const response = await api.get<TransferManifest>(`/admin/transfers/manifest?${params.toString()}`);
+    return response.data;
+  },
+  adminGetTransferManifestCSV: async (fromBranchId?: number, status = 'requested'): Promise<Blob> => {
+    const params = new URLSearchParams({ status, format: 'csv' });
+    if (fromBranchId) params.append('from_branch_id', String(fromBranchId));
+    const response = await api.get(`/admin/transfers/manifest?${params.toString()}`, { responseType: 'blob' });
+    return response.data;
+  },
+  // 관리자: 장서 점검
+  adminGetInventorySessions: async (status?: InventorySession['status'], pageQuery?: PageQuery): Promise<Page<InventorySession>> => {
+    const params = new URLSearchParams();
+    if (status) params.append('status', status);
+    appendPageParams(params, pageQuery);
+    const response = await api.get<Page<InventorySession>>(`/admin/inventory?${params.toString()}`);
+    return response.data;
+  },
+  adminCreateInventorySession: async (input: InventorySessionInput): Promise<InventorySession> => {
+    const response = await api.post<InventorySession>('/admin/inventory', input);
+    return response.data;
+  },
+  adminGetInventoryReport: async (id: number): Promise<InventoryReport> => {
+    const response = await api.get<InventoryReport>(`/admin/inventory/${id}`);
+    return response.data;
+  },
+  // locationId를 주면 그 위치에서 스캔한 것으로 보고 복본의 위치와 비교한다
+  adminScanInventory: async (
+    id: number,
+    barcodes: string[],
+    locationId?: number
+  ): Promise<{ session_id: number; results: InventoryScanResult[]; summary: Record<string, number> }> => {
+    const response = await api.post(`/admin/inventory/${id}/scans`, { barcodes, location_id: locationId });
+    return response.data;
+  },
+  adminCloseInventorySession: async (id: number): Promise<InventoryReport> => {
+    const response = await api.post<InventoryReport>(`/admin/inventory/${id}/close`);
+    return response.data;
+  },
+  adminCancelInventorySession: async (id: number): Promise<void> => {
+    await api.post(`/admin/inventory/${id}/cancel`);
+  },
+  // copyIds를 생략하면 미발견 복본 전체를 분실 처리한다
+  adminMarkInventoryMissingLost: async (
+    id: number,
+    copyIds?: number[]
+  ): Promise<{ marked: number; copy_ids: number[]; skipped: number[] }> => {
+    const response = await api.post(`/admin/inventory/${id}/mark-lost`, { copy_ids: copyIds });
+    return response.data;
+  },
+  getSubjects: async (depth?: number): Promise<Subject[]> => {
+    const params = new URLSearchParams({ nonempty: 'true' });
+    if (depth) params.append('depth', String(depth));
+    const response = await api.get<{ subjects: Subject[] }>(`/subjects?${params.toString()}`);
+    return response.data.subjects;
+  },
+  getAuthor: async (id: number | string): Promise<{ author: Author; works: AuthorWork[] }> => {
+    const response = await api.get<{ author: Author; works: AuthorWork[] }>(`/authors/${id}`);
+    return response.data;
+  },
+  getBookByISBN: async (isbn: string): Promise<{ isbn13: string; isbn10: string; book: Book }> => {
+    const response = await api.get<{ isbn13: string; isbn10: string; book: Book }>(
+      `/books/isbn/${encodeURIComponent(isbn)}`
+    );
     return response.data;
   },
   getBook: async (id: string): Promise<Book> => {
@@ -86,31 +245,196 @@ export const bookAPI = {
     return response.data;
   },
   // 관리자: 복본 관리
-  adminGetAllCopies: async (bookId?: string): Promise<any[]> => {
-    const url = bookId ? `/admin/copies?book_id=${bookId}` : '/admin/copies';
-    const response = await api.get<any[]>(url);
+  // status를 주면 그 상태의 복본만 (개수만 필요하면 limit 1로 부르고 total을 쓴다)
+  adminGetAllCopies: async (bookId?: string, pageQuery?: PageQuery, status?: CopyStatus): Promise<Page<any>> => {
+    const params = new URLSearchParams();
+    if (bookId) params.append('book_id', bookId);
+    if (status) params.append('status', status);
+    appendPageParams(params, pageQuery);
+
+    const queryString = params.toString();
+    const url = queryString ? `/admin/copies?${queryString}` : '/admin/copies';
+    const response = await api.get<Page<any>>(url);
     return response.data;
   },
   adminAddCopy: async (data: {
     book_id: string;
     copy_number: number;
     status: string;
+    branch_id?: number;
+    location_id?: number;
     location: string;
     acquired_date: string;
     notes: string;
+    barcode?: string;
   }): Promise<void> => {
     await api.post('/admin/copies', data);
   },
+  // 상태를 바꿀 때는 reason(사유 코드)이 필요하다
   adminUpdateCopy: async (id: number, data: {
     status: string;
+    reason?: string;
+    reason_note?: string;
+    branch_id?: number | null;
+    location_id?: number | null;
     location: string;
     acquired_date: string;
     notes: string;
   }): Promise<void> => {
     await api.put(`/admin/copies/${id}`, data);
   },
-  adminDeleteCopy: async (id: number): Promise<void> => {
-    await api.delete(`/admin/copies/${id}`);
+  adminGetCopyStatuses: async (): Promise<CopyStatusInfo> => {
+    const response = await api.get<CopyStatusInfo>('/admin/copies/statuses');
+    return response.data;
+  },
+  adminGetCopyHistory: async (id: number): Promise<CopyStatusChange[]> => {
+    const response = await api.get<CopyStatusChange[]>(`/admin/copies/${id}/history`);
+    return response.data;
+  },
+  // 복본 제적 (행은 남고 상태가 withdrawn으로 바뀐다)
+  adminDeleteCopy: async (id: number, reason: WithdrawalReason = 'other', note?: string): Promise<void> => {
+    const params = new URLSearchParams({ reason });
+    if (note) params.append('note', note);
+    await api.delete(`/admin/copies/${id}?${params.toString()}`);
+  },
+  adminWithdrawCopies: async (data: WithdrawRequest): Promise<WithdrawResult> => {
+    const response = await api.post<WithdrawResult>('/admin/copies/withdraw', data);
+    return response.data;
+  },
+  adminGetWeedingCandidates: async (
+    filters?: { branch_id?: number; min_score?: number; limit?: number }
+  ): Promise<WeedingReport> => {
+    const params = new URLSearchParams();
+    if (filters?.branch_id) params.append('branch_id', String(filters.branch_id));
+    if (filters?.min_score) params.append('min_score', String(filters.min_score));
+    if (filters?.limit) params.append('limit', String(filters.limit));
+    const queryString = params.toString();
+    const response = await api.get<WeedingReport>(
+      queryString ? `/admin/copies/weeding?${queryString}` : '/admin/copies/weeding'
+    );
+    return response.data;
+  },
+  // 바코드 스캔으로 복본과 도서 조회
+  getCopyByBarcode: async (code: string): Promise<CopyLookup> => {
+    const response = await api.get<CopyLookup>(`/copies/barcode/${encodeURIComponent(code)}`);
+    return response.data;
+  },
+  // 라벨 출력 (PDF 또는 Zebra ZPL)
+  adminPrintLabels: async (request: LabelRequest): Promise<Blob> => {
+    const response = await api.post('/admin/copies/labels', request, { responseType: 'blob' });
+    return response.data;
+  },
+  // 관리자: 도서 서지 관리
+  adminCreateBook: async (data: BookInput): Promise<Book> => {
+    const response = await api.post<Book>('/admin/books', data);
+    return response.data;
+  },
+  adminUpdateBook: async (id: string, data: Partial<BookInput>): Promise<Book> => {
+    const response = await api.patch<Book>(`/admin/books/${id}`, data);
+    return response.data;
+  },
+  adminGetISBNReport: async (): Promise<ISBNReport> => {
+    const response = await api.get<ISBNReport>('/admin/books/isbn-report');
+    return response.data;
+  },
+  // 중복 도서 찾기와 병합
+  adminGetDuplicates: async (
+    filters?: { status?: DuplicatePair['status']; min_score?: number; limit?: number }
+  ): Promise<DuplicateList> => {
+    const params = new URLSearchParams();
+    if (filters?.status) params.append('status', filters.status);
+    if (filters?.min_score) params.append('min_score', String(filters.min_score));
+    if (filters?.limit) params.append('limit', String(filters.limit));
+    const queryString = params.toString();
+    const response = await api.get<DuplicateList>(
+      queryString ? `/admin/books/duplicates?${queryString}` : '/admin/books/duplicates'
+    );
+    return response.data;
+  },
+  adminStartDuplicateScan: async (): Promise<DuplicateScan> => {
+    const response = await api.post<DuplicateScan>('/admin/books/duplicates/scan');
+    return response.data;
+  },
+  adminGetDuplicateScan: async (scanId: string): Promise<DuplicateScan> => {
+    const response = await api.get<DuplicateScan>(`/admin/books/duplicates/scans/${scanId}`);
+    return response.data;
+  },
+  adminDismissDuplicate: async (id: number): Promise<void> => {
+    await api.post(`/admin/books/duplicates/${id}/dismiss`);
+  },
+  // survivorId 도서를 남기고 duplicateIds 도서를 합친다
+  adminMergeBooks: async (survivorId: string, duplicateIds: string[]): Promise<MergeResult> => {
+    const response = await api.post<MergeResult>(`/admin/books/${survivorId}/merge`, { duplicate_ids: duplicateIds });
+    return response.data;
+  },
+  // MARC21(.mrc) 또는 MARCXML 파일 가져오기 (dryRun이면 보고서만 반환)
+  adminImportMARC: async (file: File, dryRun = true): Promise<MARCImportReport> => {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await api.post<MARCImportReport>(`/admin/marc/import?dry_run=${dryRun}`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  },
+  adminExportMARC: async (params: { ids?: string[]; q?: string; subject?: string }): Promise<Blob> => {
+    const query = new URLSearchParams();
+    if (params.ids?.length) query.append('ids', params.ids.join(','));
+    if (params.q) query.append('q', params.q);
+    if (params.subject) query.append('subject', params.subject);
+    const response = await api.get(`/admin/marc/export?${query.toString()}`, { responseType: 'blob' });
+    return response.data;
+  },
+  // CSV/NDJSON 일괄 가져오기 작업 시작 (진행 상황은 adminGetImportJob으로 조회)
+  adminStartImport: async (file: File, options: ImportOptions = {}): Promise<ImportJob> => {
+    const form = new FormData();
+    form.append('file', file);
+    if (options.format) form.append('format', options.format);
+    if (options.mapping) form.append('mapping', JSON.stringify(options.mapping));
+    if (options.onDuplicate) form.append('on_duplicate', options.onDuplicate);
+    if (options.copies) form.append('copies', String(options.copies));
+    if (options.location) form.append('location', options.location);
+    if (options.dryRun) form.append('dry_run', 'true');
+    const response = await api.post<ImportJob>('/admin/books/import', form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  },
+  adminGetImportJobs: async (): Promise<ImportJob[]> => {
+    const response = await api.get<ImportJob[]>('/admin/books/import');
+    return response.data;
+  },
+  adminGetImportJob: async (id: string): Promise<ImportJob> => {
+    const response = await api.get<ImportJob>(`/admin/books/import/${id}`);
+    return response.data;
+  },
+  adminGetImportErrors: async (id: string): Promise<Blob> => {
+    const response = await api.get(`/admin/books/import/${id}/errors`, { responseType: 'blob' });
+    return response.data;
+  },
+  adminExportBooks: async (format: 'csv' | 'ndjson' = 'csv'): Promise<Blob> => {
+    const response = await api.get(`/admin/books/export?format=${format}`, { responseType: 'blob' });
+    return response.data;
+  },
+  // 표지 업로드 (JPEG/PNG/GIF, 5MB 이하)
+  adminUploadCover: async (bookId: string, file: File): Promise<BookCover> => {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await api.post<BookCover>(`/admin/books/${bookId}/cover`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  },
+  // 원격 표지 URL을 내려받아 저장 (url을 생략하면 현재 cover_image)
+  adminImportCover: async (bookId: string, url?: string): Promise<BookCover> => {
+    const response = await api.post<BookCover>(`/admin/books/${bookId}/cover/import`, url ? { url } : {});
+    return response.data;
+  },
+  adminDeleteCover: async (bookId: string): Promise<void> => {
+    await api.delete(`/admin/books/${bookId}/cover`);
+  },
+  getBookMARCUrl: (id: string): string => `${API_BASE_URL}/books/${id}/marcxml`,
+  adminDeleteBook: async (id: string): Promise<void> => {
+    await api.delete(`/admin/books/${id}`);
   },
 };
 
@@ -120,40 +444,70 @@ export const borrowAPI = {
     const response = await api.get<BorrowItem[]>('/borrows');
     return response.data;
   },
-  getBorrowHistory: async (): Promise<BorrowItem[]> => {
-    const response = await api.get<BorrowItem[]>('/borrows/history');
+  getBorrowHistory: async (pageQuery?: PageQuery): Promise<Page<BorrowItem>> => {
+    const params = new URLSearchParams();
+    appendPageParams(params, pageQuery);
+    const queryString = params.toString();
+    const url = queryString ? `/borrows/history?${queryString}` : '/borrows/history';
+    const response = await api.get<Page<BorrowItem>>(url);
     return response.data;
   },
-  borrowBook: async (book: Book): Promise<void> => {
+  // branchId를 주면 그 분관의 복본을 대출한다
+  borrowBook: async (book: Book, branchId?: number): Promise<void> => {
     await api.post('/borrows/borrow', {
       book_id: book.id,
       title: book.title,
       author: book.author,
+      branch_id: branchId,
     });
   },
-  returnBook: async (bookId: string): Promise<void> => {
-    await api.post(`/borrows/return/${bookId}`);
+  returnBook: async (bookId: string, branchId?: number): Promise<ReturnedCopy> => {
+    const query = branchId ? `?branch_id=${branchId}` : '';
+    const response = await api.post<{ message: string; copy: ReturnedCopy }>(`/borrows/return/${bookId}${query}`);
+    return response.data.copy;
+  },
+  renewBook: async (bookId: string): Promise<{ due_date: string; renewal_count: number; max_renewals: number }> => {
+    const response = await api.post(`/borrows/renew/${bookId}`);
+    return response.data;
   },
   // 관리자 전용 API
   adminGetAllBorrows: async (): Promise<BorrowItem[]> => {
     const response = await api.get<BorrowItem[]>('/borrows/admin/all');
     return response.data;
   },
-  adminGetAllHistory: async (): Promise<BorrowItem[]> => {
-    const response = await api.get<BorrowItem[]>('/borrows/admin/history');
+  adminGetAllHistory: async (pageQuery?: PageQuery, status?: 'borrowed' | 'overdue' | 'returned'): Promise<Page<BorrowItem>> => {
+    const params = new URLSearchParams();
+    if (status) params.append('status', status);
+    appendPageParams(params, pageQuery);
+    const queryString = params.toString();
+    const url = queryString ? `/borrows/admin/history?${queryString}` : '/borrows/admin/history';
+    const response = await api.get<Page<BorrowItem>>(url);
     return response.data;
   },
-  adminBorrowBook: async (userId: string, book: Book): Promise<void> => {
+  // barcode를 주면 스캔한 그 복본을 대출한다
+  adminBorrowBook: async (userId: string, book: Book, barcode?: string): Promise<void> => {
     await api.post('/borrows/admin/borrow', {
       user_id: userId,
       book_id: book.id,
       title: book.title,
       author: book.author,
+      barcode,
     });
   },
   adminReturnBook: async (borrowId: number): Promise<void> => {
     await api.post(`/borrows/admin/return/${borrowId}`);
   },
+  adminReturnByBarcode: async (barcode: string, branchId?: number): Promise<BarcodeReturnResult> => {
+    const response = await api.post<BarcodeReturnResult>('/borrows/admin/return-barcode', { barcode, branch_id: branchId });
+    return response.data;
+  },
+  // apply가 false면 바꿀 내용만 확인한다
+  adminReconcileBorrows: async (apply = false): Promise<ReconcileReport> => {
+    const response = apply
+      ? await api.post<ReconcileReport>('/borrows/admin/reconcile')
+      : await api.get<ReconcileReport>('/borrows/admin/reconcile');
+    return response.data;
+  },
 };
 
 // 알림 API
@@ -179,8 +533,9 @@ export const notificationAPI = {
 
 // 예약 API
 export const reservationAPI = {
-  createReservation: async (userId: string, bookId: string): Promise<void> => {
-    await api.post('/reservations', { user_id: userId, book_id: bookId });
+  // pickupBranchId를 생략하면 어느 분관에서든 받을 수 있다
+  createReservation: async (userId: string, bookId: string, pickupBranchId?: number): Promise<void> => {
+    await api.post('/reservations', { user_id: userId, book_id: bookId, pickup_branch_id: pickupBranchId });
   },
   getReservations: async (userId: string): Promise<any[]> => {
     const response = await api.get(`/reservations?user_id=${userId}`);
@@ -0,0 +1,37 @@
+// 로그인한 이용자의 권한
+// 로그인할 때 /users/me의 permissions를 저장해 두고 화면/메뉴 표시 여부를 정한다.
+// 실제 권한 검사는 각 서비스의 requirePermission이 하므로 여기서는 화면 표시만 가린다.
+
+const PERMISSIONS_KEY = 'permissions';
+
+// 관리 화면(대시보드, 대여 관리, 복본 관리)에 필요한 권한
+export const PAGE_PERMISSIONS = {
+  dashboard: 'circulation:view-all',
+  borrowManage: 'circulation:view-all',
+  copyManage: 'copies:read',
+} as const;
+
+// 직원(사서/관리자)으로 보는 권한: 하나라도 있으면 이용자 메뉴 대신 관리 메뉴를 보여준다
+const STAFF_PERMISSIONS = ['circulation:view-all', 'copies:read', 'books:write'];
+
+export function savePermissions(permissions: string[]) {
+  localStorage.setItem(PERMISSIONS_KEY, JSON.stringify(permissions));
+}
+
+export function getPermissions(): string[] {
+  try {
+    const stored = JSON.parse(localStorage.getItem(PERMISSIONS_KEY) || '[]');
+    return Array.isArray(stored) ? stored : [];
+  } catch {
+    return [];
+  }
+}
+
+export function hasPermission(permission: string): boolean {
+  return getPermissions().includes(permission);
+}
+
+export function isStaff(): boolean {
+  const permissions = getPermissions();
+  return STAFF_PERMISSIONS.some((p) => permissions.includes(p));
+}
@@ -8,24 +8,332 @@ export interface Book {
   description: string;
   price: number;
   cover_image: string;
+  created_at: string;
   total_copies: number;
   available_copies: number;
+  highlights?: Record<string, string>;
+  subjects?: SubjectRef[];
+  contributors?: Contributor[];
+  holdings?: BranchHolding[];
+}
+
+// 분관 > 층 > 서가
+export interface Branch {
+  id: number;
+  parent_id: number | null;
+  code: string;
+  name: string;
+  level: 'branch' | 'floor' | 'shelf';
+  sort_order: number;
+  active: boolean;
+  children?: Branch[];
+}
+
+export interface BranchInput {
+  parent_id?: number | null;
+  code: string;
+  name: string;
+  sort_order?: number;
+  active?: boolean;
+}
+
+// 도서 상세의 분관별 소장 현황
+export interface BranchHolding {
+  branch_id: number;
+  code: string;
+  name: string;
+  total_copies: number;
+  available_copies: number;
+}
+
+export interface ISBNIssue {
+  book_id: string;
+  title: string;
+  isbn: string;
+  problem: 'invalid' | 'not_normalized' | 'duplicate';
+  message: string;
+  normalized?: string;
+  duplicate_of?: string[];
+}
+
+export interface ISBNReport {
+  checked: number;
+  summary: Record<ISBNIssue['problem'], number>;
+  issues: ISBNIssue[];
+}
+
+// 중복 찾기 작업
+export interface DuplicateScan {
+  id: string;
+  status: 'queued' | 'running' | 'completed' | 'failed';
+  checked_books: number;
+  pairs_found: number;
+  error?: string;
+  created_by: string;
+  created_at: string;
+  started_at: string | null;
+  finished_at: string | null;
+}
+
+export interface DuplicateBookRef {
+  id: string;
+  title: string;
+  author: string;
+  publisher: string;
+  year: number;
+  isbn: string;
+  copies: number;
+}
+
+// 중복 의심 쌍 (book은 먼저 등록된 도서, score는 최대 100)
+export interface DuplicatePair {
+  id: number;
+  book: DuplicateBookRef;
+  duplicate: DuplicateBookRef;
+  isbn_match: boolean;
+  author_match: boolean;
+  title_similarity: number;
+  score: number;
+  status: 'open' | 'dismissed';
+  found_at: string;
+}
+
+export interface DuplicateList {
+  pairs: DuplicatePair[];
+  total: number;
+  last_scan: DuplicateScan | null;
+}
+
+export interface MergeResult {
+  message: string;
+  book: Book;
+  merged: string[];
+  moved: { copies: number; borrows: number; reservations: number; cancelled_reservations: number };
+}
+
+export type MARCImportAction = 'create' | 'update' | 'unchanged' | 'conflict' | 'error';
+
+export interface MARCImportRecord {
+  index: number;
+  action: MARCImportAction;
+  book_id?: string;
+  isbn?: string;
+  title?: string;
+  changes?: string[];
+  subjects?: string[];
+  message?: string;
+}
+
+export interface MARCImportReport {
+  dry_run: boolean;
+  total: number;
+  summary: Record<MARCImportAction, number>;
+  records: MARCImportRecord[];
+}
+
+export interface BookCover {
+  book_id: string;
+  version: string;
+  content_type: string;
+  width: number;
+  height: number;
+  byte_size: number;
+  source_url?: string;
+  urls: Record<'sm' | 'md' | 'lg' | 'original', string>;
+}
+
+export interface ImportJob {
+  id: string;
+  format: 'csv' | 'ndjson';
+  status: 'queued' | 'running' | 'completed' | 'failed';
+  dry_run: boolean;
+  on_duplicate: 'update' | 'skip';
+  copies_per_row: number;
+  total_rows: number;
+  processed_rows: number;
+  created: number;
+  updated: number;
+  skipped: number;
+  failed: number;
+  copies_created: number;
+  progress: number;
+  error?: string;
+  created_by: string;
+  created_at: string;
+  started_at: string | null;
+  finished_at: string | null;
+}
+
+export interface ImportOptions {
+  format?: 'csv' | 'ndjson';
+  mapping?: Record<string, string>;
+  onDuplicate?: 'update' | 'skip';
+  copies?: number;
+  location?: string;
+  dryRun?: boolean;
+}
+
+export type ContributorRole = 'author' | 'translator' | 'editor' | 'illustrator';
+
+export interface Contributor {
+  author_id: number;
+  name: string;
+  role: ContributorRole;
+  role_label: string;
+  position: number;
+}
+
+export interface Author {
+  id: number;
+  name: string;
+  variant_names?: string;
+  birth_year: number | null;
+  death_year: number | null;
+  note?: string;
+  work_count: number;
+}
+
+export interface AuthorWork extends Book {
+  roles: ContributorRole[];
+}
+
+export interface SubjectRef {
+  code: string;
+  name: string;
+  ddc_code?: string;
+}
+
+export interface Subject extends SubjectRef {
+  parent_code: string | null;
+  book_count: number;
+  children?: Subject[];
+}
+
+export interface Suggestion {
+  type: 'title' | 'author' | 'publisher';
+  label: string;
+  text: string;
+  book_id?: string;
+  count: number;
+}
+
+export interface BookInput {
+  title: string;
+  author: string;
+  publisher: string;
+  year: number;
+  isbn: string;
+  description: string;
+  price: number;
+  cover_image: string;
 }
 
 export interface BookCopy {
   id: number;
   book_id: string;
   copy_number: number;
-  status: 'available' | 'borrowed' | 'maintenance' | 'lost';
+  status: CopyStatus;
+  branch_id: number | null;
+  branch_name: string;
+  location_id: number | null;
+  location_path: string;
   location: string;
   acquired_date: string;
   notes: string;
+  barcode: string;
+}
+
+export type CopyStatus =
+  | 'available'
+  | 'borrowed'
+  | 'on_hold'
+  | 'in_transit'
+  | 'maintenance'
+  | 'lost'
+  | 'withdrawn';
+
+// 복본 상태 변경 이력
+export interface CopyStatusChange {
+  id: number;
+  copy_id: number;
+  from_status: CopyStatus | null;
+  to_status: CopyStatus;
+  reason: string;
+  reason_label: string;
+  note?: string;
+  actor: string;
+  changed_at: string;
+}
+
+// 상태 이름, 허용 전이, 사유 코드 (reasons[code].to가 없으면 모든 전이에 사용 가능)
+export interface CopyStatusInfo {
+  statuses: Record<CopyStatus, string>;
+  transitions: Record<CopyStatus, CopyStatus[]>;
+  reasons: Record<string, { label: string; to?: CopyStatus[] }>;
+  withdrawal_reasons: Record<WithdrawalReason, string>;
+}
+
+export type WithdrawalReason = 'worn' | 'damaged' | 'lost' | 'outdated' | 'duplicate' | 'low_use' | 'other';
+
+export interface WithdrawRequest {
+  copy_ids: number[];
+  reason: WithdrawalReason;
+  note?: string;
+  withdrawn_date?: string;
+}
+
+export interface WithdrawResult {
+  withdrawn: number[];
+  skipped: Record<string, string>;
+}
+
+// 제적 후보 (score = factors 합계, 최대 100)
+export interface WeedingCandidate {
+  copy_id: number;
+  barcode: string;
+  book_id: string;
+  book_title: string;
+  book_author: string;
+  year: number;
+  copy_number: number;
+  status: CopyStatus;
+  branch_id: number | null;
+  branch_name?: string;
+  location_path?: string;
+  acquired_date: string | null;
+  last_borrowed_at: string | null;
+  idle_days: number;
+  circulations: number;
+  title_circulations: number;
+  title_copies: number;
+  score: number;
+  factors: { idle: number; circulation: number; age: number; duplicates: number };
+}
+
+export interface WeedingReport {
+  candidates: WeedingCandidate[];
+  total: number;
+  reasons: Record<WithdrawalReason, string>;
+}
+
+export interface CopyLookup {
+  copy: BookCopy;
+  book: Book;
+}
+
+export interface LabelRequest {
+  copy_ids?: number[];
+  book_ids?: string[];
+  type?: 'barcode' | 'spine' | 'both';
+  format?: 'pdf' | 'zpl';
+  skip?: number;
 }
 
 export interface BorrowItem {
   id: number;
   user_id: string;
   book_id: string;
+  copy_id: number | null;
   title: string;
   author: string;
   borrowed_at: string;
@@ -34,6 +342,159 @@ export interface BorrowItem {
   status: string;
 }
 
+// 반납한 복본을 보낼 곳. 다른 분관으로 보내야 하면 이관이 만들어진다.
+export interface ReturnedCopy {
+  copy_id?: number;
+  in_transit: boolean;
+  home_branch_id?: number;
+  transfer_id?: number;
+  to_branch_id?: number;
+  reservation_id?: number;
+}
+
+export interface BarcodeReturnResult {
+  message: string;
+  borrow_id: number;
+  user_id: string;
+  book_id: string;
+  copy_id: number;
+  copy: ReturnedCopy;
+}
+
+export type TransferKind = 'return' | 'hold' | 'relocate';
+export type TransferStatus = 'requested' | 'in_transit' | 'received' | 'cancelled';
+
+// 분관 간 복본 이관
+export interface Transfer {
+  id: number;
+  copy_id: number;
+  barcode: string;
+  book_id: string;
+  book_title: string;
+  book_author: string;
+  copy_number: number;
+  from_branch_id: number | null;
+  from_branch_name: string;
+  to_branch_id: number;
+  to_branch_name: string;
+  kind: TransferKind;
+  reservation_id: number | null;
+  status: TransferStatus;
+  note?: string;
+  requested_by: string;
+  requested_at: string;
+  shipped_at: string | null;
+  received_by?: string;
+  received_at: string | null;
+}
+
+// copy_id 또는 barcode 중 하나. reservation_id가 있으면 예약의 받을 분관으로,
+// to_branch_id를 생략하면 소속 분관으로 보낸다.
+export interface TransferRequest {
+  copy_id?: number;
+  barcode?: string;
+  to_branch_id?: number;
+  reservation_id?: number;
+  note?: string;
+  ship?: boolean;
+}
+
+export interface TransferFilters {
+  status?: TransferStatus;
+  from_branch_id?: number;
+  to_branch_id?: number;
+  copy_id?: number;
+}
+
+// 장서 점검
+export interface InventorySession {
+  id: number;
+  branch_id: number;
+  branch_name: string;
+  from_location_id: number | null;
+  from_location_path?: string;
+  to_location_id: number | null;
+  to_location_path?: string;
+  status: 'open' | 'closed' | 'cancelled';
+  note?: string;
+  opened_by: string;
+  opened_at: string;
+  closed_by?: string;
+  closed_at: string | null;
+  scan_count: number;
+}
+
+// from/to를 생략하면 분관 전체, 하나만 주면 그 위치(와 하위 위치)만 점검한다
+export interface InventorySessionInput {
+  branch_id: number;
+  from_location_id?: number;
+  to_location_id?: number;
+  note?: string;
+}
+
+export type InventoryScanOutcome = 'ok' | 'wrong_location' | 'borrowed' | 'lost' | 'other_status' | 'unknown' | 'invalid';
+
+export interface InventoryScanResult {
+  barcode: string;
+  copy_id?: number;
+  result: InventoryScanOutcome;
+  copy_status?: CopyStatus;
+  duplicate?: boolean;
+}
+
+export interface InventoryItem {
+  copy_id: number;
+  barcode: string;
+  book_id: string;
+  book_title: string;
+  copy_number: number;
+  status: CopyStatus;
+  branch_id: number | null;
+  branch_name?: string;
+  location_id: number | null;
+  location_path?: string;
+  scanned_location_id?: number;
+  scanned_location_path?: string;
+}
+
+// final이 false면 마감 전 미리 보기
+export interface InventoryReport {
+  session: InventorySession;
+  final: boolean;
+  expected: number;
+  scanned: number;
+  found: number;
+  missing: InventoryItem[];
+  wrong_location: InventoryItem[];
+  borrowed: InventoryItem[];
+  lost: InventoryItem[];
+  other_status: InventoryItem[];
+  unknown: string[];
+}
+
+export interface TransferManifest {
+  from_branch_id: number | null;
+  status: TransferStatus | 'open';
+  generated_at: string;
+  total: number;
+  destinations: { branch_id: number; name: string; items: Transfer[] }[];
+}
+
+export interface ReconcileIssue {
+  type: 'duplicate_copy' | 'wrong_copy' | 'missing_copy' | 'copy_not_borrowed' | 'orphan_copy' | 'unresolved';
+  borrow_id?: number;
+  copy_id?: number;
+  book_id: string;
+  action: string;
+  detail?: string;
+}
+
+export interface ReconcileReport {
+  dry_run: boolean;
+  issues: ReconcileIssue[];
+  summary: Record<string, number>;
+}
+
 export interface LoginRequest {
   id: string;
   password: string;
@@ -44,3 +505,64 @@ export interface LoginResponse {
   user_id: string;
   role: string;
 }
+
+export interface UserBlock {
+  id: number;
+  type: string;
+  reason: string;
+  created_by: string;
+  created_at: string;
+}
+
+export interface MembershipLimits {
+  max_loans: number;
+  max_reservations: number;
+  loan_days: number;
+  max_renewals: number;
+}
+
+export interface MyProfile {
+  username: string;
+  display_name: string;
+  email: string;
+  phone: string;
+  preferred_language: string;
+  membership_type: string;
+  card_number: string | null;
+  role: string;
+  status: string;
+  permissions: string[];
+  current_loans: number;
+  overdue_loans: number;
+  active_reservations: number;
+  limits: MembershipLimits;
+  outstanding_fines: number;
+  blocks: UserBlock[];
+}
+
+export interface Page<T> {
+  items: T[];
+  total: number;
+  page?: number;
+  limit: number;
+  next_cursor: string | null;
+}
+
+export interface FacetBucket {
+  value: string;
+  label: string;
+  count: number;
+  selected: boolean;
+}
+
+export type FacetName = 'publisher' | 'author' | 'year' | 'subject' | 'branch' | 'availability';
+
+export interface BookPage extends Page<Book> {
+  facets?: Partial<Record<FacetName, FacetBucket[]>>;
+}
+
+export interface PageQuery {
+  page?: number;
+  limit?: number;
+  cursor?: string;
+}
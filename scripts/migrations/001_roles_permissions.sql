@@ -0,0 +1,60 @@
+-- 역할/권한 모델
+-- 역할(member, librarian, cataloger, admin)에 이름 있는 권한을 매핑한다.
+-- users.role 컬럼은 그대로 역할 이름을 저장한다.
+
+CREATE TABLE IF NOT EXISTS roles (
+  name VARCHAR(50) PRIMARY KEY,
+  description VARCHAR(255),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS permissions (
+  name VARCHAR(100) PRIMARY KEY,
+  description VARCHAR(255)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role VARCHAR(50) NOT NULL,
+  permission VARCHAR(100) NOT NULL,
+  PRIMARY KEY (role, permission),
+  FOREIGN KEY (role) REFERENCES roles(name) ON DELETE CASCADE,
+  FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO roles (name, description) VALUES
+  ('member', '일반 이용자'),
+  ('librarian', '사서 (대출/반납 업무)'),
+  ('cataloger', '정리 사서 (목록/복본 관리)'),
+  ('admin', '시스템 관리자')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT INTO permissions (name, description) VALUES
+  ('books:write', '도서 서지 등록/수정/삭제'),
+  ('copies:read', '복본 목록 조회'),
+  ('copies:write', '복본 등록/수정/삭제'),
+  ('circulation:checkout-for-others', '다른 이용자 대신 대출 처리'),
+  ('circulation:return-for-others', '다른 이용자 대신 반납 처리'),
+  ('circulation:view-all', '전체 대출 현황/이력 조회'),
+  ('users:read', '이용자 조회'),
+  ('users:manage', '이용자 등록/수정/정지 및 역할 변경')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+  ('librarian', 'copies:read'),
+  ('librarian', 'copies:write'),
+  ('librarian', 'circulation:checkout-for-others'),
+  ('librarian', 'circulation:return-for-others'),
+  ('librarian', 'circulation:view-all'),
+  ('librarian', 'users:read'),
+  ('cataloger', 'books:write'),
+  ('cataloger', 'copies:read'),
+  ('cataloger', 'copies:write');
+
+-- admin은 모든 권한을 가진다
+INSERT IGNORE INTO role_permissions (role, permission)
+  SELECT 'admin', name FROM permissions;
+
+-- users.role은 역할 이름을 저장 (기존 'user' 역할은 'member'로 변경)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'member';
+ALTER TABLE users MODIFY COLUMN role VARCHAR(50) NOT NULL DEFAULT 'member';
+UPDATE users SET role = 'member' WHERE role = 'user';
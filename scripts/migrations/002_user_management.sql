@@ -0,0 +1,23 @@
+-- 이용자 관리
+-- 프로필 필드, 계정 상태(정지/활성), 이용 제한(block) 기록을 추가한다.
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS display_name VARCHAR(100) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS email VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS phone VARCHAR(30) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS status ENUM('active', 'suspended') NOT NULL DEFAULT 'active',
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NULL ON UPDATE CURRENT_TIMESTAMP,
+  ADD INDEX IF NOT EXISTS idx_users_status (status);
+
+-- 이용 제한 기록 (user_id는 borrows와 동일하게 username을 저장)
+CREATE TABLE IF NOT EXISTS user_blocks (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  user_id VARCHAR(100) NOT NULL,
+  type VARCHAR(30) NOT NULL,
+  reason VARCHAR(255) NOT NULL DEFAULT '',
+  created_by VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  cleared_at TIMESTAMP NULL,
+  cleared_by VARCHAR(100) NULL,
+  INDEX idx_user_blocks_user (user_id, cleared_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,19 @@
+-- 이용자 프로필
+-- 선호 언어, 회원 유형, 연체료(fines) 기록을 추가한다.
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(10) NOT NULL DEFAULT 'ko',
+  ADD COLUMN IF NOT EXISTS membership_type VARCHAR(30) NOT NULL DEFAULT 'general';
+
+-- 연체료 및 기타 부과금 (user_id는 username)
+CREATE TABLE IF NOT EXISTS fines (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  user_id VARCHAR(100) NOT NULL,
+  borrow_id INT NULL,
+  amount DECIMAL(10, 2) NOT NULL,
+  reason VARCHAR(255) NOT NULL DEFAULT '',
+  status ENUM('unpaid', 'paid', 'waived') NOT NULL DEFAULT 'unpaid',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  settled_at TIMESTAMP NULL,
+  INDEX idx_fines_user_status (user_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
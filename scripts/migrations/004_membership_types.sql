@@ -0,0 +1,21 @@
+-- 회원 유형별 대출 한도
+-- users.membership_type은 membership_types.name을 참조한다.
+
+CREATE TABLE IF NOT EXISTS membership_types (
+  name VARCHAR(30) PRIMARY KEY,
+  description VARCHAR(255),
+  max_loans INT NOT NULL,
+  max_reservations INT NOT NULL,
+  loan_days INT NOT NULL,
+  max_renewals INT NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO membership_types (name, description, max_loans, max_reservations, loan_days, max_renewals) VALUES
+  ('general', '일반 회원', 5, 3, 14, 1),
+  ('student', '학생 회원', 5, 3, 14, 1),
+  ('staff', '교직원 회원', 10, 5, 28, 2),
+  ('guest', '임시 회원', 2, 1, 7, 0)
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+-- 대출 연장 횟수
+ALTER TABLE borrows ADD COLUMN IF NOT EXISTS renewal_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,31 @@
+-- 도서관 이용증(카드)
+-- 카드 번호는 13자리 숫자(마지막 자리는 Luhn 체크 숫자)이며, 이용자당 active 카드는 하나만 유지한다.
+
+CREATE TABLE IF NOT EXISTS library_cards (
+  card_number VARCHAR(20) PRIMARY KEY,
+  user_id VARCHAR(100) NOT NULL,
+  status ENUM('active', 'lost', 'expired', 'replaced') NOT NULL DEFAULT 'active',
+  issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  expires_at DATE NOT NULL,
+  issued_by VARCHAR(100) NOT NULL,
+  replaced_by VARCHAR(20) NULL,
+  INDEX idx_library_cards_user (user_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 기존 이용자 중 카드가 없는 이용자에게 카드를 발급한다.
+-- 일련번호는 이용자 ID의 MD5 앞 8자리(16진수)를 10자리 숫자로 바꿔 쓰고 Luhn 체크 숫자를 붙인다
+-- (짝수 번째 자리를 두 배로 하며 9를 넘으면 9를 뺀다, user-service의 luhnCheckDigit와 같은 계산).
+-- 이미 발급된 카드 번호와 겹치는 드문 경우는 건너뛰므로 관리자 화면에서 재발급한다.
+INSERT IGNORE INTO library_cards (card_number, user_id, status, expires_at, issued_by)
+SELECT CONCAT(c.body, (10 - SUM(
+         IF(MOD(d.n, 2) = 0,
+            IF(SUBSTRING(c.body, d.n, 1) * 2 > 9, SUBSTRING(c.body, d.n, 1) * 2 - 9, SUBSTRING(c.body, d.n, 1) * 2),
+            SUBSTRING(c.body, d.n, 1))) % 10) % 10),
+       c.username, 'active', DATE_ADD(CURDATE(), INTERVAL 5 YEAR), 'migration'
+FROM (SELECT u.username, CONCAT('29', LPAD(CONV(LEFT(MD5(u.id), 8), 16, 10), 10, '0')) AS body
+      FROM users u
+      WHERE NOT EXISTS (SELECT 1 FROM library_cards lc WHERE lc.user_id = u.username)) c
+CROSS JOIN (SELECT 1 AS n UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 4
+            UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7 UNION ALL SELECT 8
+            UNION ALL SELECT 9 UNION ALL SELECT 10 UNION ALL SELECT 11 UNION ALL SELECT 12) d
+GROUP BY c.username, c.body;
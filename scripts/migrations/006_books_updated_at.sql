@@ -0,0 +1,7 @@
+-- 도서 서지 변경 시각
+-- book-service의 검색 색인이 다른 인스턴스에서 변경된 서지를 감지하는 데 사용한다.
+
+ALTER TABLE books
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+
+UPDATE books SET updated_at = created_at;
@@ -0,0 +1,137 @@
+-- 주제 분류 (한국십진분류법 KDC 6판, DDC 대응 번호 포함)
+-- 분류는 parent_code로 계층을 이루고, 도서는 여러 분류에 배정될 수 있다.
+
+CREATE TABLE IF NOT EXISTS subjects (
+  code VARCHAR(20) PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  parent_code VARCHAR(20) NULL,
+  ddc_code VARCHAR(20) NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_subjects_parent (parent_code),
+  INDEX idx_subjects_ddc (ddc_code),
+  CONSTRAINT fk_subjects_parent FOREIGN KEY (parent_code) REFERENCES subjects(code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS book_subjects (
+  book_id VARCHAR(50) NOT NULL,
+  subject_code VARCHAR(20) NOT NULL,
+  assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (book_id, subject_code),
+  INDEX idx_book_subjects_subject (subject_code),
+  CONSTRAINT fk_book_subjects_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_subjects_subject FOREIGN KEY (subject_code) REFERENCES subjects(code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 주류
+INSERT IGNORE INTO subjects (code, name, parent_code, ddc_code) VALUES
+  ('000', '총류', NULL, '000'),
+  ('100', '철학', NULL, '100'),
+  ('200', '종교', NULL, '200'),
+  ('300', '사회과학', NULL, '300'),
+  ('400', '자연과학', NULL, '500'),
+  ('500', '기술과학', NULL, '600'),
+  ('600', '예술', NULL, '700'),
+  ('700', '언어', NULL, '400'),
+  ('800', '문학', NULL, '800'),
+  ('900', '역사', NULL, '900');
+
+-- 강목
+INSERT IGNORE INTO subjects (code, name, parent_code, ddc_code) VALUES
+  ('010', '도서학, 서지학', '000', '010'),
+  ('020', '문헌정보학', '000', '020'),
+  ('030', '백과사전', '000', '030'),
+  ('040', '강연집, 수필집, 연설문집', '000', '080'),
+  ('050', '일반 연속간행물', '000', '050'),
+  ('060', '일반 학회, 단체, 협회, 기관', '000', '060'),
+  ('070', '신문, 저널리즘', '000', '070'),
+  ('080', '일반 전집, 총서', '000', '080'),
+  ('090', '향토자료', '000', NULL),
+  ('110', '형이상학', '100', '110'),
+  ('120', '인식론, 인과론, 인간학', '100', '120'),
+  ('130', '철학의 체계', '100', '140'),
+  ('140', '경학', '100', '181'),
+  ('150', '동양철학, 동양사상', '100', '181'),
+  ('160', '서양철학', '100', '190'),
+  ('170', '논리학', '100', '160'),
+  ('180', '심리학', '100', '150'),
+  ('190', '윤리학, 도덕철학', '100', '170'),
+  ('210', '비교종교', '200', '200'),
+  ('220', '불교', '200', '294.3'),
+  ('230', '기독교', '200', '230'),
+  ('240', '도교', '200', '299.514'),
+  ('250', '천도교', '200', '299.57'),
+  ('270', '힌두교, 브라만교', '200', '294.5'),
+  ('280', '이슬람교', '200', '297'),
+  ('290', '기타 제종교', '200', '299'),
+  ('310', '통계자료', '300', '310'),
+  ('320', '경제학', '300', '330'),
+  ('330', '사회학, 사회문제', '300', '301'),
+  ('340', '정치학', '300', '320'),
+  ('350', '행정학', '300', '351'),
+  ('360', '법률, 법학', '300', '340'),
+  ('370', '교육학', '300', '370'),
+  ('380', '풍습, 예절, 민속학', '300', '390'),
+  ('390', '국방, 군사학', '300', '355'),
+  ('410', '수학', '400', '510'),
+  ('420', '물리학', '400', '530'),
+  ('430', '화학', '400', '540'),
+  ('440', '천문학', '400', '520'),
+  ('450', '지학', '400', '550'),
+  ('460', '광물학', '400', '549'),
+  ('470', '생명과학', '400', '570'),
+  ('480', '식물학', '400', '580'),
+  ('490', '동물학', '400', '590'),
+  ('510', '의학', '500', '610'),
+  ('520', '농업, 농학', '500', '630'),
+  ('530', '공학, 공업일반', '500', '620'),
+  ('540', '건축, 건축학', '500', '690'),
+  ('550', '기계공학', '500', '621'),
+  ('560', '전기공학, 통신공학, 전자공학', '500', '621.3'),
+  ('570', '화학공학', '500', '660'),
+  ('580', '제조업', '500', '670'),
+  ('590', '생활과학', '500', '640'),
+  ('620', '조각, 조형미술', '600', '730'),
+  ('630', '공예', '600', '745'),
+  ('640', '서예', '600', '745.6'),
+  ('650', '회화, 도화, 디자인', '600', '750'),
+  ('660', '사진예술', '600', '770'),
+  ('670', '음악', '600', '780'),
+  ('680', '공연예술, 매체예술', '600', '790'),
+  ('690', '오락, 스포츠', '600', '790'),
+  ('710', '한국어', '700', '495.7'),
+  ('720', '중국어', '700', '495.1'),
+  ('730', '일본어 및 기타 아시아제어', '700', '495.6'),
+  ('740', '영어', '700', '420'),
+  ('750', '독일어', '700', '430'),
+  ('760', '프랑스어', '700', '440'),
+  ('770', '스페인어 및 포르투갈어', '700', '460'),
+  ('780', '이탈리아어', '700', '450'),
+  ('790', '기타 제어', '700', '490'),
+  ('810', '한국문학', '800', '895.7'),
+  ('820', '중국문학', '800', '895.1'),
+  ('830', '일본문학 및 기타 아시아문학', '800', '895.6'),
+  ('840', '영미문학', '800', '820'),
+  ('850', '독일문학', '800', '830'),
+  ('860', '프랑스문학', '800', '840'),
+  ('870', '스페인 및 포르투갈문학', '800', '860'),
+  ('880', '이탈리아문학', '800', '850'),
+  ('890', '기타 제문학', '800', '890'),
+  ('910', '아시아', '900', '950'),
+  ('920', '유럽', '900', '940'),
+  ('930', '아프리카', '900', '960'),
+  ('940', '북아메리카', '900', '970'),
+  ('950', '남아메리카', '900', '980'),
+  ('960', '오세아니아', '900', '990'),
+  ('970', '양극지방', '900', '998'),
+  ('980', '지리', '900', '910'),
+  ('990', '전기', '900', '920');
+
+-- 자주 쓰는 요목
+INSERT IGNORE INTO subjects (code, name, parent_code, ddc_code) VALUES
+  ('004', '컴퓨터과학', '000', '004'),
+  ('005', '프로그래밍, 프로그램, 데이터', '000', '005'),
+  ('325', '경영', '320', '658'),
+  ('813', '한국소설', '810', '895.73'),
+  ('814', '한국수필', '810', '895.74'),
+  ('843', '영미소설', '840', '823'),
+  ('833', '일본소설', '830', '895.63');
@@ -0,0 +1,37 @@
+-- 저자 전거와 도서별 기여자(저자, 역자, 편자, 삽화가)
+-- books.author는 목록 표시와 정렬에 쓰는 대표 저자 문자열로 유지하며,
+-- 기여자를 변경하면 role이 author인 기여자 이름으로 다시 채운다.
+
+CREATE TABLE IF NOT EXISTS authors (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  variant_names VARCHAR(500) NULL,
+  birth_year INT NULL,
+  death_year INT NULL,
+  note TEXT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  INDEX idx_authors_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS book_contributors (
+  book_id VARCHAR(50) NOT NULL,
+  author_id INT NOT NULL,
+  role ENUM('author', 'translator', 'editor', 'illustrator') NOT NULL DEFAULT 'author',
+  position INT NOT NULL DEFAULT 1,
+  PRIMARY KEY (book_id, author_id, role),
+  INDEX idx_book_contributors_author (author_id),
+  CONSTRAINT fk_book_contributors_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_contributors_author FOREIGN KEY (author_id) REFERENCES authors(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 기존 author 문자열을 전거로 옮긴다 (같은 이름은 한 사람으로 본다)
+INSERT INTO authors (name)
+SELECT DISTINCT b.author FROM books b
+WHERE b.author <> '' AND NOT EXISTS (SELECT 1 FROM authors a WHERE a.name = b.author);
+
+INSERT IGNORE INTO book_contributors (book_id, author_id, role, position)
+SELECT b.id, MIN(a.id), 'author', 1
+FROM books b
+JOIN authors a ON a.name = b.author
+GROUP BY b.id;
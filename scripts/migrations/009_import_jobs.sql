@@ -0,0 +1,36 @@
+-- 도서 일괄 가져오기(CSV/NDJSON) 작업과 행별 오류
+-- 작업은 업로드를 받은 book-service 인스턴스가 백그라운드로 처리하고,
+-- 진행 상황은 테이블에 기록하므로 어느 인스턴스에서나 조회할 수 있다.
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+  id VARCHAR(36) PRIMARY KEY,
+  format ENUM('csv', 'ndjson') NOT NULL,
+  status ENUM('queued', 'running', 'completed', 'failed') NOT NULL DEFAULT 'queued',
+  dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+  on_duplicate ENUM('update', 'skip') NOT NULL DEFAULT 'update',
+  copies_per_row INT NOT NULL DEFAULT 0,
+  total_rows INT NOT NULL DEFAULT 0,
+  processed_rows INT NOT NULL DEFAULT 0,
+  created_count INT NOT NULL DEFAULT 0,
+  updated_count INT NOT NULL DEFAULT 0,
+  skipped_count INT NOT NULL DEFAULT 0,
+  failed_count INT NOT NULL DEFAULT 0,
+  copies_created INT NOT NULL DEFAULT 0,
+  error_message TEXT NULL,
+  created_by VARCHAR(50) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP NULL,
+  finished_at TIMESTAMP NULL,
+  INDEX idx_import_jobs_created (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS import_job_errors (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  job_id VARCHAR(36) NOT NULL,
+  row_no INT NOT NULL,
+  field VARCHAR(50) NOT NULL DEFAULT '',
+  value VARCHAR(500) NOT NULL DEFAULT '',
+  message VARCHAR(255) NOT NULL,
+  INDEX idx_import_job_errors_job (job_id, row_no),
+  CONSTRAINT fk_import_job_errors_job FOREIGN KEY (job_id) REFERENCES import_jobs(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+-- 업로드하거나 원격 URL에서 가져와 저장한 표지
+-- 원본과 썸네일은 blob 저장소(COVER_STORE)의 covers/<book_id>/<version>/ 아래에 둔다.
+-- version은 원본 SHA-256의 앞 16자리로, 표지가 바뀌면 URL도 바뀌어 오래 캐시할 수 있다.
+-- books.cover_image에는 제공 URL(/api/books/<id>/cover/<version>/md)을 기록한다.
+
+CREATE TABLE IF NOT EXISTS book_covers (
+  book_id VARCHAR(50) PRIMARY KEY,
+  version VARCHAR(16) NOT NULL,
+  content_type VARCHAR(50) NOT NULL,
+  width INT NOT NULL,
+  height INT NOT NULL,
+  byte_size INT NOT NULL,
+  source_url VARCHAR(500) NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_book_covers_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+-- 복본 등록번호(바코드)
+-- 값은 book-service가 채운다: 접두어 + 0으로 채운 복본 ID + Luhn 체크 숫자 (ITEM_BARCODE_PREFIX, ITEM_BARCODE_DIGITS).
+-- 기존 복본은 book-service 시작 시 비어 있는 바코드를 일괄 부여한다.
+
+ALTER TABLE book_copies
+  ADD COLUMN IF NOT EXISTS barcode VARCHAR(32) NULL,
+  ADD UNIQUE INDEX IF NOT EXISTS uk_book_copies_barcode (barcode);
@@ -0,0 +1,24 @@
+-- 복본 상태 값 제한과 상태 변경 이력
+-- 허용하는 전이와 사유 코드는 book-service(copy_status.go)에 정의한다.
+-- 대출/반납에 따른 변경은 borrow-service가 같은 이력 테이블에 기록한다.
+
+-- 알 수 없는 상태는 수선 중으로 돌려 점검하게 한다
+UPDATE book_copies SET status = 'maintenance'
+WHERE status NOT IN ('available', 'borrowed', 'on_hold', 'in_transit', 'maintenance', 'lost', 'withdrawn');
+
+ALTER TABLE book_copies
+  MODIFY status ENUM('available', 'borrowed', 'on_hold', 'in_transit', 'maintenance', 'lost', 'withdrawn')
+    NOT NULL DEFAULT 'available';
+
+CREATE TABLE IF NOT EXISTS copy_status_history (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  copy_id INT NOT NULL,
+  from_status VARCHAR(20) NULL,
+  to_status VARCHAR(20) NOT NULL,
+  reason VARCHAR(30) NOT NULL,
+  note VARCHAR(255) NULL,
+  actor VARCHAR(50) NOT NULL,
+  changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_copy_status_history_copy (copy_id, changed_at),
+  CONSTRAINT fk_copy_status_history_copy FOREIGN KEY (copy_id) REFERENCES book_copies(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
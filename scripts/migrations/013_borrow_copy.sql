@@ -0,0 +1,17 @@
+-- 대출 기록에 실제로 빌려 간 복본을 남긴다.
+-- 기존 대출 기록의 copy_id는 비어 있으며, borrow-service의 대출 정리 도구
+-- (POST /borrows/admin/reconcile)로 대출 중인 복본과 연결한다.
+
+ALTER TABLE borrows
+  ADD COLUMN IF NOT EXISTS copy_id INT NULL AFTER book_id,
+  ADD INDEX IF NOT EXISTS idx_borrows_copy (copy_id, status);
+
+ALTER TABLE borrows
+  ADD CONSTRAINT fk_borrows_copy FOREIGN KEY IF NOT EXISTS (copy_id) REFERENCES book_copies(id) ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description) VALUES
+  ('circulation:reconcile', '대출 기록과 복본 상태 정리')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+  ('admin', 'circulation:reconcile');
@@ -0,0 +1,51 @@
+-- 분관(열람실)과 위치 계층: 분관(branch) > 층(floor) > 서가(shelf)
+-- 복본은 소속 분관(branch_id)과 선택적인 세부 위치(location_id, 그 분관의 층 또는 서가)를 가진다.
+-- 기존 book_copies.location 문자열은 비고 성격의 위치 메모로 남겨 둔다.
+
+CREATE TABLE IF NOT EXISTS branches (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  parent_id INT NULL,
+  code VARCHAR(20) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  level ENUM('branch', 'floor', 'shelf') NOT NULL,
+  sort_order INT NOT NULL DEFAULT 0,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uk_branches_code (code),
+  INDEX idx_branches_parent (parent_id, sort_order),
+  CONSTRAINT fk_branches_parent FOREIGN KEY (parent_id) REFERENCES branches(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 기존 복본은 본관으로 옮긴다
+INSERT INTO branches (code, name, level, sort_order)
+  SELECT 'MAIN', '본관', 'branch', 0 FROM DUAL
+  WHERE NOT EXISTS (SELECT 1 FROM branches WHERE level = 'branch');
+
+ALTER TABLE book_copies
+  ADD COLUMN IF NOT EXISTS branch_id INT NULL AFTER status,
+  ADD COLUMN IF NOT EXISTS location_id INT NULL AFTER branch_id,
+  ADD INDEX IF NOT EXISTS idx_book_copies_branch (branch_id, status);
+
+ALTER TABLE book_copies
+  ADD CONSTRAINT fk_book_copies_branch FOREIGN KEY IF NOT EXISTS (branch_id) REFERENCES branches(id),
+  ADD CONSTRAINT fk_book_copies_location FOREIGN KEY IF NOT EXISTS (location_id) REFERENCES branches(id);
+
+UPDATE book_copies
+SET branch_id = (SELECT id FROM branches WHERE level = 'branch' ORDER BY sort_order, id LIMIT 1)
+WHERE branch_id IS NULL;
+
+-- 대출/반납한 분관
+ALTER TABLE borrows
+  ADD COLUMN IF NOT EXISTS branch_id INT NULL AFTER copy_id,
+  ADD COLUMN IF NOT EXISTS return_branch_id INT NULL AFTER branch_id;
+
+-- 예약 도서를 받을 분관 (NULL이면 어느 분관이든)
+ALTER TABLE reservations
+  ADD COLUMN IF NOT EXISTS pickup_branch_id INT NULL AFTER book_id;
+
+INSERT INTO permissions (name, description) VALUES
+  ('branches:write', '분관/층/서가 등록/수정/삭제')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+  ('admin', 'branches:write');
@@ -0,0 +1,35 @@
+-- 분관 간 복본 이관
+-- kind: return(다른 분관에 반납된 복본을 소속 분관으로), hold(예약 도서를 받을 분관으로),
+--       relocate(소속 분관 변경, 도착하면 복본의 branch_id가 바뀐다)
+-- status: requested(요청) -> in_transit(발송) -> received(도착), 요청 단계에서만 cancelled(취소) 가능
+
+CREATE TABLE IF NOT EXISTS copy_transfers (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  copy_id INT NOT NULL,
+  from_branch_id INT NULL,
+  to_branch_id INT NOT NULL,
+  kind ENUM('return', 'hold', 'relocate') NOT NULL,
+  reservation_id INT NULL,
+  status ENUM('requested', 'in_transit', 'received', 'cancelled') NOT NULL DEFAULT 'requested',
+  note VARCHAR(255) NULL,
+  requested_by VARCHAR(50) NOT NULL,
+  requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  shipped_at TIMESTAMP NULL,
+  received_by VARCHAR(50) NULL,
+  received_at TIMESTAMP NULL,
+  INDEX idx_copy_transfers_copy (copy_id, status),
+  INDEX idx_copy_transfers_route (from_branch_id, status, to_branch_id),
+  INDEX idx_copy_transfers_reservation (reservation_id),
+  CONSTRAINT fk_copy_transfers_copy FOREIGN KEY (copy_id) REFERENCES book_copies(id) ON DELETE CASCADE,
+  CONSTRAINT fk_copy_transfers_from FOREIGN KEY (from_branch_id) REFERENCES branches(id),
+  CONSTRAINT fk_copy_transfers_to FOREIGN KEY (to_branch_id) REFERENCES branches(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO permissions (name, description) VALUES
+  ('transfers:read', '분관 간 이관 목록/발송 목록 조회'),
+  ('transfers:write', '분관 간 이관 요청/발송/도착/취소')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+  ('admin', 'transfers:read'),
+  ('admin', 'transfers:write');
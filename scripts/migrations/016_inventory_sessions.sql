@@ -0,0 +1,52 @@
+-- 장서 점검(재고 조사)
+-- 분관 전체 또는 그 분관의 위치 범위(from_location_id ~ to_location_id, 위치 트리 순서)를 대상으로
+-- 서가의 복본 바코드를 스캔해 넣고, 마감할 때 서가에 있어야 할 복본(inventory_expected)을 확정한다.
+
+CREATE TABLE IF NOT EXISTS inventory_sessions (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  branch_id INT NOT NULL,
+  from_location_id INT NULL,
+  to_location_id INT NULL,
+  status ENUM('open', 'closed', 'cancelled') NOT NULL DEFAULT 'open',
+  note VARCHAR(255) NULL,
+  opened_by VARCHAR(50) NOT NULL,
+  opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  closed_by VARCHAR(50) NULL,
+  closed_at TIMESTAMP NULL,
+  INDEX idx_inventory_sessions_branch (branch_id, status),
+  CONSTRAINT fk_inventory_sessions_branch FOREIGN KEY (branch_id) REFERENCES branches(id),
+  CONSTRAINT fk_inventory_sessions_from FOREIGN KEY (from_location_id) REFERENCES branches(id),
+  CONSTRAINT fk_inventory_sessions_to FOREIGN KEY (to_location_id) REFERENCES branches(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 스캔한 바코드 (같은 바코드를 다시 스캔하면 위치와 시각만 갱신)
+CREATE TABLE IF NOT EXISTS inventory_scans (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  session_id INT NOT NULL,
+  barcode VARCHAR(32) NOT NULL,
+  copy_id INT NULL,
+  location_id INT NULL,
+  scanned_by VARCHAR(50) NOT NULL,
+  scanned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY uk_inventory_scans_barcode (session_id, barcode),
+  CONSTRAINT fk_inventory_scans_session FOREIGN KEY (session_id) REFERENCES inventory_sessions(id) ON DELETE CASCADE,
+  CONSTRAINT fk_inventory_scans_copy FOREIGN KEY (copy_id) REFERENCES book_copies(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 마감 시점에 점검 범위에서 대여 가능(available) 상태였던 복본
+CREATE TABLE IF NOT EXISTS inventory_expected (
+  session_id INT NOT NULL,
+  copy_id INT NOT NULL,
+  PRIMARY KEY (session_id, copy_id),
+  CONSTRAINT fk_inventory_expected_session FOREIGN KEY (session_id) REFERENCES inventory_sessions(id) ON DELETE CASCADE,
+  CONSTRAINT fk_inventory_expected_copy FOREIGN KEY (copy_id) REFERENCES book_copies(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO permissions (name, description) VALUES
+  ('inventory:read', '장서 점검 목록/결과 조회'),
+  ('inventory:write', '장서 점검 시작/스캔/마감, 미발견 복본 분실 처리')
+ON DUPLICATE KEY UPDATE description = VALUES(description);
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+  ('admin', 'inventory:read'),
+  ('admin', 'inventory:write');
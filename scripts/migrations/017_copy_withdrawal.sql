@@ -0,0 +1,15 @@
+-- 복본 제적: 행을 지우지 않고 status = 'withdrawn'으로 두고 사유와 제적일을 남긴다.
+-- 제적한 복본은 소장/대여 가능 수와 공개 복본 목록에서 빠지지만 이력과 통계에는 남는다.
+
+ALTER TABLE book_copies
+  ADD COLUMN IF NOT EXISTS withdrawn_at DATE NULL,
+  ADD COLUMN IF NOT EXISTS withdrawn_reason VARCHAR(30) NULL,
+  ADD COLUMN IF NOT EXISTS withdrawn_note VARCHAR(255) NULL,
+  ADD COLUMN IF NOT EXISTS withdrawn_by VARCHAR(50) NULL;
+
+-- 이미 제적 상태인 복본은 마지막 제적 이력 날짜로 채운다
+UPDATE book_copies bc
+SET withdrawn_at = COALESCE((SELECT DATE(MAX(h.changed_at)) FROM copy_status_history h
+                             WHERE h.copy_id = bc.id AND h.to_status = 'withdrawn'), CURDATE()),
+    withdrawn_reason = 'other'
+WHERE bc.status = 'withdrawn' AND bc.withdrawn_at IS NULL;
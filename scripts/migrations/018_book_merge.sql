@@ -0,0 +1,48 @@
+-- 중복 도서 찾기와 병합
+-- 찾기 작업(duplicate_scans)은 정규화한 ISBN, 제목 유사도, 저자로 중복 의심 쌍(book_duplicates)을 기록한다.
+-- book_id는 먼저 등록된 도서(병합할 때 남길 후보), duplicate_id는 나중에 등록된 도서다.
+-- 병합하면 복본/대출/예약을 남는 도서로 옮기고 없어진 도서 ID는 book_redirects로 남는 도서를 가리킨다.
+
+CREATE TABLE IF NOT EXISTS duplicate_scans (
+  id VARCHAR(36) PRIMARY KEY,
+  status ENUM('queued', 'running', 'completed', 'failed') NOT NULL DEFAULT 'queued',
+  checked_books INT NOT NULL DEFAULT 0,
+  pairs_found INT NOT NULL DEFAULT 0,
+  error_message TEXT NULL,
+  created_by VARCHAR(50) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  started_at TIMESTAMP NULL,
+  finished_at TIMESTAMP NULL,
+  INDEX idx_duplicate_scans_created (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- status: open(검토 전), dismissed(중복 아님으로 판정, 다음 찾기에서도 다시 열지 않는다)
+CREATE TABLE IF NOT EXISTS book_duplicates (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  book_id VARCHAR(50) NOT NULL,
+  duplicate_id VARCHAR(50) NOT NULL,
+  isbn_match BOOLEAN NOT NULL DEFAULT FALSE,
+  author_match BOOLEAN NOT NULL DEFAULT FALSE,
+  title_similarity DECIMAL(4, 3) NOT NULL DEFAULT 0,
+  score DECIMAL(4, 1) NOT NULL DEFAULT 0,
+  status ENUM('open', 'dismissed') NOT NULL DEFAULT 'open',
+  scan_id VARCHAR(36) NULL,
+  found_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  dismissed_by VARCHAR(50) NULL,
+  dismissed_at TIMESTAMP NULL,
+  UNIQUE KEY uk_book_duplicates_pair (book_id, duplicate_id),
+  INDEX idx_book_duplicates_status (status, score),
+  INDEX idx_book_duplicates_duplicate (duplicate_id),
+  CONSTRAINT fk_book_duplicates_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_duplicates_duplicate FOREIGN KEY (duplicate_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS book_redirects (
+  old_id VARCHAR(50) PRIMARY KEY,
+  book_id VARCHAR(50) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  merged_by VARCHAR(50) NOT NULL,
+  merged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_book_redirects_book (book_id),
+  CONSTRAINT fk_book_redirects_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 출판 연도를 모르는 도서는 0 대신 NULL로 저장한다 (연도 패싯에 "0년대"가 생기지 않도록)
+UPDATE books SET year = NULL WHERE year = 0;
@@ -60,6 +60,24 @@ func main() {
 	router.Any("/api/books/*path", func(c *gin.Context) {
 		proxyRequest(c, bookServiceAddr, "/api/books", "/books")
 	})
+	router.Any("/api/subjects", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/subjects", "/subjects")
+	})
+	router.Any("/api/subjects/*path", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/subjects", "/subjects")
+	})
+	router.Any("/api/authors", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/authors", "/authors")
+	})
+	router.Any("/api/authors/*path", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/authors", "/authors")
+	})
+	router.Any("/api/copies/*path", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/copies", "/copies")
+	})
+	router.Any("/api/branches", func(c *gin.Context) {
+		proxyRequest(c, bookServiceAddr, "/api/branches", "/branches")
+	})
 
 	// Borrow 서비스 (대출)
 	router.Any("/api/borrows", func(c *gin.Context) {
@@ -156,7 +174,7 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// 도서 서지 등록/수정 요청
+type BookInput struct {
+	Title       string  `json:"title"`
+	Author      string  `json:"author"`
+	Publisher   string  `json:"publisher"`
+	Year        int     `json:"year"`
+	ISBN        string  `json:"isbn"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	CoverImage  string  `json:"cover_image"`
+}
+
+// 부분 수정 요청 (전달된 필드만 변경)
+type BookPatch struct {
+	Title       *string  `json:"title"`
+	Author      *string  `json:"author"`
+	Publisher   *string  `json:"publisher"`
+	Year        *int     `json:"year"`
+	ISBN        *string  `json:"isbn"`
+	Description *string  `json:"description"`
+	Price       *float64 `json:"price"`
+	CoverImage  *string  `json:"cover_image"`
+}
+
+// 출판 연도 허용 범위의 하한
+const minPublicationYear = 1000
+
+// 필드별 검증 (오류가 없으면 빈 map). ISBN은 ISBN-13 숫자열로 정규화한다.
+// legacyISBN은 부분 수정에서 바꾸지 않은 기존 값으로, 형식이 잘못되어도 그대로 둔다.
+func validateBookInput(input *BookInput, legacyISBN string) map[string]string {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Author = strings.TrimSpace(input.Author)
+	input.Publisher = strings.TrimSpace(input.Publisher)
+	input.ISBN = strings.TrimSpace(input.ISBN)
+	input.CoverImage = strings.TrimSpace(input.CoverImage)
+
+	errs := map[string]string{}
+
+	if input.Title == "" {
+		errs["title"] = "제목은 필수입니다"
+	} else if utf8.RuneCountInString(input.Title) > 255 {
+		errs["title"] = "제목은 255자 이하여야 합니다"
+	}
+	if input.Author == "" {
+		errs["author"] = "저자는 필수입니다"
+	} else if utf8.RuneCountInString(input.Author) > 100 {
+		errs["author"] = "저자는 100자 이하여야 합니다"
+	}
+	if utf8.RuneCountInString(input.Publisher) > 100 {
+		errs["publisher"] = "출판사는 100자 이하여야 합니다"
+	}
+	maxYear := time.Now().Year() + 1
+	if input.Year != 0 && (input.Year < minPublicationYear || input.Year > maxYear) {
+		errs["year"] = "출판 연도가 허용 범위를 벗어났습니다"
+	}
+	if input.ISBN != "" && (legacyISBN == "" || input.ISBN != legacyISBN) {
+		if normalized, err := normalizeISBN(input.ISBN); err != nil {
+			errs["isbn"] = err.Error()
+		} else {
+			input.ISBN = normalized
+		}
+	}
+	if input.Price < 0 {
+		errs["price"] = "가격은 0 이상이어야 합니다"
+	}
+	if len(input.CoverImage) > 500 {
+		errs["cover_image"] = "표지 이미지 URL은 500자 이하여야 합니다"
+	}
+
+	return errs
+}
+
+// 관리자: 도서 서지 등록 (ID는 서버에서 생성)
+func handleCreateBook(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var input BookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if errs := validateBookInput(&input, ""); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	if conflict, err := isbnInUse(input.ISBN, ""); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 등록에 실패했습니다"})
+		return
+	} else if conflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 등록된 ISBN입니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 등록에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	bookID := uuid.New().String()
+	query := `INSERT INTO books (id, title, author, publisher, year, isbn, description, price, cover_image)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query, bookID, input.Title, input.Author, input.Publisher, nullIfZero(input.Year),
+		nullIfEmpty(input.ISBN), input.Description, input.Price, input.CoverImage)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 등록에 실패했습니다"})
+		return
+	}
+	// 대표 저자를 전거에 연결 (역자 등은 /admin/books/:id/contributors로 지정)
+	if err := linkDefaultAuthor(tx, bookID, input.Author); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 등록에 실패했습니다"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 등록에 실패했습니다"})
+		return
+	}
+
+	book, err := fetchBook(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 조회에 실패했습니다"})
+		return
+	}
+
+	indexBook(book)
+
+	log.Printf("Admin %v created book %s (%s)", adminID, bookID, input.Title)
+	c.JSON(http.StatusCreated, book)
+}
+
+// 관리자: 도서 서지 전체 수정
+func handleReplaceBook(c *gin.Context) {
+	bookID := c.Param("id")
+
+	var input BookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	saveBook(c, bookID, input, "")
+}
+
+// 관리자: 도서 서지 부분 수정
+func handlePatchBook(c *gin.Context) {
+	bookID := c.Param("id")
+
+	var patch BookPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	current, err := fetchBook(bookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 조회에 실패했습니다"})
+		return
+	}
+
+	input := BookInput{
+		Title:       current.Title,
+		Author:      current.Author,
+		Publisher:   current.Publisher,
+		Year:        current.Year,
+		ISBN:        current.ISBN,
+		Description: current.Description,
+		Price:       current.Price,
+		CoverImage:  current.CoverImage,
+	}
+	if patch.Title != nil {
+		input.Title = *patch.Title
+	}
+	if patch.Author != nil {
+		input.Author = *patch.Author
+	}
+	if patch.Publisher != nil {
+		input.Publisher = *patch.Publisher
+	}
+	if patch.Year != nil {
+		input.Year = *patch.Year
+	}
+	if patch.ISBN != nil {
+		input.ISBN = *patch.ISBN
+	}
+	if patch.Description != nil {
+		input.Description = *patch.Description
+	}
+	if patch.Price != nil {
+		input.Price = *patch.Price
+	}
+	if patch.CoverImage != nil {
+		input.CoverImage = *patch.CoverImage
+	}
+
+	saveBook(c, bookID, input, current.ISBN)
+}
+
+// PUT/PATCH 공통: 검증 후 저장하고 변경된 도서를 응답
+func saveBook(c *gin.Context, bookID string, input BookInput, legacyISBN string) {
+	adminID, _ := c.Get("user_id")
+
+	if errs := validateBookInput(&input, legacyISBN); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	if conflict, err := isbnInUse(input.ISBN, bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+		return
+	} else if conflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 등록된 ISBN입니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var currentAuthor string
+	if err := tx.QueryRow("SELECT author FROM books WHERE id = ? FOR UPDATE", bookID).Scan(&currentAuthor); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+		return
+	}
+
+	query := `UPDATE books
+	          SET title = ?, author = ?, publisher = ?, year = ?, isbn = ?, description = ?, price = ?, cover_image = ?
+	          WHERE id = ?`
+	_, err = tx.Exec(query, input.Title, input.Author, input.Publisher, nullIfZero(input.Year),
+		nullIfEmpty(input.ISBN), input.Description, input.Price, input.CoverImage, bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+		return
+	}
+	// 대표 저자가 바뀌면 저자 기여자를 새 저자로 교체한다 (역자 등 다른 역할은 유지)
+	if currentAuthor != input.Author {
+		if _, err := tx.Exec("DELETE FROM book_contributors WHERE book_id = ? AND role = 'author'", bookID); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+			return
+		}
+		if err := linkDefaultAuthor(tx, bookID, input.Author); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 수정에 실패했습니다"})
+		return
+	}
+
+	book, err := fetchBook(bookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 조회에 실패했습니다"})
+		return
+	}
+
+	indexBook(book)
+
+	log.Printf("Admin %v updated book %s", adminID, bookID)
+	c.JSON(http.StatusOK, book)
+}
+
+// 관리자: 도서 서지 삭제 (복본이 없는 도서만 삭제, 복본 이력이 있으면 거부)
+func handleDeleteBook(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM books WHERE id = ? FOR UPDATE", bookID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	// 복본이 한 권이라도 등록된 적이 있는 도서는 삭제하지 않는다 (서지 삭제는 복본이 없는 도서만 가능).
+	// 제적한 복본도 상태 이력과 제적 사유를 보존해야 하므로 함께 센다. 대여/이관/예약 보관 중인 복본도
+	// 여기서 걸러진다. 복본은 제적 처리(/admin/copies/withdraw)로, 중복 서지는 병합(/admin/books/:id/merge)으로 정리한다.
+	var copies int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM book_copies WHERE book_id = ? FOR UPDATE", bookID).Scan(&copies); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+	if copies > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "복본 이력이 있는 도서는 삭제할 수 없습니다(제적한 복본 포함). 복본은 제적 처리(/admin/copies/withdraw)를, 중복 서지는 병합을 이용하세요",
+			"copies":   copies,
+			"withdraw": "/admin/copies/withdraw",
+			"merge":    "/admin/books/" + bookID + "/merge",
+		})
+		return
+	}
+
+	// 남아 있는 예약은 취소 처리
+	if _, err := tx.Exec("UPDATE reservations SET status = 'cancelled' WHERE book_id = ? AND status = 'active'", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+
+	// 저장된 표지 파일은 커밋 후 지운다 (book_covers 행은 FK로 함께 삭제)
+	var coverVersion, coverType string
+	err = tx.QueryRow("SELECT version, content_type FROM book_covers WHERE book_id = ?", bookID).Scan(&coverVersion, &coverType)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM books WHERE id = ?", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 삭제에 실패했습니다"})
+		return
+	}
+
+	unindexBook(bookID)
+	if coverVersion != "" {
+		deleteCoverBlobs(bookID, coverVersion, coverType)
+	}
+
+	log.Printf("Admin %v deleted book %s", adminID, bookID)
+	c.JSON(http.StatusOK, gin.H{"message": "도서가 삭제되었습니다"})
+}
+
+// 다른 도서가 같은 ISBN을 사용 중인지 확인 (excludeID는 제외)
+func isbnInUse(isbn, excludeID string) (bool, error) {
+	if isbn == "" {
+		return false, nil
+	}
+	clause := "isbn = ?"
+	args := []interface{}{isbn}
+	// 정규화된 ISBN이면 하이픈/ISBN-10으로 저장된 기존 도서와도 비교
+	if normalized, err := normalizeISBN(isbn); err == nil && normalized == isbn {
+		clause, args = isbnMatchClause(isbn)
+	}
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM books WHERE "+clause+" AND id <> ?", append(args, excludeID)...).Scan(&count)
+	return count > 0, err
+}
+
+// books.isbn은 UNIQUE이므로 빈 값은 NULL로 저장
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
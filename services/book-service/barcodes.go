@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errBarcodeChecksum = errors.New("바코드 체크 숫자가 올바르지 않습니다")
+	errBarcodeFormat   = errors.New("바코드는 영문 대문자, 숫자, 하이픈으로 32자 이하여야 합니다")
+)
+
+// 복본 등록번호 형식: 접두어 + Digits자리 복본 ID + Luhn 체크 숫자 (예: LB000001234 + 체크 숫자)
+type barcodeFormat struct {
+	Prefix string
+	Digits int
+}
+
+var itemBarcode = barcodeFormat{Prefix: "LB", Digits: 8}
+
+// ITEM_BARCODE_PREFIX, ITEM_BARCODE_DIGITS로 형식을 바꿀 수 있다.
+func loadBarcodeFormat() barcodeFormat {
+	format := itemBarcode
+	format.Prefix = strings.ToUpper(getEnv("ITEM_BARCODE_PREFIX", format.Prefix))
+	if digits, err := strconv.Atoi(getEnv("ITEM_BARCODE_DIGITS", "")); err == nil && digits >= 4 && digits <= 20 {
+		format.Digits = digits
+	}
+	return format
+}
+
+func (f barcodeFormat) generate(copyID int64) string {
+	body := fmt.Sprintf("%0*d", f.Digits, copyID)
+	return f.Prefix + body + string(luhnCheckDigit(body))
+}
+
+// 이 형식으로 만든 바코드이면 체크 숫자를 확인한다. 다른 형식(기존 라벨 등)은 그대로 통과시킨다.
+func (f barcodeFormat) verify(code string) error {
+	if !strings.HasPrefix(code, f.Prefix) {
+		return nil
+	}
+	body := code[len(f.Prefix):]
+	if len(body) < f.Digits+1 || strings.Trim(body, "0123456789") != "" {
+		return nil
+	}
+	if luhnCheckDigit(body[:len(body)-1]) != body[len(body)-1] {
+		return errBarcodeChecksum
+	}
+	return nil
+}
+
+// 스캐너 입력의 공백을 없애고 대문자로 맞춘다.
+func normalizeBarcode(raw string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if code == "" || len(code) > 32 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-") != "" {
+		return "", errBarcodeFormat
+	}
+	return code, nil
+}
+
+func luhnCheckDigit(digits string) byte {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// 새로 만든 복본에 바코드를 부여한다 (이미 있으면 그대로 둔다).
+func assignCopyBarcode(exec sqlExecer, copyID int64) (string, error) {
+	barcode := itemBarcode.generate(copyID)
+	_, err := exec.Exec("UPDATE book_copies SET barcode = ? WHERE id = ? AND barcode IS NULL", barcode, copyID)
+	return barcode, err
+}
+
+// 바코드가 없는 기존 복본에 바코드를 부여한다 (서비스 시작 시 실행).
+func backfillCopyBarcodes() error {
+	rows, err := db.Query("SELECT id FROM book_copies WHERE barcode IS NULL")
+	if err != nil {
+		return err
+	}
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := assignCopyBarcode(db, id); err != nil {
+			return err
+		}
+	}
+	if len(ids) > 0 {
+		log.Printf("Assigned barcodes to %d copies", len(ids))
+	}
+	return nil
+}
+
+// 바코드로 복본과 도서 조회 (대출 데스크 스캔용)
+func handleGetCopyByBarcode(c *gin.Context) {
+	code, err := normalizeBarcode(c.Param("code"))
+	if err == nil {
+		err = itemBarcode.verify(code)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var copy BookCopy
+	err = db.QueryRow(`SELECT id, book_id, copy_number, status, branch_id, location_id, location, acquired_date, COALESCE(notes, ''), COALESCE(barcode, '')
+	                   FROM book_copies WHERE barcode = ?`, code).
+		Scan(&copy.ID, &copy.BookID, &copy.CopyNumber, &copy.Status, &copy.BranchID, &copy.LocationID, &copy.Location, &copy.AcquiredDate, &copy.Notes, &copy.Barcode)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다", "barcode": code})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 조회에 실패했습니다"})
+		return
+	}
+
+	_, branches, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 조회에 실패했습니다"})
+		return
+	}
+	copy.fillPlacement(branches)
+
+	book, err := fetchBook(copy.BookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 조회에 실패했습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"copy": copy, "book": book})
+}
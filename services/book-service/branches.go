@@ -0,0 +1,421 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errBranchNotFound   = errors.New("분관을 찾을 수 없습니다")
+	errLocationNotFound = errors.New("위치를 찾을 수 없습니다")
+	errLocationBranch   = errors.New("위치가 복본의 분관에 속하지 않습니다")
+)
+
+// 분관/층/서가. 분관은 parent_id가 없고, 층은 분관 아래, 서가는 층 아래에 둔다.
+type Branch struct {
+	ID        int       `json:"id"`
+	ParentID  *int      `json:"parent_id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Level     string    `json:"level"`
+	SortOrder int       `json:"sort_order"`
+	Active    bool      `json:"active"`
+	Children  []*Branch `json:"children,omitempty"`
+}
+
+// 분관 등록/수정 요청
+type BranchInput struct {
+	ParentID  *int   `json:"parent_id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+	Active    *bool  `json:"active"`
+}
+
+// 분관별 소장 현황 (도서 상세용)
+type BranchHolding struct {
+	BranchID        int    `json:"branch_id"`
+	Code            string `json:"code"`
+	Name            string `json:"name"`
+	TotalCopies     int    `json:"total_copies"`
+	AvailableCopies int    `json:"available_copies"`
+}
+
+// 상위 단계별 하위 단계
+var branchChildLevel = map[string]string{
+	"":       "branch",
+	"branch": "floor",
+	"floor":  "shelf",
+}
+
+// 분관/층/서가 전체를 읽어 트리로 만든다 (정렬 순서, ID 순).
+func loadBranchTree() (roots []*Branch, byID map[int]*Branch, err error) {
+	rows, err := db.Query(`SELECT id, parent_id, code, name, level, sort_order, active
+	                       FROM branches ORDER BY sort_order, id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ordered := []*Branch{}
+	byID = map[int]*Branch{}
+	for rows.Next() {
+		var b Branch
+		var parent sql.NullInt64
+		if err := rows.Scan(&b.ID, &parent, &b.Code, &b.Name, &b.Level, &b.SortOrder, &b.Active); err != nil {
+			return nil, nil, err
+		}
+		if parent.Valid {
+			id := int(parent.Int64)
+			b.ParentID = &id
+		}
+		ordered = append(ordered, &b)
+		byID[b.ID] = &b
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	roots = []*Branch{}
+	for _, b := range ordered {
+		if b.ParentID == nil {
+			roots = append(roots, b)
+			continue
+		}
+		if parent, ok := byID[*b.ParentID]; ok {
+			parent.Children = append(parent.Children, b)
+		}
+	}
+	return roots, byID, nil
+}
+
+// 위치가 속한 분관 ID (분관이면 자기 자신)
+func branchRoot(byID map[int]*Branch, id int) int {
+	node, ok := byID[id]
+	for ok && node.ParentID != nil {
+		id = *node.ParentID
+		node, ok = byID[id]
+	}
+	return id
+}
+
+// "본관 > 2층 > A-03" 형태의 위치 경로
+func branchPath(byID map[int]*Branch, id int) string {
+	names := []string{}
+	for node, ok := byID[id]; ok; {
+		names = append([]string{node.Name}, names...)
+		if node.ParentID == nil {
+			break
+		}
+		node, ok = byID[*node.ParentID]
+	}
+	return strings.Join(names, " > ")
+}
+
+// 분관 코드 또는 ID로 분관 ID를 찾는다 (없으면 sql.ErrNoRows).
+func resolveBranch(value string) (int, error) {
+	var id int
+	err := db.QueryRow("SELECT id FROM branches WHERE level = 'branch' AND (code = ? OR id = ?)",
+		strings.ToUpper(strings.TrimSpace(value)), value).Scan(&id)
+	return id, err
+}
+
+// 분관을 지정하지 않은 새 복본이 들어갈 분관 (가장 앞선 운영 중인 분관)
+func defaultBranchID(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) (int, error) {
+	var id int
+	err := q.QueryRow(`SELECT id FROM branches WHERE level = 'branch' AND active = TRUE
+	                   ORDER BY sort_order, id LIMIT 1`).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// 복본의 분관과 세부 위치를 확인한다. 분관을 생략하면 기본 분관, 위치는 그 분관의 층이나 서가여야 한다.
+func resolveCopyPlacement(branchID, locationID *int) (int, interface{}, error) {
+	_, byID, err := loadBranchTree()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	branch := 0
+	if branchID != nil {
+		node, ok := byID[*branchID]
+		if !ok || node.Level != "branch" {
+			return 0, nil, errBranchNotFound
+		}
+		branch = node.ID
+	} else if locationID != nil {
+		branch = branchRoot(byID, *locationID)
+	} else if branch, err = defaultBranchID(db); err != nil {
+		return 0, nil, err
+	}
+
+	if locationID == nil {
+		return branch, nil, nil
+	}
+	node, ok := byID[*locationID]
+	if !ok || node.Level == "branch" {
+		return 0, nil, errLocationNotFound
+	}
+	if branchRoot(byID, node.ID) != branch {
+		return 0, nil, errLocationBranch
+	}
+	return branch, node.ID, nil
+}
+
+// 도서의 분관별 복본 수와 대여 가능 수 (제적하지 않은 복본이 있는 분관만)
+func fetchBranchHoldings(bookID string) ([]BranchHolding, error) {
+	rows, err := db.Query(`SELECT br.id, br.code, br.name, COUNT(bc.id),
+	                              COALESCE(SUM(CASE WHEN bc.status = 'available' THEN 1 ELSE 0 END), 0)
+	                       FROM book_copies bc
+	                       JOIN branches br ON br.id = bc.branch_id
+	                       WHERE bc.book_id = ? AND bc.status <> 'withdrawn'
+	                       GROUP BY br.id, br.code, br.name, br.sort_order
+	                       ORDER BY br.sort_order, br.id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holdings := []BranchHolding{}
+	for rows.Next() {
+		var h BranchHolding
+		if err := rows.Scan(&h.BranchID, &h.Code, &h.Name, &h.TotalCopies, &h.AvailableCopies); err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, rows.Err()
+}
+
+// 분관/층/서가 트리 조회 (all=true이면 운영하지 않는 곳도 포함)
+func handleGetBranches(c *gin.Context) {
+	roots, _, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 조회에 실패했습니다"})
+		return
+	}
+	if c.Query("all") != "true" {
+		roots = activeBranches(roots)
+	}
+	c.JSON(http.StatusOK, gin.H{"branches": roots})
+}
+
+func activeBranches(nodes []*Branch) []*Branch {
+	active := []*Branch{}
+	for _, node := range nodes {
+		if !node.Active {
+			continue
+		}
+		copied := *node
+		copied.Children = nil
+		if len(node.Children) > 0 {
+			copied.Children = activeBranches(node.Children)
+		}
+		active = append(active, &copied)
+	}
+	return active
+}
+
+func validateBranchInput(input *BranchInput) map[string]string {
+	input.Code = strings.ToUpper(strings.TrimSpace(input.Code))
+	input.Name = strings.TrimSpace(input.Name)
+
+	errs := map[string]string{}
+	if input.Code == "" {
+		errs["code"] = "코드는 필수입니다"
+	} else if len(input.Code) > 20 || strings.Trim(input.Code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") != "" {
+		errs["code"] = "코드는 영문 대문자, 숫자, -, _로 20자 이하여야 합니다"
+	}
+	if input.Name == "" {
+		errs["name"] = "이름은 필수입니다"
+	} else if utf8.RuneCountInString(input.Name) > 100 {
+		errs["name"] = "이름은 100자 이하여야 합니다"
+	}
+	return errs
+}
+
+// 관리자: 분관/층/서가 등록 (단계는 상위 항목에서 정해진다)
+func handleCreateBranch(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var input BranchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateBranchInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	parentLevel := ""
+	if input.ParentID != nil {
+		err := db.QueryRow("SELECT level FROM branches WHERE id = ?", *input.ParentID).Scan(&parentLevel)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "상위 위치를 찾을 수 없습니다"})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 등록에 실패했습니다"})
+			return
+		}
+	}
+	level, ok := branchChildLevel[parentLevel]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "서가 아래에는 위치를 만들 수 없습니다"})
+		return
+	}
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM branches WHERE code = ?", input.Code).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 등록에 실패했습니다"})
+		return
+	}
+	if exists > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 코드입니다"})
+		return
+	}
+
+	active := input.Active == nil || *input.Active
+	result, err := db.Exec(`INSERT INTO branches (parent_id, code, name, level, sort_order, active)
+	                        VALUES (?, ?, ?, ?, ?, ?)`,
+		input.ParentID, input.Code, input.Name, level, input.SortOrder, active)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 등록에 실패했습니다"})
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	log.Printf("Admin %v created %s %s (%s)", adminID, level, input.Code, input.Name)
+	c.JSON(http.StatusCreated, Branch{
+		ID: int(id), ParentID: input.ParentID, Code: input.Code, Name: input.Name,
+		Level: level, SortOrder: input.SortOrder, Active: active,
+	})
+}
+
+// 관리자: 코드, 이름, 정렬 순서, 운영 여부 수정 (상위 위치는 바꿀 수 없다)
+func handleUpdateBranch(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분관을 찾을 수 없습니다"})
+		return
+	}
+
+	var input BranchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateBranchInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	var current Branch
+	var parent sql.NullInt64
+	err = db.QueryRow("SELECT id, parent_id, level, active FROM branches WHERE id = ?", id).
+		Scan(&current.ID, &parent, &current.Level, &current.Active)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분관을 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 수정에 실패했습니다"})
+		return
+	}
+
+	var used int
+	if err := db.QueryRow("SELECT COUNT(*) FROM branches WHERE code = ? AND id <> ?", input.Code, id).Scan(&used); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 수정에 실패했습니다"})
+		return
+	}
+	if used > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 코드입니다"})
+		return
+	}
+
+	active := current.Active
+	if input.Active != nil {
+		active = *input.Active
+	}
+	_, err = db.Exec("UPDATE branches SET code = ?, name = ?, sort_order = ?, active = ? WHERE id = ?",
+		input.Code, input.Name, input.SortOrder, active, id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 수정에 실패했습니다"})
+		return
+	}
+
+	if parent.Valid {
+		parentID := int(parent.Int64)
+		current.ParentID = &parentID
+	}
+	current.Code, current.Name, current.SortOrder, current.Active = input.Code, input.Name, input.SortOrder, active
+	log.Printf("Admin %v updated %s %d (%s)", adminID, current.Level, id, input.Code)
+	c.JSON(http.StatusOK, current)
+}
+
+// 관리자: 분관/층/서가 삭제 (하위 위치나 배정된 복본이 있으면 거부)
+func handleDeleteBranch(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	id := c.Param("id")
+
+	var children, copies, transfers int
+	err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM branches WHERE parent_id = ?),
+	                           (SELECT COUNT(*) FROM book_copies WHERE branch_id = ? OR location_id = ?),
+	                           (SELECT COUNT(*) FROM copy_transfers WHERE from_branch_id = ? OR to_branch_id = ?)`,
+		id, id, id, id, id).Scan(&children, &copies, &transfers)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 삭제에 실패했습니다"})
+		return
+	}
+	if children > 0 || copies > 0 || transfers > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "하위 위치나 복본, 이관 기록이 있는 곳은 삭제할 수 없습니다. 운영 중지(active=false)로 바꿔 주세요",
+			"children":  children,
+			"copies":    copies,
+			"transfers": transfers,
+		})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM branches WHERE id = ?", id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 삭제에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분관을 찾을 수 없습니다"})
+		return
+	}
+
+	log.Printf("Admin %v deleted branch %s", adminID, id)
+	c.JSON(http.StatusOK, gin.H{"message": "삭제되었습니다"})
+}
+
+func nullIfZero(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 내보내기 중 응답을 내려보내는 간격 (도서 수)
+const exportFlushEvery = 100
+
+// 내보내기용 도서 (NDJSON 한 줄)
+type exportBook struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Author      string       `json:"author"`
+	Publisher   string       `json:"publisher"`
+	Year        int          `json:"year"`
+	ISBN        string       `json:"isbn"`
+	Description string       `json:"description"`
+	Price       float64      `json:"price"`
+	CoverImage  string       `json:"cover_image"`
+	Copies      []exportCopy `json:"copies"`
+}
+
+type exportCopy struct {
+	CopyNumber   int    `json:"copy_number"`
+	Status       string `json:"status"`
+	Location     string `json:"location"`
+	AcquiredDate string `json:"acquired_date"`
+	Notes        string `json:"notes"`
+}
+
+// CSV 열. 복본 정보는 가져오기의 copies/location 열과 이름을 달리해
+// 내보낸 파일을 다시 가져와도 복본이 추가로 만들어지지 않게 한다.
+var exportCSVHeader = []string{"id", "title", "author", "publisher", "year", "isbn", "description", "price", "cover_image",
+	"copy_count", "available_copies", "copy_locations"}
+
+// 관리자: 전체 서지와 복본 내보내기 (format=csv|ndjson)
+// 도서와 복본을 한 번의 조인 쿼리로 읽으며 도서 단위로 바로 내려보낸다.
+func handleExportBooks(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 형식입니다: " + format})
+		return
+	}
+
+	rows, err := db.Query(`SELECT b.id, b.title, b.author, COALESCE(b.publisher, ''), COALESCE(b.year, 0), COALESCE(b.isbn, ''),
+	                              COALESCE(b.description, ''), COALESCE(b.price, 0), COALESCE(b.cover_image, ''),
+	                              bc.copy_number, COALESCE(bc.status, ''), COALESCE(bc.location, ''),
+	                              COALESCE(DATE_FORMAT(bc.acquired_date, '%Y-%m-%d'), ''), COALESCE(bc.notes, '')
+	                       FROM books b
+	                       LEFT JOIN book_copies bc ON bc.book_id = b.id
+	                       ORDER BY b.id, bc.copy_number`)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "내보내기에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	filename := "catalog-" + time.Now().Format("20060102")
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+	} else {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.ndjson"`)
+	}
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+	encoder.SetEscapeHTML(false)
+	if format == "csv" {
+		c.Writer.WriteString("\xef\xbb\xbf")
+		csvWriter.Write(exportCSVHeader)
+	}
+
+	written := 0
+	write := func(book *exportBook) {
+		if format == "csv" {
+			csvWriter.Write(exportCSVRecord(book))
+		} else if err := encoder.Encode(book); err != nil {
+			log.Printf("Export error: %v", err)
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			csvWriter.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	var current *exportBook
+	for rows.Next() {
+		var book exportBook
+		var copyNumber *int
+		var copy exportCopy
+		err := rows.Scan(&book.ID, &book.Title, &book.Author, &book.Publisher, &book.Year, &book.ISBN,
+			&book.Description, &book.Price, &book.CoverImage,
+			&copyNumber, &copy.Status, &copy.Location, &copy.AcquiredDate, &copy.Notes)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		if current == nil || current.ID != book.ID {
+			if current != nil {
+				write(current)
+			}
+			book.Copies = []exportCopy{}
+			current = &book
+		}
+		if copyNumber != nil {
+			copy.CopyNumber = *copyNumber
+			current.Copies = append(current.Copies, copy)
+		}
+	}
+	if current != nil {
+		write(current)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Export error: %v", err)
+	}
+	csvWriter.Flush()
+
+	log.Printf("Admin %v exported %d books (%s)", adminID, written, format)
+}
+
+func exportCSVRecord(book *exportBook) []string {
+	available := 0
+	locations := []string{}
+	for _, copy := range book.Copies {
+		if copy.Status == "available" {
+			available++
+		}
+		if copy.Location != "" {
+			locations = append(locations, copy.Location)
+		}
+	}
+	return []string{
+		book.ID, book.Title, book.Author, book.Publisher, strconv.Itoa(book.Year), book.ISBN, book.Description,
+		strconv.FormatFloat(book.Price, 'f', -1, 64), book.CoverImage,
+		strconv.Itoa(len(book.Copies)), strconv.Itoa(available), strings.Join(uniqueStrings(locations), "; "),
+	}
+}
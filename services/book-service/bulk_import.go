@@ -0,0 +1,714 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// 가져오기 대상 필드별로 자동 인식하는 열 이름 (소문자로 비교)
+var importFieldAliases = map[string][]string{
+	"title":       {"title", "제목", "서명"},
+	"author":      {"author", "저자", "지은이"},
+	"publisher":   {"publisher", "출판사", "발행처"},
+	"year":        {"year", "발행년", "출판연도", "출판년도"},
+	"isbn":        {"isbn", "isbn13", "isbn10"},
+	"description": {"description", "설명", "소개"},
+	"price":       {"price", "가격", "정가"},
+	"cover_image": {"cover_image", "표지", "표지 이미지"},
+	"copies":      {"copies", "복본수", "복본 수"},
+	"location":    {"location", "소장위치", "위치"},
+}
+
+const (
+	// 행마다 만들 수 있는 최대 복본 수
+	maxImportCopiesPerRow = 50
+	// 진행 상황과 오류를 기록하는 간격 (행)
+	importProgressEvery = 50
+)
+
+var errImportEncoding = errors.New("UTF-8로 저장된 파일이어야 합니다")
+
+// 일괄 가져오기 작업
+type ImportJob struct {
+	ID            string     `json:"id"`
+	Format        string     `json:"format"`
+	Status        string     `json:"status"`
+	DryRun        bool       `json:"dry_run"`
+	OnDuplicate   string     `json:"on_duplicate"`
+	CopiesPerRow  int        `json:"copies_per_row"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	Created       int        `json:"created"`
+	Updated       int        `json:"updated"`
+	Skipped       int        `json:"skipped"`
+	Failed        int        `json:"failed"`
+	CopiesCreated int        `json:"copies_created"`
+	Progress      float64    `json:"progress"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// 행별 오류 (Row는 파일의 줄 번호)
+type importError struct {
+	Row     int
+	Field   string
+	Value   string
+	Message string
+}
+
+// 파일에서 읽은 한 행 (열 이름은 소문자, 앞뒤 공백 제거)
+type importRow struct {
+	Line   int
+	Values map[string]string
+	Err    string
+}
+
+type importOptions struct {
+	DryRun       bool
+	OnDuplicate  string
+	CopiesPerRow int
+	Location     string
+	// 필드 -> 원본 열 이름 (지정하지 않은 필드는 importFieldAliases로 찾는다)
+	Mapping map[string]string
+}
+
+// 행에서 필드 값을 꺼낸다. 명시한 매핑이 우선이고, 없으면 기본 열 이름을 차례로 찾는다.
+func (o importOptions) field(row importRow, field string) string {
+	if source, ok := o.Mapping[field]; ok {
+		return row.Values[source]
+	}
+	for _, alias := range importFieldAliases[field] {
+		if value, ok := row.Values[alias]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func (o importOptions) hasColumn(columns map[string]bool, field string) bool {
+	if source, ok := o.Mapping[field]; ok {
+		return columns[source]
+	}
+	for _, alias := range importFieldAliases[field] {
+		if columns[alias] {
+			return true
+		}
+	}
+	return false
+}
+
+// 관리자: CSV 또는 NDJSON 일괄 가져오기 작업 시작
+// 파일은 multipart file 필드 또는 요청 본문으로 받고, 옵션은 쿼리나 폼 필드로 받는다.
+//   - format: csv | ndjson (생략하면 내용으로 판단)
+//   - mapping: {"title": "서명", ...} 형태의 JSON (필드 -> 원본 열 이름)
+//   - on_duplicate: ISBN이 같은 도서가 있을 때 update(기본) 또는 skip
+//   - copies, location: 행마다 만들 복본 수와 소장 위치 (copies/location 열이 있으면 열 값 우선)
+//   - dry_run: true이면 검증과 ISBN 대조만 하고 저장하지 않는다
+func handleStartImport(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	data, err := readUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errImportEncoding.Error()})
+		return
+	}
+
+	opts, errs := parseImportOptions(c)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	format := importOption(c, "format")
+	if format == "" {
+		format = "csv"
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = "ndjson"
+		}
+	}
+
+	var rows []importRow
+	switch format {
+	case "csv":
+		var columns map[string]bool
+		rows, columns, err = parseImportCSV(data)
+		if err == nil {
+			for _, field := range []string{"title", "author"} {
+				if !opts.hasColumn(columns, field) {
+					err = fmt.Errorf("%s 열을 찾을 수 없습니다 (mapping으로 지정하세요)", field)
+					break
+				}
+			}
+		}
+	case "ndjson":
+		rows, err = parseImportNDJSON(data)
+	default:
+		err = fmt.Errorf("지원하지 않는 형식입니다: %s", format)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "가져올 행이 없습니다"})
+		return
+	}
+
+	job := ImportJob{
+		ID:           uuid.New().String(),
+		Format:       format,
+		Status:       "queued",
+		DryRun:       opts.DryRun,
+		OnDuplicate:  opts.OnDuplicate,
+		CopiesPerRow: opts.CopiesPerRow,
+		TotalRows:    len(rows),
+		CreatedBy:    fmt.Sprint(adminID),
+		CreatedAt:    time.Now(),
+	}
+	_, err = db.Exec(`INSERT INTO import_jobs (id, format, status, dry_run, on_duplicate, copies_per_row, total_rows, created_by)
+	                  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.Format, job.Status, job.DryRun, job.OnDuplicate, job.CopiesPerRow, job.TotalRows, job.CreatedBy)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "가져오기 작업을 만들지 못했습니다"})
+		return
+	}
+
+	go runImportJob(job.ID, rows, opts)
+
+	log.Printf("Admin %v started import job %s (%s, %d rows)", adminID, job.ID, format, len(rows))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// 쿼리 파라미터가 없으면 multipart 폼 필드에서 찾는다.
+func importOption(c *gin.Context, name string) string {
+	if value := c.Query(name); value != "" {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(c.PostForm(name))
+}
+
+func parseImportOptions(c *gin.Context) (importOptions, map[string]string) {
+	opts := importOptions{
+		DryRun:      importOption(c, "dry_run") == "true",
+		OnDuplicate: importOption(c, "on_duplicate"),
+		Location:    importOption(c, "location"),
+		Mapping:     map[string]string{},
+	}
+	errs := map[string]string{}
+
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = "update"
+	} else if opts.OnDuplicate != "update" && opts.OnDuplicate != "skip" {
+		errs["on_duplicate"] = "update 또는 skip이어야 합니다"
+	}
+	if raw := importOption(c, "copies"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxImportCopiesPerRow {
+			errs["copies"] = fmt.Sprintf("0에서 %d 사이의 숫자여야 합니다", maxImportCopiesPerRow)
+		}
+		opts.CopiesPerRow = n
+	}
+	if utf8.RuneCountInString(opts.Location) > 100 {
+		errs["location"] = "소장 위치는 100자 이하여야 합니다"
+	}
+	if raw := importOption(c, "mapping"); raw != "" {
+		var mapping map[string]string
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			errs["mapping"] = "필드와 열 이름의 JSON 객체여야 합니다"
+		}
+		for field, source := range mapping {
+			if _, ok := importFieldAliases[field]; !ok {
+				errs["mapping"] = "알 수 없는 필드입니다: " + field
+				continue
+			}
+			opts.Mapping[field] = strings.ToLower(strings.TrimSpace(source))
+		}
+	}
+	return opts, errs
+}
+
+// 첫 행을 열 이름으로 사용한다. 열 수가 맞지 않는 행도 있는 열까지 읽는다.
+func parseImportCSV(data []byte) ([]importRow, map[string]bool, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSV 머리글을 읽을 수 없습니다: %v", err)
+	}
+	columns := map[string]bool{}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		columns[header[i]] = true
+	}
+
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, importRow{Line: parseErr.StartLine, Err: "CSV 형식 오류: " + parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		values := map[string]string{}
+		empty := true
+		for i, value := range record {
+			if i < len(header) {
+				values[header[i]] = strings.TrimSpace(value)
+				empty = empty && values[header[i]] == ""
+			}
+		}
+		if !empty {
+			rows = append(rows, importRow{Line: line, Values: values})
+		}
+	}
+	return rows, columns, nil
+}
+
+// 한 줄에 JSON 객체 하나. 숫자 등은 문자열로 바꿔 CSV와 같은 경로로 검증한다.
+func parseImportNDJSON(data []byte) ([]importRow, error) {
+	rows := []importRow{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			rows = append(rows, importRow{Line: i + 1, Err: "JSON 형식 오류: " + err.Error()})
+			continue
+		}
+		values := map[string]string{}
+		for key, value := range object {
+			if value == nil {
+				continue
+			}
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(fmt.Sprint(value))
+		}
+		rows = append(rows, importRow{Line: i + 1, Values: values})
+	}
+	return rows, nil
+}
+
+// 행 처리 결과: created, updated, skipped, failed
+type importOutcome struct {
+	Result string
+	Copies int
+	BookID string
+	Errors []importError
+}
+
+func runImportJob(jobID string, rows []importRow, opts importOptions) {
+	if _, err := db.Exec("UPDATE import_jobs SET status = 'running', started_at = CURRENT_TIMESTAMP WHERE id = ?", jobID); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+
+	counts := map[string]int{}
+	copies := 0
+	pending := []importError{}
+	flush := func(processed int) error {
+		if err := insertImportErrors(jobID, pending); err != nil {
+			return err
+		}
+		pending = pending[:0]
+		_, err := db.Exec(`UPDATE import_jobs
+		                   SET processed_rows = ?, created_count = ?, updated_count = ?, skipped_count = ?, failed_count = ?, copies_created = ?
+		                   WHERE id = ?`,
+			processed, counts["created"], counts["updated"], counts["skipped"], counts["failed"], copies, jobID)
+		return err
+	}
+
+	for i, row := range rows {
+		outcome := processImportRow(row, opts)
+		counts[outcome.Result]++
+		copies += outcome.Copies
+		pending = append(pending, outcome.Errors...)
+		if outcome.BookID != "" {
+			reindexBooks([]string{outcome.BookID})
+		}
+
+		if (i+1)%importProgressEvery == 0 || i == len(rows)-1 {
+			if err := flush(i + 1); err != nil {
+				log.Printf("Database error: %v", err)
+				finishImportJob(jobID, "failed", "진행 상황을 기록하지 못했습니다")
+				return
+			}
+		}
+	}
+
+	finishImportJob(jobID, "completed", "")
+	log.Printf("Import job %s completed: %v, %d copies", jobID, counts, copies)
+}
+
+func finishImportJob(jobID, status, message string) {
+	_, err := db.Exec("UPDATE import_jobs SET status = ?, error_message = ?, finished_at = CURRENT_TIMESTAMP WHERE id = ?",
+		status, nullIfEmpty(message), jobID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+}
+
+func insertImportErrors(jobID string, errs []importError) error {
+	for _, e := range errs {
+		_, err := db.Exec("INSERT INTO import_job_errors (job_id, row_no, field, value, message) VALUES (?, ?, ?, ?, ?)",
+			jobID, e.Row, e.Field, truncateRunes(e.Value, 500), truncateRunes(e.Message, 255))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 한 행을 검증하고 ISBN으로 기존 도서를 찾아 등록 또는 갱신한다 (행마다 별도 트랜잭션).
+func processImportRow(row importRow, opts importOptions) importOutcome {
+	failed := func(field, value, message string) importOutcome {
+		return importOutcome{Result: "failed", Errors: []importError{{Row: row.Line, Field: field, Value: value, Message: message}}}
+	}
+	if row.Err != "" {
+		return failed("", "", row.Err)
+	}
+
+	input := BookInput{
+		Title:       opts.field(row, "title"),
+		Author:      opts.field(row, "author"),
+		Publisher:   opts.field(row, "publisher"),
+		ISBN:        opts.field(row, "isbn"),
+		Description: opts.field(row, "description"),
+		CoverImage:  opts.field(row, "cover_image"),
+	}
+	errs := []importError{}
+	if raw := opts.field(row, "year"); raw != "" {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			errs = append(errs, importError{Row: row.Line, Field: "year", Value: raw, Message: "숫자여야 합니다"})
+		}
+		input.Year = year
+	}
+	if raw := opts.field(row, "price"); raw != "" {
+		price, err := strconv.ParseFloat(strings.NewReplacer(",", "", "₩", "", "원", "").Replace(raw), 64)
+		if err != nil {
+			errs = append(errs, importError{Row: row.Line, Field: "price", Value: raw, Message: "숫자여야 합니다"})
+		}
+		input.Price = price
+	}
+	copyCount := opts.CopiesPerRow
+	if raw := opts.field(row, "copies"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxImportCopiesPerRow {
+			errs = append(errs, importError{Row: row.Line, Field: "copies", Value: raw,
+				Message: fmt.Sprintf("0에서 %d 사이의 숫자여야 합니다", maxImportCopiesPerRow)})
+		}
+		copyCount = n
+	}
+	location := opts.Location
+	if value := opts.field(row, "location"); value != "" {
+		location = truncateRunes(value, 100)
+	}
+
+	original := input
+	for field, message := range validateBookInput(&input, "") {
+		errs = append(errs, importError{Row: row.Line, Field: field, Value: importFieldValue(original, field), Message: message})
+	}
+	if len(errs) > 0 {
+		return importOutcome{Result: "failed", Errors: errs}
+	}
+
+	existingID := ""
+	if input.ISBN != "" {
+		ids, err := booksWithISBN(input.ISBN)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			return failed("", "", "도서 조회 중 오류가 발생했습니다")
+		}
+		if len(ids) > 1 {
+			return failed("isbn", input.ISBN, "같은 ISBN을 가진 도서가 여러 권입니다: "+strings.Join(ids, ", "))
+		}
+		if len(ids) == 1 {
+			existingID = ids[0]
+			if opts.OnDuplicate == "skip" {
+				return importOutcome{Result: "skipped"}
+			}
+		}
+	}
+
+	result := "created"
+	if existingID != "" {
+		result = "updated"
+	}
+	if opts.DryRun {
+		return importOutcome{Result: result, Copies: copyCount}
+	}
+
+	bookID, err := saveImportedBook(existingID, input, copyCount, location)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return failed("", "", "저장 중 오류가 발생했습니다")
+	}
+	return importOutcome{Result: result, Copies: copyCount, BookID: bookID}
+}
+
+func importFieldValue(input BookInput, field string) string {
+	switch field {
+	case "title":
+		return input.Title
+	case "author":
+		return input.Author
+	case "publisher":
+		return input.Publisher
+	case "year":
+		return strconv.Itoa(input.Year)
+	case "isbn":
+		return input.ISBN
+	case "price":
+		return strconv.FormatFloat(input.Price, 'f', -1, 64)
+	case "cover_image":
+		return input.CoverImage
+	}
+	return ""
+}
+
+// 정규화한 ISBN과 일치하는 도서 ID 목록
+func booksWithISBN(isbn13 string) ([]string, error) {
+	clause, args := isbnMatchClause(isbn13)
+	rows, err := db.Query("SELECT id FROM books WHERE "+clause+" ORDER BY created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// existingID가 비어 있으면 새로 등록한다. 저자가 바뀌면 대표 저자 전거를 다시 연결한다.
+// 기존 도서를 갱신할 때 값이 없는 필드는 덮어쓰지 않는다.
+func saveImportedBook(existingID string, input BookInput, copyCount int, location string) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	bookID := existingID
+	if bookID == "" {
+		bookID = uuid.New().String()
+		_, err = tx.Exec(`INSERT INTO books (id, title, author, publisher, year, isbn, description, price, cover_image)
+		                  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			bookID, input.Title, input.Author, input.Publisher, nullIfZero(input.Year),
+			nullIfEmpty(input.ISBN), input.Description, input.Price, input.CoverImage)
+		if err != nil {
+			return "", err
+		}
+		if err := linkDefaultAuthor(tx, bookID, input.Author); err != nil {
+			return "", err
+		}
+	} else {
+		var currentAuthor string
+		if err := tx.QueryRow("SELECT author FROM books WHERE id = ? FOR UPDATE", bookID).Scan(&currentAuthor); err != nil {
+			return "", err
+		}
+		// 파일에 없는 열이나 빈 칸은 기존 값을 유지한다 (제목, 저자, ISBN은 항상 값이 있다)
+		_, err = tx.Exec(`UPDATE books
+		                  SET title = ?, author = ?,
+		                      publisher = IF(? = '', publisher, ?),
+		                      year = IF(? = 0, year, ?),
+		                      isbn = ?,
+		                      description = IF(? = '', description, ?),
+		                      price = IF(? = 0, price, ?),
+		                      cover_image = IF(? = '', cover_image, ?)
+		                  WHERE id = ?`,
+			input.Title, input.Author, input.Publisher, input.Publisher, input.Year, nullIfZero(input.Year),
+			nullIfEmpty(input.ISBN), input.Description, input.Description, input.Price, input.Price,
+			input.CoverImage, input.CoverImage, bookID)
+		if err != nil {
+			return "", err
+		}
+		if currentAuthor != input.Author {
+			if _, err := tx.Exec("DELETE FROM book_contributors WHERE book_id = ? AND role = 'author'", bookID); err != nil {
+				return "", err
+			}
+			if err := linkDefaultAuthor(tx, bookID, input.Author); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if copyCount > 0 {
+		if err := addImportedCopies(tx, bookID, copyCount, location); err != nil {
+			return "", err
+		}
+	}
+	return bookID, tx.Commit()
+}
+
+// 기존 복본 번호 다음부터 이용 가능 상태로 만든다 (기본 분관 소속).
+func addImportedCopies(tx *sql.Tx, bookID string, count int, location string) error {
+	var last int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(copy_number), 0) FROM book_copies WHERE book_id = ? FOR UPDATE", bookID).Scan(&last); err != nil {
+		return err
+	}
+	branchID, err := defaultBranchID(tx)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= count; i++ {
+		result, err := tx.Exec(`INSERT INTO book_copies (book_id, copy_number, status, branch_id, location, acquired_date, notes)
+		                        VALUES (?, ?, 'available', ?, ?, CURDATE(), '일괄 가져오기')`,
+			bookID, last+i, nullIfZero(branchID), location)
+		if err != nil {
+			return err
+		}
+		id, _ := result.LastInsertId()
+		if _, err := assignCopyBarcode(tx, id); err != nil {
+			return err
+		}
+		if err := recordCopyStatus(tx, id, "", "available", "acquired", "일괄 가져오기", "import"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const importJobColumns = `id, format, status, dry_run, on_duplicate, copies_per_row, total_rows, processed_rows,
+       created_count, updated_count, skipped_count, failed_count, copies_created,
+       COALESCE(error_message, ''), created_by, created_at, started_at, finished_at`
+
+func scanImportJob(scanner interface{ Scan(...interface{}) error }) (ImportJob, error) {
+	var job ImportJob
+	var startedAt, finishedAt sql.NullTime
+	err := scanner.Scan(&job.ID, &job.Format, &job.Status, &job.DryRun, &job.OnDuplicate, &job.CopiesPerRow,
+		&job.TotalRows, &job.ProcessedRows, &job.Created, &job.Updated, &job.Skipped, &job.Failed, &job.CopiesCreated,
+		&job.Error, &job.CreatedBy, &job.CreatedAt, &startedAt, &finishedAt)
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if job.TotalRows > 0 {
+		job.Progress = float64(job.ProcessedRows) / float64(job.TotalRows)
+	}
+	return job, err
+}
+
+// 관리자: 최근 가져오기 작업 목록
+func handleGetImportJobs(c *gin.Context) {
+	rows, err := db.Query("SELECT " + importJobColumns + " FROM import_jobs ORDER BY created_at DESC LIMIT 20")
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "가져오기 작업을 조회하지 못했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	jobs := []ImportJob{}
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// 관리자: 가져오기 작업 진행 상황
+func handleGetImportJob(c *gin.Context) {
+	job, err := scanImportJob(db.QueryRow("SELECT "+importJobColumns+" FROM import_jobs WHERE id = ?", c.Param("job_id")))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "가져오기 작업을 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "가져오기 작업을 조회하지 못했습니다"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// 관리자: 가져오기 오류 보고서 (CSV 다운로드)
+func handleGetImportErrors(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM import_jobs WHERE id = ?", jobID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "오류 보고서를 만들지 못했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "가져오기 작업을 찾을 수 없습니다"})
+		return
+	}
+
+	rows, err := db.Query("SELECT row_no, field, value, message FROM import_job_errors WHERE job_id = ? ORDER BY row_no, id", jobID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "오류 보고서를 만들지 못했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="import-`+jobID+`-errors.csv"`)
+	c.Status(http.StatusOK)
+	// 엑셀에서 한글이 깨지지 않도록 BOM을 붙인다.
+	c.Writer.WriteString("\xef\xbb\xbf")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"row", "field", "value", "message"})
+	for rows.Next() {
+		var row int
+		var field, value, message string
+		if err := rows.Scan(&row, &field, &value, &message); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		writer.Write([]string{strconv.Itoa(row), field, value, message})
+	}
+	writer.Flush()
+}
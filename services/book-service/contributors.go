@@ -0,0 +1,604 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 기여자 역할과 표시 이름
+var contributorRoles = map[string]string{
+	"author":      "지은이",
+	"translator":  "옮긴이",
+	"editor":      "엮은이",
+	"illustrator": "그린이",
+}
+
+const (
+	defaultAuthorSearchLimit = 20
+	maxAuthorSearchLimit     = 100
+)
+
+// 저자 전거 (같은 사람의 여러 표기는 VariantNames에 쉼표로 구분)
+type Author struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	VariantNames string `json:"variant_names,omitempty"`
+	BirthYear    *int   `json:"birth_year"`
+	DeathYear    *int   `json:"death_year"`
+	Note         string `json:"note,omitempty"`
+	WorkCount    int    `json:"work_count"`
+}
+
+// 도서의 기여자 (Position 순으로 표시)
+type Contributor struct {
+	AuthorID  int    `json:"author_id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	RoleLabel string `json:"role_label"`
+	Position  int    `json:"position"`
+}
+
+// 기여자 지정 요청: AuthorID가 없으면 Name으로 전거를 찾거나 새로 만든다.
+type ContributorInput struct {
+	AuthorID int    `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+// 저자 전거 등록/수정 요청
+type AuthorInput struct {
+	Name         string `json:"name"`
+	VariantNames string `json:"variant_names"`
+	BirthYear    *int   `json:"birth_year"`
+	DeathYear    *int   `json:"death_year"`
+	Note         string `json:"note"`
+}
+
+// 저자의 작품 (이 저자가 맡은 역할 포함)
+type AuthorWork struct {
+	Book
+	Roles []string `json:"roles"`
+}
+
+// 도서의 기여자 목록
+func fetchBookContributors(bookID string) ([]Contributor, error) {
+	query := `SELECT a.id, a.name, bc.role, bc.position
+	          FROM book_contributors bc
+	          JOIN authors a ON a.id = bc.author_id
+	          WHERE bc.book_id = ?
+	          ORDER BY bc.position, a.name`
+	rows, err := db.Query(query, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contributors := []Contributor{}
+	for rows.Next() {
+		var ct Contributor
+		if err := rows.Scan(&ct.AuthorID, &ct.Name, &ct.Role, &ct.Position); err != nil {
+			return nil, err
+		}
+		ct.RoleLabel = contributorRoles[ct.Role]
+		contributors = append(contributors, ct)
+	}
+	return contributors, rows.Err()
+}
+
+// 이름이 같은 전거가 있으면 그 ID를, 없으면 새 전거를 만든다.
+func findOrCreateAuthor(tx *sql.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow("SELECT id FROM authors WHERE name = ? ORDER BY id LIMIT 1", name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+	result, err := tx.Exec("INSERT INTO authors (name) VALUES (?)", name)
+	if err != nil {
+		return 0, err
+	}
+	newID, err := result.LastInsertId()
+	return int(newID), err
+}
+
+// 새로 등록한 도서의 author 문자열을 첫 번째 저자로 연결한다.
+func linkDefaultAuthor(tx *sql.Tx, bookID, name string) error {
+	if name == "" {
+		return nil
+	}
+	authorID, err := findOrCreateAuthor(tx, name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO book_contributors (book_id, author_id, role, position) VALUES (?, ?, 'author', 1)",
+		bookID, authorID)
+	return err
+}
+
+// 도서의 기여자를 이름 목록으로 바꾸고 books.author를 다시 채운다 (전거는 이름으로 찾거나 만든다).
+func replaceContributors(tx *sql.Tx, bookID string, inputs []ContributorInput) error {
+	if _, err := tx.Exec("DELETE FROM book_contributors WHERE book_id = ?", bookID); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i, in := range inputs {
+		name := strings.TrimSpace(in.Name)
+		if utf8.RuneCountInString(name) > 100 {
+			name = string([]rune(name)[:100])
+		}
+		if _, ok := contributorRoles[in.Role]; !ok || name == "" {
+			continue
+		}
+		authorID, err := findOrCreateAuthor(tx, name)
+		if err != nil {
+			return err
+		}
+		key := strconv.Itoa(authorID) + "/" + in.Role
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, err := tx.Exec("INSERT INTO book_contributors (book_id, author_id, role, position) VALUES (?, ?, ?, ?)",
+			bookID, authorID, in.Role, i+1); err != nil {
+			return err
+		}
+	}
+	return syncBookAuthor(tx, bookID)
+}
+
+// books.author를 role이 author인 기여자 이름으로 다시 채운다 (저자가 없으면 그대로 둔다).
+// 다른 인스턴스의 검색 색인이 변경을 감지하도록 updated_at도 갱신한다.
+func syncBookAuthor(tx *sql.Tx, bookID string) error {
+	rows, err := tx.Query(`SELECT a.name FROM book_contributors bc
+	                       JOIN authors a ON a.id = bc.author_id
+	                       WHERE bc.book_id = ? AND bc.role = 'author'
+	                       ORDER BY bc.position, a.name`, bookID)
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		_, err = tx.Exec("UPDATE books SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", bookID)
+		return err
+	}
+	author := strings.Join(names, ", ")
+	if utf8.RuneCountInString(author) > 100 {
+		author = string([]rune(author)[:99]) + "…"
+	}
+	_, err = tx.Exec("UPDATE books SET author = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", author, bookID)
+	return err
+}
+
+// 변경된 도서들을 다시 읽어 검색 색인에 반영한다.
+func reindexBooks(bookIDs []string) {
+	for _, id := range bookIDs {
+		book, err := fetchBook(id)
+		if err != nil {
+			log.Printf("Search index update error: %v", err)
+			continue
+		}
+		indexBook(book)
+	}
+}
+
+// 저자 전거 검색 (이름 또는 다른 표기 일부 일치)
+func handleSearchAuthors(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuthorSearchLimit)))
+	if err != nil || limit < 1 || limit > maxAuthorSearchLimit {
+		limit = defaultAuthorSearchLimit
+	}
+
+	query := `SELECT a.id, a.name, COALESCE(a.variant_names, ''), a.birth_year, a.death_year, COALESCE(a.note, ''),
+	          (SELECT COUNT(DISTINCT bc.book_id) FROM book_contributors bc WHERE bc.author_id = a.id) AS work_count
+	          FROM authors a`
+	args := []interface{}{}
+	if q != "" {
+		query += " WHERE a.name LIKE ? OR a.variant_names LIKE ?"
+		args = append(args, "%"+q+"%", "%"+q+"%")
+	}
+	query += " ORDER BY work_count DESC, a.name, a.id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	authors := []Author{}
+	for rows.Next() {
+		var a Author
+		if err := rows.Scan(&a.ID, &a.Name, &a.VariantNames, &a.BirthYear, &a.DeathYear, &a.Note, &a.WorkCount); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		authors = append(authors, a)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"authors": authors})
+}
+
+// 저자 전거와 작품 목록 (출판 연도 최신순)
+func handleGetAuthor(c *gin.Context) {
+	authorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 저자 ID입니다"})
+		return
+	}
+
+	var a Author
+	err = db.QueryRow(`SELECT id, name, COALESCE(variant_names, ''), birth_year, death_year, COALESCE(note, '')
+	                   FROM authors WHERE id = ?`, authorID).
+		Scan(&a.ID, &a.Name, &a.VariantNames, &a.BirthYear, &a.DeathYear, &a.Note)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "저자를 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 조회에 실패했습니다"})
+		return
+	}
+
+	query := `SELECT b.id, b.title, b.author, b.publisher, COALESCE(b.year, 0) AS year, COALESCE(b.isbn, '') AS isbn, b.description, b.price, b.cover_image,
+	          b.created_at,
+	          (SELECT COUNT(*) FROM book_copies bc WHERE bc.book_id = b.id AND bc.status <> 'withdrawn') AS total_copies,
+	          (SELECT COUNT(*) FROM book_copies bc WHERE bc.book_id = b.id AND bc.status = 'available') AS available_copies,
+	          GROUP_CONCAT(ct.role ORDER BY ct.position SEPARATOR ',') AS roles
+	          FROM book_contributors ct
+	          JOIN books b ON b.id = ct.book_id
+	          WHERE ct.author_id = ?
+	          GROUP BY b.id
+	          ORDER BY b.year DESC, b.title`
+	rows, err := db.Query(query, authorID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	works := []AuthorWork{}
+	for rows.Next() {
+		var w AuthorWork
+		var roles string
+		err := rows.Scan(&w.ID, &w.Title, &w.Author, &w.Publisher, &w.Year, &w.ISBN, &w.Description, &w.Price,
+			&w.CoverImage, &w.CreatedAt, &w.TotalCopies, &w.AvailableCopies, &roles)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		w.Roles = strings.Split(roles, ",")
+		works = append(works, w)
+	}
+	a.WorkCount = len(works)
+
+	c.JSON(http.StatusOK, gin.H{"author": a, "works": works})
+}
+
+func validateAuthorInput(input *AuthorInput) map[string]string {
+	input.Name = strings.TrimSpace(input.Name)
+	input.VariantNames = strings.TrimSpace(input.VariantNames)
+	input.Note = strings.TrimSpace(input.Note)
+
+	errs := map[string]string{}
+	if input.Name == "" {
+		errs["name"] = "이름은 필수입니다"
+	} else if utf8.RuneCountInString(input.Name) > 100 {
+		errs["name"] = "이름은 100자 이하여야 합니다"
+	}
+	if utf8.RuneCountInString(input.VariantNames) > 500 {
+		errs["variant_names"] = "다른 표기는 500자 이하여야 합니다"
+	}
+	if input.BirthYear != nil && input.DeathYear != nil && *input.DeathYear < *input.BirthYear {
+		errs["death_year"] = "몰년은 생년보다 빠를 수 없습니다"
+	}
+	return errs
+}
+
+// 관리자: 저자 전거 등록
+func handleCreateAuthor(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var input AuthorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateAuthorInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	result, err := db.Exec(`INSERT INTO authors (name, variant_names, birth_year, death_year, note) VALUES (?, ?, ?, ?, ?)`,
+		input.Name, nullIfEmpty(input.VariantNames), input.BirthYear, input.DeathYear, nullIfEmpty(input.Note))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 등록에 실패했습니다"})
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	log.Printf("Admin %v created author %d (%s)", adminID, id, input.Name)
+	c.JSON(http.StatusCreated, Author{
+		ID:           int(id),
+		Name:         input.Name,
+		VariantNames: input.VariantNames,
+		BirthYear:    input.BirthYear,
+		DeathYear:    input.DeathYear,
+		Note:         input.Note,
+	})
+}
+
+// 관리자: 저자 전거 수정 (이름이 바뀌면 해당 저자의 도서 author 문자열도 갱신)
+func handleUpdateAuthor(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	authorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 저자 ID입니다"})
+		return
+	}
+
+	var input AuthorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateAuthorInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 수정에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE authors SET name = ?, variant_names = ?, birth_year = ?, death_year = ?, note = ? WHERE id = ?`,
+		input.Name, nullIfEmpty(input.VariantNames), input.BirthYear, input.DeathYear, nullIfEmpty(input.Note), authorID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 수정에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM authors WHERE id = ?", authorID).Scan(&exists); err != nil || exists == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "저자를 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	bookIDs, err := authorBookIDs(tx, authorID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 수정에 실패했습니다"})
+		return
+	}
+	for _, bookID := range bookIDs {
+		if err := syncBookAuthor(tx, bookID); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 수정에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 수정에 실패했습니다"})
+		return
+	}
+	reindexBooks(bookIDs)
+
+	log.Printf("Admin %v updated author %d (%d books)", adminID, authorID, len(bookIDs))
+	c.JSON(http.StatusOK, Author{
+		ID:           authorID,
+		Name:         input.Name,
+		VariantNames: input.VariantNames,
+		BirthYear:    input.BirthYear,
+		DeathYear:    input.DeathYear,
+		Note:         input.Note,
+		WorkCount:    len(bookIDs),
+	})
+}
+
+// 관리자: 저자 전거 삭제 (작품이 연결되어 있으면 거부)
+func handleDeleteAuthor(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	authorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 저자 ID입니다"})
+		return
+	}
+
+	var works int
+	if err := db.QueryRow("SELECT COUNT(*) FROM book_contributors WHERE author_id = ?", authorID).Scan(&works); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 삭제에 실패했습니다"})
+		return
+	}
+	if works > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "작품이 연결된 저자는 삭제할 수 없습니다", "work_count": works})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM authors WHERE id = ?", authorID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "저자 삭제에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "저자를 찾을 수 없습니다"})
+		return
+	}
+
+	log.Printf("Admin %v deleted author %d", adminID, authorID)
+	c.JSON(http.StatusOK, gin.H{"message": "저자가 삭제되었습니다"})
+}
+
+// 관리자: 도서의 기여자를 요청한 순서대로 교체
+func handleSetBookContributors(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	var req struct {
+		Contributors []ContributorInput `json:"contributors"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if len(req.Contributors) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "기여자를 한 명 이상 지정해야 합니다"})
+		return
+	}
+	for i := range req.Contributors {
+		in := &req.Contributors[i]
+		in.Name = strings.TrimSpace(in.Name)
+		if in.Role == "" {
+			in.Role = "author"
+		}
+		if _, ok := contributorRoles[in.Role]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 역할입니다: " + in.Role})
+			return
+		}
+		if in.AuthorID == 0 && in.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "author_id 또는 name이 필요합니다"})
+			return
+		}
+		if utf8.RuneCountInString(in.Name) > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "이름은 100자 이하여야 합니다"})
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM books WHERE id = ? FOR UPDATE", bookID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM book_contributors WHERE book_id = ?", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+		return
+	}
+
+	seen := map[string]bool{}
+	for i, in := range req.Contributors {
+		authorID := in.AuthorID
+		if authorID == 0 {
+			authorID, err = findOrCreateAuthor(tx, in.Name)
+			if err != nil {
+				log.Printf("Database error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+				return
+			}
+		} else {
+			var found int
+			if err := tx.QueryRow("SELECT COUNT(*) FROM authors WHERE id = ?", authorID).Scan(&found); err != nil {
+				log.Printf("Database error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+				return
+			}
+			if found == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 저자입니다: " + strconv.Itoa(authorID)})
+				return
+			}
+		}
+
+		key := strconv.Itoa(authorID) + "/" + in.Role
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, err := tx.Exec("INSERT INTO book_contributors (book_id, author_id, role, position) VALUES (?, ?, ?, ?)",
+			bookID, authorID, in.Role, i+1); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := syncBookAuthor(tx, bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "기여자 변경에 실패했습니다"})
+		return
+	}
+
+	book, err := fetchBook(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 조회에 실패했습니다"})
+		return
+	}
+	indexBook(book)
+
+	log.Printf("Admin %v set %d contributors of book %s", adminID, len(book.Contributors), bookID)
+	c.JSON(http.StatusOK, book)
+}
+
+func authorBookIDs(tx *sql.Tx, authorID int) ([]string, error) {
+	rows, err := tx.Query("SELECT DISTINCT book_id FROM book_contributors WHERE author_id = ?", authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 복본 상태와 표시 이름
+var copyStatuses = map[string]string{
+	"available":   "이용 가능",
+	"borrowed":    "대출 중",
+	"on_hold":     "예약 보관",
+	"in_transit":  "이동 중",
+	"maintenance": "수선 중",
+	"lost":        "분실",
+	"withdrawn":   "제적",
+}
+
+// 상태별로 옮겨 갈 수 있는 상태 (withdrawn은 종료 상태)
+var copyTransitions = map[string][]string{
+	"available":   {"borrowed", "on_hold", "in_transit", "maintenance", "lost", "withdrawn"},
+	"borrowed":    {"available", "in_transit", "lost"},
+	"on_hold":     {"available", "borrowed", "in_transit"},
+	"in_transit":  {"available", "on_hold", "lost"},
+	"maintenance": {"available", "lost", "withdrawn"},
+	"lost":        {"available", "withdrawn"},
+	"withdrawn":   {},
+}
+
+// 새 복본이 가질 수 있는 상태
+var initialCopyStatuses = []string{"available", "in_transit", "maintenance"}
+
+// 상태 변경 사유. To가 비어 있으면 허용된 모든 전이에 쓸 수 있다.
+type copyStatusReason struct {
+	Label string   `json:"label"`
+	To    []string `json:"to,omitempty"`
+}
+
+var copyStatusReasons = map[string]copyStatusReason{
+	"acquired":     {Label: "신규 등록"},
+	"checkout":     {Label: "대출", To: []string{"borrowed"}},
+	"checkin":      {Label: "반납", To: []string{"available", "in_transit"}},
+	"hold_shelved": {Label: "예약 도서 확보", To: []string{"on_hold"}},
+	"hold_expired": {Label: "예약 만료/취소", To: []string{"available"}},
+	"transfer":     {Label: "이관 발송", To: []string{"in_transit"}},
+	"received":     {Label: "이관 도착", To: []string{"available", "on_hold"}},
+	"damaged":      {Label: "파손", To: []string{"maintenance"}},
+	"repaired":     {Label: "수선 완료", To: []string{"available"}},
+	"missing":      {Label: "분실", To: []string{"lost"}},
+	"found":        {Label: "분실 도서 발견", To: []string{"available"}},
+	"weeded":       {Label: "제적", To: []string{"withdrawn"}},
+	"correction":   {Label: "상태 정정"},
+}
+
+// 복본 상태 변경 이력
+type CopyStatusChange struct {
+	ID          int64     `json:"id"`
+	CopyID      int       `json:"copy_id"`
+	FromStatus  *string   `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Reason      string    `json:"reason"`
+	ReasonLabel string    `json:"reason_label"`
+	Note        string    `json:"note,omitempty"`
+	Actor       string    `json:"actor"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+func copyTransitionAllowed(from, to string) bool {
+	for _, next := range copyTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// 관리자가 직접 바꾸는 상태 전이를 검증한다 (오류 메시지를 반환, 문제가 없으면 빈 문자열).
+// 대출과 정상 반납은 borrow-service에서만 처리하며,
+// 대출 중 -> 이용 가능은 대출 기록과 맞지 않는 복본을 정정(correction)할 때만 허용한다.
+// 제적은 사유와 제적일을 함께 남기도록 제적 처리(withdrawal.go)로만 한다.
+func validateCopyTransition(tx *sql.Tx, bookID, copyID, from, to, reason string) (string, error) {
+	if _, ok := copyStatuses[to]; !ok {
+		return "알 수 없는 상태입니다: " + to, nil
+	}
+	if !copyTransitionAllowed(from, to) {
+		return copyStatuses[from] + "에서 " + copyStatuses[to] + "(으)로 바꿀 수 없습니다", nil
+	}
+	r, ok := copyStatusReasons[reason]
+	if !ok {
+		return "알 수 없는 사유입니다: " + reason, nil
+	}
+	if len(r.To) > 0 && !containsString(r.To, to) {
+		return r.Label + " 사유로 " + copyStatuses[to] + " 상태가 될 수 없습니다", nil
+	}
+	if to == "borrowed" {
+		return "대출은 대출 처리에서만 할 수 있습니다", nil
+	}
+	if to == "withdrawn" {
+		return "제적은 제적 처리에서만 할 수 있습니다", nil
+	}
+	if from == "borrowed" && to == "in_transit" {
+		return "대출 중인 복본은 반납 처리로 되돌려야 합니다", nil
+	}
+	if from == "borrowed" && to == "available" {
+		if reason != "correction" {
+			return "대출 중인 복본은 반납 처리로 되돌려야 합니다", nil
+		}
+		// 이 복본에 연결된 진행 중인 대출이 있으면 정정할 수 없다
+		var linked bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM borrows WHERE copy_id = ? AND status IN ('borrowed', 'overdue'))", copyID).Scan(&linked); err != nil {
+			return "", err
+		}
+		if linked {
+			return "진행 중인 대출 기록이 있어 정정할 수 없습니다", nil
+		}
+		// 복본이 연결되지 않은 기존 대출이 남은 대출 중 복본 수만큼 있으면 이 복본의 대출일 수 있다
+		var loans, borrowedCopies int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM borrows WHERE book_id = ? AND copy_id IS NULL AND status IN ('borrowed', 'overdue')", bookID).Scan(&loans); err != nil {
+			return "", err
+		}
+		unlinkedQuery := `SELECT COUNT(*) FROM book_copies bc
+		                  WHERE bc.book_id = ? AND bc.status = 'borrowed'
+		                    AND NOT EXISTS (SELECT 1 FROM borrows b WHERE b.copy_id = bc.id AND b.status IN ('borrowed', 'overdue'))`
+		if err := tx.QueryRow(unlinkedQuery, bookID).Scan(&borrowedCopies); err != nil {
+			return "", err
+		}
+		if loans >= borrowedCopies {
+			return "진행 중인 대출 기록이 있어 정정할 수 없습니다", nil
+		}
+	}
+	return "", nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// 상태 변경 이력 기록 (from이 비어 있으면 신규 등록)
+func recordCopyStatus(exec sqlExecer, copyID int64, from, to, reason, note, actor string) error {
+	_, err := exec.Exec(`INSERT INTO copy_status_history (copy_id, from_status, to_status, reason, note, actor)
+	                     VALUES (?, ?, ?, ?, ?, ?)`,
+		copyID, nullIfEmpty(from), to, reason, nullIfEmpty(note), actor)
+	return err
+}
+
+// 관리자: 상태, 전이, 사유 목록 (복본 관리 화면용)
+func handleGetCopyStatuses(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"statuses":           copyStatuses,
+		"transitions":        copyTransitions,
+		"reasons":            copyStatusReasons,
+		"withdrawal_reasons": withdrawalReasons,
+	})
+}
+
+// 관리자: 복본 상태 변경 이력 (최신순)
+func handleGetCopyHistory(c *gin.Context) {
+	copyID := c.Param("id")
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM book_copies WHERE id = ?", copyID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "상태 이력 조회에 실패했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
+		return
+	}
+
+	rows, err := db.Query(`SELECT id, copy_id, from_status, to_status, reason, COALESCE(note, ''), actor, changed_at
+	                       FROM copy_status_history
+	                       WHERE copy_id = ?
+	                       ORDER BY changed_at DESC, id DESC`, copyID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "상태 이력 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	history := []CopyStatusChange{}
+	for rows.Next() {
+		var h CopyStatusChange
+		var from sql.NullString
+		if err := rows.Scan(&h.ID, &h.CopyID, &from, &h.ToStatus, &h.Reason, &h.Note, &h.Actor, &h.ChangedAt); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		if from.Valid {
+			h.FromStatus = &from.String
+		}
+		h.ReasonLabel = copyStatusReasons[h.Reason].Label
+		history = append(history, h)
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 썸네일 크기 (가로 픽셀, 세로는 비율 유지)
+var coverSizes = map[string]int{"sm": 120, "md": 240, "lg": 480}
+
+// 업로드를 허용하는 형식과 원본 파일 확장자
+var coverTypes = map[string]string{"image/jpeg": "jpg", "image/png": "png", "image/gif": "gif"}
+
+const (
+	maxCoverBytes     = 5 << 20
+	minCoverDimension = 50
+	maxCoverDimension = 6000
+	coverJPEGQuality  = 85
+	// 원격 표지 일괄 가져오기 한 번에 처리하는 최대 도서 수
+	maxRemoteCoverBatch = 100
+)
+
+var (
+	errCoverType       = errors.New("JPEG, PNG, GIF 이미지만 업로드할 수 있습니다")
+	errCoverTooLarge   = errors.New("표지 이미지는 5MB 이하여야 합니다")
+	errCoverDimensions = errors.New("표지 이미지는 가로세로 50~6000픽셀이어야 합니다")
+	errCoverRemote     = errors.New("원격 표지를 가져오지 못했습니다")
+)
+
+var (
+	coverStore        blobStore
+	remoteCoverClient = &http.Client{
+		Timeout: 15 * time.Second,
+		Transport: &http.Transport{
+			// 프록시를 거치면 실제 접속 주소를 검사할 수 없으므로 직접 연결만 쓴다
+			Proxy: nil,
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+				Control: rejectInternalAddress,
+			}).DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+		},
+	}
+)
+
+// 원격 표지 접속 직전에 확인한 IP가 내부 주소(루프백, 사설, 링크 로컬 등)면 연결을 거부한다.
+// 이름 해석이 끝난 실제 주소를 검사하므로 리다이렉트나 DNS 재바인딩으로도 내부망에 닿지 않는다.
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return errCoverRemote
+	}
+	// 0.0.0.0/8과 100.64.0.0/10(CGNAT) 대역도 막는다
+	if v4 := ip.To4(); v4 != nil && (v4[0] == 0 || (v4[0] == 100 && v4[1]&0xc0 == 64)) {
+		return errCoverRemote
+	}
+	return nil
+}
+
+// 저장된 표지 정보
+type BookCover struct {
+	BookID      string            `json:"book_id"`
+	Version     string            `json:"version"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	ByteSize    int               `json:"byte_size"`
+	SourceURL   string            `json:"source_url,omitempty"`
+	URLs        map[string]string `json:"urls"`
+}
+
+func coverURL(bookID, version, size string) string {
+	return "/api/books/" + bookID + "/cover/" + version + "/" + size
+}
+
+func coverKey(bookID, version, name string) string {
+	return "covers/" + bookID + "/" + version + "/" + name
+}
+
+func coverURLs(bookID, version string) map[string]string {
+	urls := map[string]string{"original": coverURL(bookID, version, "original")}
+	for size := range coverSizes {
+		urls[size] = coverURL(bookID, version, size)
+	}
+	return urls
+}
+
+// 형식, 크기, 가로세로를 검증하고 이미지를 디코딩한다.
+func decodeCover(data []byte) (image.Image, string, error) {
+	if len(data) > maxCoverBytes {
+		return nil, "", errCoverTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := coverTypes[contentType]; !ok {
+		return nil, "", errCoverType
+	}
+	// 전체를 디코딩하기 전에 머리글로 크기를 확인한다 (압축 폭탄 방지)
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errCoverType
+	}
+	if config.Width < minCoverDimension || config.Height < minCoverDimension ||
+		config.Width > maxCoverDimension || config.Height > maxCoverDimension {
+		return nil, "", errCoverDimensions
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errCoverType
+	}
+	return img, contentType, nil
+}
+
+// 투명 영역은 흰 배경으로 채우고, 가로가 width보다 크면 영역 평균으로 축소한다.
+func coverThumbnail(src image.Image, width int) *image.RGBA {
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if sw <= width {
+		return flat
+	}
+	dw := width
+	dh := maxInt(1, sh*dw/sw)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := y*sh/dh, maxInt((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := x*sw/dw, maxInt((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, b, n int
+			for sy := sy0; sy < sy1; sy++ {
+				offset := flat.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += int(flat.Pix[offset])
+					g += int(flat.Pix[offset+1])
+					b += int(flat.Pix[offset+2])
+					offset += 4
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), 255
+		}
+	}
+	return dst
+}
+
+// 원본과 썸네일을 저장소에 올리고 표지 정보를 기록한다.
+// books.cover_image를 중간 크기 썸네일 URL로 바꾸며, 이전 버전의 파일은 지운다.
+func storeCover(bookID string, data []byte, img image.Image, contentType, sourceURL string) (BookCover, error) {
+	version := sha256Hex(data)[:16]
+	cover := BookCover{
+		BookID:      bookID,
+		Version:     version,
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		ByteSize:    len(data),
+		SourceURL:   sourceURL,
+		URLs:        coverURLs(bookID, version),
+	}
+
+	if err := coverStore.Put(coverKey(bookID, version, "original."+coverTypes[contentType]), data, contentType); err != nil {
+		return cover, err
+	}
+	for size, width := range coverSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, coverThumbnail(img, width), &jpeg.Options{Quality: coverJPEGQuality}); err != nil {
+			return cover, err
+		}
+		if err := coverStore.Put(coverKey(bookID, version, size+".jpg"), buf.Bytes(), "image/jpeg"); err != nil {
+			return cover, err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return cover, err
+	}
+	defer tx.Rollback()
+
+	var previous, previousType string
+	err = tx.QueryRow("SELECT version, content_type FROM book_covers WHERE book_id = ? FOR UPDATE", bookID).Scan(&previous, &previousType)
+	if err != nil && err != sql.ErrNoRows {
+		return cover, err
+	}
+	_, err = tx.Exec(`INSERT INTO book_covers (book_id, version, content_type, width, height, byte_size, source_url)
+	                  VALUES (?, ?, ?, ?, ?, ?, ?)
+	                  ON DUPLICATE KEY UPDATE version = VALUES(version), content_type = VALUES(content_type),
+	                      width = VALUES(width), height = VALUES(height), byte_size = VALUES(byte_size),
+	                      source_url = VALUES(source_url), created_at = CURRENT_TIMESTAMP`,
+		bookID, version, contentType, cover.Width, cover.Height, cover.ByteSize, nullIfEmpty(sourceURL))
+	if err != nil {
+		return cover, err
+	}
+	if _, err := tx.Exec("UPDATE books SET cover_image = ? WHERE id = ?", cover.URLs["md"], bookID); err != nil {
+		return cover, err
+	}
+	if err := tx.Commit(); err != nil {
+		return cover, err
+	}
+
+	if previous != "" && previous != version {
+		deleteCoverBlobs(bookID, previous, previousType)
+	}
+	reindexBooks([]string{bookID})
+	return cover, nil
+}
+
+// 저장소의 파일 삭제는 실패해도 표지 정보에는 영향이 없으므로 기록만 남긴다.
+func deleteCoverBlobs(bookID, version, contentType string) {
+	names := []string{"original." + coverTypes[contentType]}
+	for size := range coverSizes {
+		names = append(names, size+".jpg")
+	}
+	for _, name := range names {
+		if err := coverStore.Delete(coverKey(bookID, version, name)); err != nil {
+			log.Printf("Cover store error: %v", err)
+		}
+	}
+}
+
+func bookExists(bookID string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM books WHERE id = ?", bookID).Scan(&count)
+	return count > 0, err
+}
+
+// 관리자: 표지 업로드 (multipart file 필드 또는 요청 본문)
+func handleUploadCover(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	if exists, err := bookExists(bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 저장에 실패했습니다"})
+		return
+	} else if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	data, err := readUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	img, contentType, err := decodeCover(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cover, err := storeCover(bookID, data, img, contentType, "")
+	if err != nil {
+		log.Printf("Cover store error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 저장에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v uploaded cover %s for book %s", adminID, cover.Version, bookID)
+	c.JSON(http.StatusOK, cover)
+}
+
+// 관리자: 저장된 표지 삭제 (cover_image도 비운다)
+func handleDeleteCover(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 삭제에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var version, contentType string
+	err = tx.QueryRow("SELECT version, content_type FROM book_covers WHERE book_id = ? FOR UPDATE", bookID).Scan(&version, &contentType)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "저장된 표지가 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 삭제에 실패했습니다"})
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM book_covers WHERE book_id = ?", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 삭제에 실패했습니다"})
+		return
+	}
+	if _, err := tx.Exec("UPDATE books SET cover_image = '' WHERE id = ?", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 삭제에 실패했습니다"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 삭제에 실패했습니다"})
+		return
+	}
+
+	deleteCoverBlobs(bookID, version, contentType)
+	reindexBooks([]string{bookID})
+
+	log.Printf("Admin %v deleted cover of book %s", adminID, bookID)
+	c.JSON(http.StatusOK, gin.H{"message": "표지가 삭제되었습니다"})
+}
+
+// 표지 제공. URL에 버전이 들어 있어 내용이 바뀌지 않으므로 1년 동안 캐시하게 한다.
+// 이전 버전 URL로 요청하면 현재 버전으로 보낸다.
+func handleGetCover(c *gin.Context) {
+	bookID, version, size := c.Param("id"), c.Param("version"), c.Param("size")
+	if _, ok := coverSizes[size]; !ok && size != "original" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 크기입니다: " + size})
+		return
+	}
+
+	var current, contentType string
+	err := db.QueryRow("SELECT version, content_type FROM book_covers WHERE book_id = ?", bookID).Scan(&current, &contentType)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "저장된 표지가 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지를 불러오지 못했습니다"})
+		return
+	}
+	if version != current {
+		c.Header("Cache-Control", "no-cache")
+		c.Redirect(http.StatusFound, coverURL(bookID, current, size))
+		return
+	}
+
+	etag := `"` + version + "-" + size + `"`
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	name, servedType := size+".jpg", "image/jpeg"
+	if size == "original" {
+		name, servedType = "original."+coverTypes[contentType], contentType
+	}
+	data, err := coverStore.Get(coverKey(bookID, version, name))
+	if err == errBlobNotFound {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusNotFound, gin.H{"error": "표지 파일이 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Cover store error: %v", err)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지를 불러오지 못했습니다"})
+		return
+	}
+	c.Data(http.StatusOK, servedType, data)
+}
+
+// 원격 표지 URL을 내려받는다 (http/https만, 최대 5MB).
+func fetchRemoteCover(rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errCoverRemote
+	}
+	resp, err := remoteCoverClient.Get(parsed.String())
+	if err != nil {
+		return nil, errCoverRemote
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errCoverRemote
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverBytes+1))
+	if err != nil {
+		return nil, errCoverRemote
+	}
+	if len(data) > maxCoverBytes {
+		return nil, errCoverTooLarge
+	}
+	return data, nil
+}
+
+func importRemoteCover(bookID, rawURL string) (BookCover, error) {
+	data, err := fetchRemoteCover(rawURL)
+	if err != nil {
+		return BookCover{}, err
+	}
+	img, contentType, err := decodeCover(data)
+	if err != nil {
+		return BookCover{}, err
+	}
+	return storeCover(bookID, data, img, contentType, rawURL)
+}
+
+// 사용자에게 보여줄 수 있는 오류인지 (검증/원격 오류)
+func isCoverInputError(err error) bool {
+	return err == errCoverType || err == errCoverTooLarge || err == errCoverDimensions || err == errCoverRemote
+}
+
+// 관리자: 원격 표지 URL을 내려받아 저장 (url을 생략하면 현재 cover_image)
+func handleImportCover(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	var req struct {
+		URL string `json:"url"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	var current string
+	err := db.QueryRow("SELECT COALESCE(cover_image, '') FROM books WHERE id = ?", bookID).Scan(&current)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 저장에 실패했습니다"})
+		return
+	}
+	source := strings.TrimSpace(req.URL)
+	if source == "" {
+		source = current
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "가져올 원격 표지 URL이 없습니다"})
+		return
+	}
+
+	cover, err := importRemoteCover(bookID, source)
+	if isCoverInputError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "url": source})
+		return
+	}
+	if err != nil {
+		log.Printf("Cover store error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "표지 저장에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v imported cover of book %s from %s", adminID, bookID, source)
+	c.JSON(http.StatusOK, cover)
+}
+
+// 관리자: 아직 원격 URL을 쓰는 표지를 limit권(기본 20, 최대 100)씩 내려받아 저장
+// 실패한 도서는 다시 시도하지 않도록 ID 순으로 처리하고, 응답의 next_after로 다음 묶음을 요청한다.
+func handleImportRemoteCovers(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	after := c.Query("after")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > maxRemoteCoverBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit은 1에서 100 사이여야 합니다"})
+		return
+	}
+
+	const remoteWhere = `WHERE (b.cover_image LIKE 'http://%' OR b.cover_image LIKE 'https://%')
+	                       AND NOT EXISTS (SELECT 1 FROM book_covers bc WHERE bc.book_id = b.id)
+	                       AND b.id > ?`
+	rows, err := db.Query("SELECT b.id, b.cover_image FROM books b "+remoteWhere+" ORDER BY b.id LIMIT ?", after, limit)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "원격 표지 가져오기에 실패했습니다"})
+		return
+	}
+	type pendingCover struct{ bookID, url string }
+	pending := []pendingCover{}
+	for rows.Next() {
+		var p pendingCover
+		if err := rows.Scan(&p.bookID, &p.url); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+
+	results := []gin.H{}
+	imported := 0
+	for _, p := range pending {
+		after = p.bookID
+		cover, err := importRemoteCover(p.bookID, p.url)
+		if err != nil {
+			if !isCoverInputError(err) {
+				log.Printf("Cover store error: %v", err)
+			}
+			results = append(results, gin.H{"book_id": p.bookID, "url": p.url, "status": "failed", "error": err.Error()})
+			continue
+		}
+		imported++
+		results = append(results, gin.H{"book_id": p.bookID, "url": p.url, "status": "imported", "cover": cover})
+	}
+
+	var remaining int
+	if err := db.QueryRow("SELECT COUNT(*) FROM books b "+remoteWhere, after).Scan(&remaining); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+
+	log.Printf("Admin %v imported %d of %d remote covers", adminID, imported, len(pending))
+	c.JSON(http.StatusOK, gin.H{"processed": len(pending), "imported": imported, "remaining": remaining,
+		"next_after": after, "results": results})
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// 저자가 같을 때 중복으로 보는 제목 유사도 (1 - 편집 거리/긴 제목 길이)
+	duplicateTitleThreshold = 0.85
+	// 한 번에 병합할 수 있는 도서 수
+	maxMergeBooks = 20
+	// 비교 묶음을 나누는 저자 키 앞부분 글자 수
+	duplicateAuthorBlockRunes = 2
+)
+
+// 중복 찾기 작업
+type DuplicateScan struct {
+	ID           string     `json:"id"`
+	Status       string     `json:"status"`
+	CheckedBooks int        `json:"checked_books"`
+	PairsFound   int        `json:"pairs_found"`
+	Error        string     `json:"error,omitempty"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+}
+
+// 중복 의심 쌍. Book은 먼저 등록된 도서(남길 후보), Duplicate는 나중에 등록된 도서다.
+type DuplicatePair struct {
+	ID              int              `json:"id"`
+	Book            DuplicateBookRef `json:"book"`
+	Duplicate       DuplicateBookRef `json:"duplicate"`
+	ISBNMatch       bool             `json:"isbn_match"`
+	AuthorMatch     bool             `json:"author_match"`
+	TitleSimilarity float64          `json:"title_similarity"`
+	Score           float64          `json:"score"`
+	Status          string           `json:"status"`
+	FoundAt         time.Time        `json:"found_at"`
+}
+
+type DuplicateBookRef struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Publisher string `json:"publisher"`
+	Year      int    `json:"year"`
+	ISBN      string `json:"isbn"`
+	Copies    int    `json:"copies"`
+}
+
+// 비교용 도서 정보 (등록 순서대로 읽는다)
+type duplicateCandidate struct {
+	ID        string
+	ISBN      string
+	TitleKey  []rune
+	AuthorKey string
+}
+
+type duplicateMatch struct {
+	BookID, DuplicateID string
+	ISBNMatch           bool
+	AuthorMatch         bool
+	TitleSimilarity     float64
+}
+
+// 점수: ISBN 일치 50점, 제목 유사도 35점, 저자 일치 15점
+func (m duplicateMatch) score() float64 {
+	score := 35 * m.TitleSimilarity
+	if m.ISBNMatch {
+		score += 50
+	}
+	if m.AuthorMatch {
+		score += 15
+	}
+	return math.Round(score*10) / 10
+}
+
+// 비교용 제목/저자 키: 괄호 속 부가 정보(판차, 부제 등)를 빼고 글자와 숫자만 소문자로 남긴다.
+func duplicateKey(text string) string {
+	stripped := stripBracketed(text)
+	if strings.TrimSpace(stripped) == "" {
+		stripped = text
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(stripped) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func stripBracketed(text string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range text {
+		switch r {
+		case '(', '[', '{', '<', '〈', '《':
+			depth++
+		case ')', ']', '}', '>', '〉', '》':
+			if depth > 0 {
+				depth--
+			}
+		default:
+			if depth == 0 {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// 제목 유사도 (0~1)
+func titleSimilarity(a, b []rune) float64 {
+	longest := maxInt(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(editDistance(a, b, longest))/float64(longest)
+}
+
+// 같은 저자는 표기 일부가 더 붙은 경우("한강"과 "한강 지음")도 일치로 본다.
+func authorsMatch(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+// 두 도서를 비교한다. 중복이 아니면 false.
+// ISBN이 같거나, 저자가 같고 제목 유사도가 duplicateTitleThreshold 이상이면 중복으로 본다.
+func compareDuplicate(a, b duplicateCandidate) (duplicateMatch, bool) {
+	m := duplicateMatch{
+		BookID:          a.ID,
+		DuplicateID:     b.ID,
+		ISBNMatch:       a.ISBN != "" && a.ISBN == b.ISBN,
+		AuthorMatch:     authorsMatch(a.AuthorKey, b.AuthorKey),
+		TitleSimilarity: math.Round(titleSimilarity(a.TitleKey, b.TitleKey)*1000) / 1000,
+	}
+	return m, m.ISBNMatch || (m.AuthorMatch && m.TitleSimilarity >= duplicateTitleThreshold)
+}
+
+// 중복 의심 쌍을 찾는다. 모든 쌍을 비교하지 않도록 ISBN이 같거나, 저자 키 앞부분이 같거나,
+// 제목이 같은 도서끼리만 비교한다. ISBN이 다르면 저자가 일치해야 중복이므로 저자 앞부분 묶음 안에서
+// 제목 오타나 표기 차이("해리포터"/"해리 포터와")가 있는 도서도 비교된다.
+// books는 등록 순서여야 하며, 쌍의 앞쪽이 먼저 등록된 도서가 된다.
+func findDuplicatePairs(books []duplicateCandidate) []duplicateMatch {
+	groups := map[string][]int{}
+	for i, book := range books {
+		if book.ISBN != "" {
+			groups["isbn:"+book.ISBN] = append(groups["isbn:"+book.ISBN], i)
+		}
+		if book.AuthorKey != "" {
+			key := "author:" + truncateRunes(book.AuthorKey, duplicateAuthorBlockRunes)
+			groups[key] = append(groups[key], i)
+		}
+		if len(book.TitleKey) > 0 {
+			key := "title:" + string(book.TitleKey)
+			groups[key] = append(groups[key], i)
+		}
+	}
+
+	seen := map[[2]int]bool{}
+	matches := []duplicateMatch{}
+	for _, members := range groups {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				pair := [2]int{members[x], members[y]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				if m, ok := compareDuplicate(books[pair[0]], books[pair[1]]); ok {
+					matches = append(matches, m)
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].BookID != matches[j].BookID {
+			return matches[i].BookID < matches[j].BookID
+		}
+		return matches[i].DuplicateID < matches[j].DuplicateID
+	})
+	return matches
+}
+
+// 관리자: 중복 찾기 작업 시작 (백그라운드로 처리, 진행 중인 작업이 있으면 409)
+func handleStartDuplicateScan(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var running int
+	if err := db.QueryRow("SELECT COUNT(*) FROM duplicate_scans WHERE status IN ('queued', 'running')").Scan(&running); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 찾기 작업을 만들지 못했습니다"})
+		return
+	}
+	if running > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "진행 중인 중복 찾기 작업이 있습니다"})
+		return
+	}
+
+	scan := DuplicateScan{ID: uuid.New().String(), Status: "queued", CreatedBy: actor, CreatedAt: time.Now()}
+	if _, err := db.Exec("INSERT INTO duplicate_scans (id, status, created_by) VALUES (?, ?, ?)", scan.ID, scan.Status, scan.CreatedBy); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 찾기 작업을 만들지 못했습니다"})
+		return
+	}
+
+	go runDuplicateScan(scan.ID)
+
+	log.Printf("Admin %s started duplicate scan %s", actor, scan.ID)
+	c.JSON(http.StatusAccepted, scan)
+}
+
+func runDuplicateScan(scanID string) {
+	if _, err := db.Exec("UPDATE duplicate_scans SET status = 'running', started_at = CURRENT_TIMESTAMP WHERE id = ?", scanID); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+
+	rows, err := db.Query("SELECT id, title, author, COALESCE(isbn, '') FROM books ORDER BY created_at, id")
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		finishDuplicateScan(scanID, "failed", "도서 목록을 읽지 못했습니다")
+		return
+	}
+	books := []duplicateCandidate{}
+	for rows.Next() {
+		var id, title, author, isbn string
+		if err := rows.Scan(&id, &title, &author, &isbn); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		normalized, _ := normalizeISBN(isbn)
+		books = append(books, duplicateCandidate{
+			ID:        id,
+			ISBN:      normalized,
+			TitleKey:  []rune(duplicateKey(title)),
+			AuthorKey: duplicateKey(author),
+		})
+	}
+	rows.Close()
+	if _, err := db.Exec("UPDATE duplicate_scans SET checked_books = ? WHERE id = ?", len(books), scanID); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+
+	matches := findDuplicatePairs(books)
+	for _, m := range matches {
+		// 중복 아님으로 판정한 쌍은 상태를 그대로 둔다
+		_, err := db.Exec(`INSERT INTO book_duplicates (book_id, duplicate_id, isbn_match, author_match, title_similarity, score, scan_id)
+		                   VALUES (?, ?, ?, ?, ?, ?, ?)
+		                   ON DUPLICATE KEY UPDATE isbn_match = VALUES(isbn_match), author_match = VALUES(author_match),
+		                     title_similarity = VALUES(title_similarity), score = VALUES(score), scan_id = VALUES(scan_id)`,
+			m.BookID, m.DuplicateID, m.ISBNMatch, m.AuthorMatch, m.TitleSimilarity, m.score(), scanID)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			finishDuplicateScan(scanID, "failed", "중복 쌍을 기록하지 못했습니다")
+			return
+		}
+	}
+
+	// 이번에 다시 찾지 못한 검토 전 쌍(서지를 고쳐 더는 중복이 아닌 경우)은 지운다
+	if _, err := db.Exec("DELETE FROM book_duplicates WHERE status = 'open' AND (scan_id IS NULL OR scan_id <> ?)", scanID); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	if _, err := db.Exec("UPDATE duplicate_scans SET pairs_found = ? WHERE id = ?", len(matches), scanID); err != nil {
+		log.Printf("Database error: %v", err)
+	}
+
+	finishDuplicateScan(scanID, "completed", "")
+	log.Printf("Duplicate scan %s completed: %d books, %d pairs", scanID, len(books), len(matches))
+}
+
+func finishDuplicateScan(scanID, status, message string) {
+	_, err := db.Exec("UPDATE duplicate_scans SET status = ?, error_message = ?, finished_at = CURRENT_TIMESTAMP WHERE id = ?",
+		status, nullIfEmpty(message), scanID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+}
+
+const duplicateScanColumns = `id, status, checked_books, pairs_found, COALESCE(error_message, ''), created_by, created_at, started_at, finished_at`
+
+func scanDuplicateScan(scanner interface{ Scan(...interface{}) error }) (DuplicateScan, error) {
+	var scan DuplicateScan
+	var startedAt, finishedAt sql.NullTime
+	err := scanner.Scan(&scan.ID, &scan.Status, &scan.CheckedBooks, &scan.PairsFound, &scan.Error,
+		&scan.CreatedBy, &scan.CreatedAt, &startedAt, &finishedAt)
+	if startedAt.Valid {
+		scan.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		scan.FinishedAt = &finishedAt.Time
+	}
+	return scan, err
+}
+
+// 관리자: 중복 찾기 작업 진행 상황
+func handleGetDuplicateScan(c *gin.Context) {
+	scan, err := scanDuplicateScan(db.QueryRow("SELECT "+duplicateScanColumns+" FROM duplicate_scans WHERE id = ?", c.Param("scan_id")))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "중복 찾기 작업을 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 찾기 작업을 조회하지 못했습니다"})
+		return
+	}
+	c.JSON(http.StatusOK, scan)
+}
+
+// 관리자: 중복 의심 쌍 목록 (점수 높은 순)와 마지막 찾기 작업.
+// status(open 기본, dismissed), min_score, limit(기본 50, 최대 500)로 거른다.
+func handleGetDuplicates(c *gin.Context) {
+	status := c.DefaultQuery("status", "open")
+	if status != "open" && status != "dismissed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "알 수 없는 상태입니다"})
+		return
+	}
+	limit := 50
+	if value, err := strconv.Atoi(c.Query("limit")); err == nil && value >= 1 && value <= 500 {
+		limit = value
+	}
+	minScore, _ := strconv.ParseFloat(c.Query("min_score"), 64)
+
+	var lastScan *DuplicateScan
+	scan, err := scanDuplicateScan(db.QueryRow("SELECT " + duplicateScanColumns + " FROM duplicate_scans ORDER BY created_at DESC LIMIT 1"))
+	if err == nil {
+		lastScan = &scan
+	} else if err != sql.ErrNoRows {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 목록을 조회하지 못했습니다"})
+		return
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM book_duplicates WHERE status = ? AND score >= ?", status, minScore).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 목록을 조회하지 못했습니다"})
+		return
+	}
+
+	rows, err := db.Query(`SELECT d.id, d.isbn_match, d.author_match, d.title_similarity, d.score, d.status, d.found_at,
+	                              a.id, a.title, a.author, COALESCE(a.publisher, ''), COALESCE(a.year, 0), COALESCE(a.isbn, ''),
+	                              (SELECT COUNT(*) FROM book_copies ac WHERE ac.book_id = a.id AND ac.status <> 'withdrawn'),
+	                              b.id, b.title, b.author, COALESCE(b.publisher, ''), COALESCE(b.year, 0), COALESCE(b.isbn, ''),
+	                              (SELECT COUNT(*) FROM book_copies bc WHERE bc.book_id = b.id AND bc.status <> 'withdrawn')
+	                       FROM book_duplicates d
+	                       JOIN books a ON a.id = d.book_id
+	                       JOIN books b ON b.id = d.duplicate_id
+	                       WHERE d.status = ? AND d.score >= ?
+	                       ORDER BY d.score DESC, d.id
+	                       LIMIT ?`, status, minScore, limit)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 목록을 조회하지 못했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	pairs := []DuplicatePair{}
+	for rows.Next() {
+		var p DuplicatePair
+		if err := rows.Scan(&p.ID, &p.ISBNMatch, &p.AuthorMatch, &p.TitleSimilarity, &p.Score, &p.Status, &p.FoundAt,
+			&p.Book.ID, &p.Book.Title, &p.Book.Author, &p.Book.Publisher, &p.Book.Year, &p.Book.ISBN, &p.Book.Copies,
+			&p.Duplicate.ID, &p.Duplicate.Title, &p.Duplicate.Author, &p.Duplicate.Publisher, &p.Duplicate.Year,
+			&p.Duplicate.ISBN, &p.Duplicate.Copies); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pairs": pairs, "total": total, "last_scan": lastScan})
+}
+
+// 관리자: 중복 의심 쌍을 중복 아님으로 판정
+func handleDismissDuplicate(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	result, err := db.Exec(`UPDATE book_duplicates SET status = 'dismissed', dismissed_by = ?, dismissed_at = CURRENT_TIMESTAMP
+	                        WHERE id = ? AND status = 'open'`, actor, c.Param("id"))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중복 판정에 실패했습니다"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "검토 전인 중복 의심 쌍을 찾을 수 없습니다"})
+		return
+	}
+
+	log.Printf("Admin %s dismissed duplicate pair %s", actor, c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{"message": "중복 아님으로 처리했습니다"})
+}
+
+// 병합 결과 집계
+type mergeCounts struct {
+	Copies                int `json:"copies"`
+	Borrows               int `json:"borrows"`
+	Reservations          int `json:"reservations"`
+	CancelledReservations int `json:"cancelled_reservations"`
+}
+
+// 저장된 표지 (병합 후 blob을 지우기 위해)
+type mergedCover struct {
+	BookID, Version, ContentType string
+}
+
+// duplicateID 도서를 survivorID 도서로 합친다.
+// 복본(복본 번호는 남는 도서의 번호 뒤로), 대출 기록, 예약, 주제, 저자 외 기여자를 옮기고
+// 남는 도서에 비어 있는 서지 항목은 없어지는 도서의 값으로 채운 뒤 없어지는 도서를 지우고 redirect를 남긴다.
+// 같은 이용자가 두 도서를 모두 예약했으면 없어지는 도서의 예약은 취소한다.
+func mergeBook(tx *sql.Tx, survivorID, duplicateID, actor string, counts *mergeCounts) (*mergedCover, error) {
+	var title string
+	if err := tx.QueryRow("SELECT title FROM books WHERE id = ? FOR UPDATE", duplicateID).Scan(&title); err != nil {
+		return nil, err
+	}
+
+	var maxCopyNumber int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(copy_number), 0) FROM book_copies WHERE book_id = ?", survivorID).Scan(&maxCopyNumber); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec("UPDATE book_copies SET book_id = ?, copy_number = copy_number + ? WHERE book_id = ?",
+		survivorID, maxCopyNumber, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	affected, _ := result.RowsAffected()
+	counts.Copies += int(affected)
+
+	if result, err = tx.Exec("UPDATE borrows SET book_id = ? WHERE book_id = ?", survivorID, duplicateID); err != nil {
+		return nil, err
+	}
+	affected, _ = result.RowsAffected()
+	counts.Borrows += int(affected)
+
+	result, err = tx.Exec(`UPDATE reservations r
+	                       JOIN reservations s ON s.user_id = r.user_id AND s.book_id = ? AND s.status = 'active'
+	                       SET r.status = 'cancelled'
+	                       WHERE r.book_id = ? AND r.status = 'active'`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	affected, _ = result.RowsAffected()
+	counts.CancelledReservations += int(affected)
+	if result, err = tx.Exec("UPDATE reservations SET book_id = ? WHERE book_id = ?", survivorID, duplicateID); err != nil {
+		return nil, err
+	}
+	affected, _ = result.RowsAffected()
+	counts.Reservations += int(affected)
+
+	if _, err := tx.Exec(`INSERT IGNORE INTO book_subjects (book_id, subject_code, assigned_at)
+	                      SELECT ?, subject_code, assigned_at FROM book_subjects WHERE book_id = ?`, survivorID, duplicateID); err != nil {
+		return nil, err
+	}
+	var maxPosition int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(position), 0) FROM book_contributors WHERE book_id = ?", survivorID).Scan(&maxPosition); err != nil {
+		return nil, err
+	}
+	// 저자는 남는 도서의 저자(books.author와 맞춰 둔 값)를 유지하고 역자 등 다른 역할만 옮긴다
+	if _, err := tx.Exec(`INSERT IGNORE INTO book_contributors (book_id, author_id, role, position)
+	                      SELECT ?, author_id, role, position + ? FROM book_contributors
+	                      WHERE book_id = ? AND role <> 'author'`,
+		survivorID, maxPosition, duplicateID); err != nil {
+		return nil, err
+	}
+
+	// 업로드한 표지는 없어지는 도서 ID 경로에 저장되어 있으므로 옮기지 않고 커밋 후 지운다
+	var cover *mergedCover
+	var version, contentType string
+	err = tx.QueryRow("SELECT version, content_type FROM book_covers WHERE book_id = ?", duplicateID).Scan(&version, &contentType)
+	if err == nil {
+		cover = &mergedCover{BookID: duplicateID, Version: version, ContentType: contentType}
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// ISBN은 UNIQUE이므로 없어지는 도서에서 먼저 비운다
+	var isbn sql.NullString
+	if err := tx.QueryRow("SELECT isbn FROM books WHERE id = ?", duplicateID).Scan(&isbn); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE books SET isbn = NULL WHERE id = ?", duplicateID); err != nil {
+		return nil, err
+	}
+	coverSource := "d.cover_image"
+	if cover != nil {
+		coverSource = "NULL"
+	}
+	_, err = tx.Exec(`UPDATE books s JOIN books d ON d.id = ?
+	                  SET s.isbn = COALESCE(NULLIF(s.isbn, ''), ?),
+	                      s.publisher = COALESCE(NULLIF(s.publisher, ''), d.publisher),
+	                      s.year = COALESCE(s.year, d.year),
+	                      s.description = COALESCE(NULLIF(s.description, ''), d.description),
+	                      s.cover_image = COALESCE(NULLIF(s.cover_image, ''), `+coverSource+`)
+	                  WHERE s.id = ?`, duplicateID, isbn, survivorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 없어지는 도서를 가리키던 redirect도 남는 도서로 옮긴다
+	if _, err := tx.Exec("UPDATE book_redirects SET book_id = ? WHERE book_id = ?", survivorID, duplicateID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("INSERT INTO book_redirects (old_id, book_id, title, merged_by) VALUES (?, ?, ?, ?)",
+		duplicateID, survivorID, title, actor); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM books WHERE id = ?", duplicateID); err != nil {
+		return nil, err
+	}
+	return cover, nil
+}
+
+// 관리자: 도서 병합. :id 도서를 남기고 duplicate_ids 도서의 복본/대출/예약을 옮긴 뒤 지운다.
+// 없어진 도서 ID로 조회하면 남은 도서로 redirect된다.
+func handleMergeBooks(c *gin.Context) {
+	actor := c.GetString("user_id")
+	survivorID := c.Param("id")
+
+	var req struct {
+		DuplicateIDs []string `json:"duplicate_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	duplicateIDs := []string{}
+	for _, id := range uniqueStrings(req.DuplicateIDs) {
+		if id = strings.TrimSpace(id); id != "" {
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if len(duplicateIDs) == 0 || len(duplicateIDs) > maxMergeBooks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("병합할 도서는 1~%d권이어야 합니다", maxMergeBooks)})
+		return
+	}
+	for _, id := range duplicateIDs {
+		if id == survivorID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "남길 도서는 병합할 도서에 넣을 수 없습니다"})
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 병합에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM books WHERE id = ? FOR UPDATE", survivorID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 병합에 실패했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	counts := mergeCounts{}
+	covers := []*mergedCover{}
+	for _, id := range duplicateIDs {
+		cover, err := mergeBook(tx, survivorID, id, actor, &counts)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "병합할 도서를 찾을 수 없습니다", "book_id": id})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 병합에 실패했습니다"})
+			return
+		}
+		if cover != nil {
+			covers = append(covers, cover)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 병합에 실패했습니다"})
+		return
+	}
+
+	for _, id := range duplicateIDs {
+		unindexBook(id)
+	}
+	reindexBooks([]string{survivorID})
+	for _, cover := range covers {
+		deleteCoverBlobs(cover.BookID, cover.Version, cover.ContentType)
+	}
+
+	book, err := fetchBook(survivorID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	log.Printf("Admin %s merged books %v into %s (%d copies, %d borrows, %d reservations)",
+		actor, duplicateIDs, survivorID, counts.Copies, counts.Borrows, counts.Reservations)
+	c.JSON(http.StatusOK, gin.H{"message": "도서가 병합되었습니다", "book": book, "merged": duplicateIDs, "moved": counts})
+}
+
+// 병합으로 없어진 도서 ID가 가리키는 도서 (없으면 빈 문자열)
+func redirectedBookID(oldID string) (string, error) {
+	var bookID string
+	err := db.QueryRow("SELECT book_id FROM book_redirects WHERE old_id = ?", oldID).Scan(&bookID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return bookID, err
+}
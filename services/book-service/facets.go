@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 패싯 정의: 버킷 값은 Expr로 계산하고, 선택한 값은 Expr IN (...) 조건으로 거른다.
+// 같은 패싯의 여러 값은 OR, 서로 다른 패싯은 AND로 결합된다.
+// 다대다 관계는 Join으로 버킷을 세고, Match(%s에 IN 목록)로 선택 값을 거른다.
+type facetDef struct {
+	Expr      string
+	Join      string
+	Match     string
+	LabelExpr string
+	// 반환할 최대 버킷 수 (0이면 전부)
+	Size int
+	// true이면 값 내림차순, false이면 도서 수 내림차순
+	ByValue bool
+	Label   func(value string) string
+}
+
+type FacetBucket struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// 패싯을 포함한 도서 목록 응답
+type BookPageResponse struct {
+	PageResponse
+	Facets map[string][]FacetBucket `json:"facets,omitempty"`
+}
+
+// 지원하는 패싯 (facets=publisher,year 또는 facets=all로 요청, facet_<이름>으로 값 선택)
+var bookFacets = map[string]facetDef{
+	"publisher": {Expr: "b.publisher", Size: 10},
+	"author":    {Expr: "b.author", Size: 10},
+	"year": {
+		Expr:    "CONCAT(FLOOR(b.year / 10) * 10, '-', FLOOR(b.year / 10) * 10 + 9)",
+		ByValue: true,
+		Label: func(value string) string {
+			return strings.SplitN(value, "-", 2)[0] + "년대"
+		},
+	},
+	"subject": {
+		Expr:      "fs.subject_code",
+		Join:      " JOIN book_subjects fs ON fs.book_id = b.id JOIN subjects fsub ON fsub.code = fs.subject_code",
+		Match:     "EXISTS (SELECT 1 FROM book_subjects ms WHERE ms.book_id = b.id AND ms.subject_code IN (%s))",
+		LabelExpr: "fsub.name",
+		Size:      10,
+	},
+	"branch": {
+		Expr:      "fbr.code",
+		Join:      " JOIN book_copies fbc ON fbc.book_id = b.id AND fbc.status <> 'withdrawn' JOIN branches fbr ON fbr.id = fbc.branch_id",
+		Match:     "EXISTS (SELECT 1 FROM book_copies mc JOIN branches mbr ON mbr.id = mc.branch_id WHERE mc.book_id = b.id AND mc.status <> 'withdrawn' AND mbr.code IN (%s))",
+		LabelExpr: "fbr.name",
+	},
+	"availability": {
+		Expr: "CASE WHEN EXISTS (SELECT 1 FROM book_copies fc WHERE fc.book_id = b.id AND fc.status = 'available') " +
+			"THEN 'available' ELSE 'unavailable' END",
+		Label: func(value string) string {
+			if value == "available" {
+				return "대여 가능"
+			}
+			return "대여 불가"
+		},
+	},
+}
+
+// 요청한 패싯 이름과 패싯별 선택 값
+type facetRequest struct {
+	Names    []string
+	Selected map[string][]string
+}
+
+func parseFacetRequest(c *gin.Context) (facetRequest, error) {
+	req := facetRequest{Selected: map[string][]string{}}
+
+	for name := range bookFacets {
+		values := []string{}
+		for _, value := range c.QueryArray("facet_" + name) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		if name == "year" {
+			for _, value := range values {
+				if !validYearBucket(value) {
+					return req, fmt.Errorf("연도 패싯 값이 올바르지 않습니다: %s", value)
+				}
+			}
+		}
+		if len(values) > 0 {
+			req.Selected[name] = uniqueStrings(values)
+		}
+	}
+
+	requested := strings.TrimSpace(c.Query("facets"))
+	if requested == "" {
+		return req, nil
+	}
+	if requested == "all" {
+		for name := range bookFacets {
+			req.Names = append(req.Names, name)
+		}
+		sort.Strings(req.Names)
+		return req, nil
+	}
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := bookFacets[name]; !ok {
+			return req, fmt.Errorf("지원하지 않는 패싯입니다: %s", name)
+		}
+		req.Names = append(req.Names, name)
+	}
+	req.Names = uniqueStrings(req.Names)
+	return req, nil
+}
+
+// 선택 값 조건 (except 패싯은 제외). 버킷 수를 셀 때는 자기 패싯의 선택을 빼야 다른 값의 수가 보인다.
+func (r facetRequest) where(except string) (string, []interface{}) {
+	names := make([]string, 0, len(r.Selected))
+	for name := range r.Selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clause := ""
+	args := []interface{}{}
+	for _, name := range names {
+		if name == except {
+			continue
+		}
+		values := r.Selected[name]
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		if def := bookFacets[name]; def.Match != "" {
+			clause += " AND " + fmt.Sprintf(def.Match, placeholders)
+		} else {
+			clause += " AND " + def.Expr + " IN (" + placeholders + ")"
+		}
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+	return clause, args
+}
+
+// 요청한 패싯별 버킷을 계산한다. where/args는 패싯 선택을 제외한 검색 조건이다.
+func countFacets(req facetRequest, where string, args []interface{}) (map[string][]FacetBucket, error) {
+	facets := make(map[string][]FacetBucket, len(req.Names))
+	for _, name := range req.Names {
+		def := bookFacets[name]
+		facetWhere, facetArgs := req.where(name)
+
+		labelExpr := def.Expr
+		if def.LabelExpr != "" {
+			labelExpr = def.LabelExpr
+		}
+		query := "SELECT " + def.Expr + " AS bucket, MIN(" + labelExpr + ") AS label, COUNT(DISTINCT b.id) AS book_count" +
+			" FROM books b" + def.Join + where + facetWhere +
+			" GROUP BY bucket HAVING bucket <> ''"
+		if def.ByValue {
+			query += " ORDER BY bucket DESC"
+		} else {
+			query += " ORDER BY book_count DESC, bucket"
+		}
+		queryArgs := append(append([]interface{}{}, args...), facetArgs...)
+
+		rows, err := db.Query(query, queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		selected := map[string]bool{}
+		for _, value := range req.Selected[name] {
+			selected[value] = true
+		}
+		buckets := []FacetBucket{}
+		for rows.Next() {
+			var bucket FacetBucket
+			if err := rows.Scan(&bucket.Value, &bucket.Label, &bucket.Count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			bucket.Selected = selected[bucket.Value]
+			// 선택한 값은 상위 목록 밖이어도 항상 포함
+			if def.Size > 0 && len(buckets) >= def.Size && !bucket.Selected {
+				continue
+			}
+			buckets = append(buckets, bucket)
+			delete(selected, bucket.Value)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// 결과가 없는 선택 값도 해제할 수 있도록 0건으로 표시
+		for _, value := range req.Selected[name] {
+			if selected[value] {
+				buckets = append(buckets, FacetBucket{Value: value, Count: 0, Selected: true})
+			}
+		}
+		for i := range buckets {
+			if buckets[i].Label == "" {
+				buckets[i].Label = buckets[i].Value
+			}
+			if def.Label != nil {
+				buckets[i].Label = def.Label(buckets[i].Value)
+			}
+		}
+		facets[name] = buckets
+	}
+	return facets, nil
+}
+
+// 빈 결과에 붙일 패싯 (선택 값만 0건으로 표시)
+func emptyFacets(req facetRequest) map[string][]FacetBucket {
+	if len(req.Names) == 0 {
+		return nil
+	}
+	facets := make(map[string][]FacetBucket, len(req.Names))
+	for _, name := range req.Names {
+		buckets := []FacetBucket{}
+		for _, value := range req.Selected[name] {
+			label := value
+			if def := bookFacets[name]; def.Label != nil {
+				label = def.Label(value)
+			}
+			buckets = append(buckets, FacetBucket{Value: value, Label: label, Selected: true})
+		}
+		facets[name] = buckets
+	}
+	return facets
+}
+
+// 연도 패싯 값은 10년 단위 구간이어야 한다 (예: "2010-2019")
+func validYearBucket(value string) bool {
+	parts := strings.SplitN(value, "-", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	start, err1 := strconv.Atoi(parts[0])
+	end, err2 := strconv.Atoi(parts[1])
+	return err1 == nil && err2 == nil && start%10 == 0 && end == start+9
+}
@@ -0,0 +1,188 @@
+package main
+
+import "strings"
+
+// 한글 음절 분해/조합 (유니코드 한글 음절 = 0xAC00 + (초성*21 + 중성)*28 + 종성)
+const (
+	hangulBase      = 0xAC00
+	hangulJungCount = 21
+	hangulJongCount = 28
+)
+
+var (
+	choseongs  = []rune("ㄱㄲㄴㄷㄸㄹㅁㅂㅃㅅㅆㅇㅈㅉㅊㅋㅌㅍㅎ")
+	jungseongs = []rune("ㅏㅐㅑㅒㅓㅔㅕㅖㅗㅘㅙㅚㅛㅜㅝㅞㅟㅠㅡㅢㅣ")
+	// 종성 0번은 받침 없음
+	jongseongs = []rune(" ㄱㄲㄳㄴㄵㄶㄷㄹㄺㄻㄼㄽㄾㄿㅀㅁㅂㅄㅅㅆㅇㅈㅊㅋㅌㅍㅎ")
+)
+
+// 두 자모가 합쳐지는 겹모음과 겹받침
+var (
+	compoundVowels = map[[2]rune]rune{
+		{'ㅗ', 'ㅏ'}: 'ㅘ', {'ㅗ', 'ㅐ'}: 'ㅙ', {'ㅗ', 'ㅣ'}: 'ㅚ',
+		{'ㅜ', 'ㅓ'}: 'ㅝ', {'ㅜ', 'ㅔ'}: 'ㅞ', {'ㅜ', 'ㅣ'}: 'ㅟ',
+		{'ㅡ', 'ㅣ'}: 'ㅢ',
+	}
+	compoundFinals = map[[2]rune]rune{
+		{'ㄱ', 'ㅅ'}: 'ㄳ', {'ㄴ', 'ㅈ'}: 'ㄵ', {'ㄴ', 'ㅎ'}: 'ㄶ',
+		{'ㄹ', 'ㄱ'}: 'ㄺ', {'ㄹ', 'ㅁ'}: 'ㄻ', {'ㄹ', 'ㅂ'}: 'ㄼ', {'ㄹ', 'ㅅ'}: 'ㄽ',
+		{'ㄹ', 'ㅌ'}: 'ㄾ', {'ㄹ', 'ㅍ'}: 'ㄿ', {'ㄹ', 'ㅎ'}: 'ㅀ',
+		{'ㅂ', 'ㅅ'}: 'ㅄ',
+	}
+)
+
+// 두벌식 자판에서 영문 키에 대응하는 자모 (한/영 전환을 잊고 입력한 경우 복원용)
+var dubeolsikKeys = map[rune]rune{
+	'q': 'ㅂ', 'w': 'ㅈ', 'e': 'ㄷ', 'r': 'ㄱ', 't': 'ㅅ', 'y': 'ㅛ', 'u': 'ㅕ', 'i': 'ㅑ', 'o': 'ㅐ', 'p': 'ㅔ',
+	'a': 'ㅁ', 's': 'ㄴ', 'd': 'ㅇ', 'f': 'ㄹ', 'g': 'ㅎ', 'h': 'ㅗ', 'j': 'ㅓ', 'k': 'ㅏ', 'l': 'ㅣ',
+	'z': 'ㅋ', 'x': 'ㅌ', 'c': 'ㅊ', 'v': 'ㅍ', 'b': 'ㅠ', 'n': 'ㅜ', 'm': 'ㅡ',
+	'Q': 'ㅃ', 'W': 'ㅉ', 'E': 'ㄸ', 'R': 'ㄲ', 'T': 'ㅆ', 'O': 'ㅒ', 'P': 'ㅖ',
+}
+
+func isChoseong(r rune) bool {
+	return indexRune(choseongs, r) >= 0
+}
+
+func isJungseong(r rune) bool {
+	return indexRune(jungseongs, r) >= 0
+}
+
+// 음절의 초성 (한글 음절이 아니면 그대로 반환)
+func choseongOf(r rune) rune {
+	if !isHangulSyllable(r) {
+		return r
+	}
+	return choseongs[(r-hangulBase)/(hangulJungCount*hangulJongCount)]
+}
+
+// 한글 음절을 자모 단위로 분해한다 (편집 거리 계산용, 그 외 문자는 그대로).
+func decomposeJamo(text string) []rune {
+	jamo := []rune{}
+	for _, r := range text {
+		if !isHangulSyllable(r) {
+			jamo = append(jamo, r)
+			continue
+		}
+		offset := r - hangulBase
+		jamo = append(jamo,
+			choseongs[offset/(hangulJungCount*hangulJongCount)],
+			jungseongs[(offset%(hangulJungCount*hangulJongCount))/hangulJongCount])
+		if jong := offset % hangulJongCount; jong > 0 {
+			jamo = append(jamo, jongseongs[jong])
+		}
+	}
+	return jamo
+}
+
+func composeSyllable(cho, jung, jong rune) rune {
+	jongIndex := 0
+	if jong != 0 {
+		jongIndex = indexRune(jongseongs, jong)
+	}
+	return hangulBase + rune((indexRune(choseongs, cho)*hangulJungCount+indexRune(jungseongs, jung))*hangulJongCount+jongIndex)
+}
+
+// 영문 자판으로 입력된 문자열을 두벌식 한글로 변환한다.
+// 변환 결과가 완성된 음절로만 이루어지지 않으면 ok=false.
+func convertDubeolsik(text string) (string, bool) {
+	var out strings.Builder
+	var cho, jung, jong rune
+	complete := true
+
+	flush := func() {
+		switch {
+		case cho != 0 && jung != 0:
+			out.WriteRune(composeSyllable(cho, jung, jong))
+		case cho != 0:
+			out.WriteRune(cho)
+			complete = false
+		case jung != 0:
+			out.WriteRune(jung)
+			complete = false
+		}
+		cho, jung, jong = 0, 0, 0
+	}
+
+	for _, key := range text {
+		if key == ' ' {
+			flush()
+			out.WriteRune(' ')
+			continue
+		}
+		j, ok := dubeolsikKeys[key]
+		if !ok {
+			j, ok = dubeolsikKeys[toLowerASCII(key)]
+		}
+		if !ok {
+			return "", false
+		}
+
+		if isJungseong(j) {
+			switch {
+			case cho != 0 && jung == 0:
+				jung = j
+			case jung != 0 && jong == 0:
+				if combined, ok := compoundVowels[[2]rune{jung, j}]; ok {
+					jung = combined
+				} else {
+					flush()
+					jung = j
+				}
+			case jong != 0:
+				// 받침이 다음 음절의 초성으로 넘어간다 (겹받침은 뒤 자음만 이동)
+				next := jong
+				jong = 0
+				for pair, combined := range compoundFinals {
+					if combined == next {
+						jong, next = pair[0], pair[1]
+						break
+					}
+				}
+				flush()
+				cho, jung = next, j
+			default:
+				flush()
+				jung = j
+			}
+			continue
+		}
+
+		switch {
+		case jung == 0 || cho == 0:
+			// 초성만 있거나 모음만 있는 상태에서는 새 음절 시작
+			flush()
+			cho = j
+		case jong == 0 && indexRune(jongseongs, j) > 0:
+			jong = j
+		case jong != 0:
+			if combined, ok := compoundFinals[[2]rune{jong, j}]; ok {
+				jong = combined
+			} else {
+				flush()
+				cho = j
+			}
+		default:
+			flush()
+			cho = j
+		}
+	}
+	flush()
+
+	return out.String(), complete
+}
+
+func indexRune(list []rune, r rune) int {
+	for i, v := range list {
+		if v == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func toLowerASCII(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + ('a' - 'A')
+	}
+	return r
+}
@@ -0,0 +1,798 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInventoryNotFound = errors.New("장서 점검을 찾을 수 없습니다")
+
+// 한 번에 받을 수 있는 스캔 바코드 수
+const maxInventoryScanBatch = 500
+
+// 장서 점검 (migrations/016_inventory_sessions.sql 참고)
+type InventorySession struct {
+	ID               int        `json:"id"`
+	BranchID         int        `json:"branch_id"`
+	BranchName       string     `json:"branch_name"`
+	FromLocationID   *int       `json:"from_location_id"`
+	FromLocationPath string     `json:"from_location_path,omitempty"`
+	ToLocationID     *int       `json:"to_location_id"`
+	ToLocationPath   string     `json:"to_location_path,omitempty"`
+	Status           string     `json:"status"`
+	Note             string     `json:"note,omitempty"`
+	OpenedBy         string     `json:"opened_by"`
+	OpenedAt         time.Time  `json:"opened_at"`
+	ClosedBy         string     `json:"closed_by,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at"`
+	ScanCount        int        `json:"scan_count"`
+}
+
+// 스캔 한 건의 즉시 판정.
+// result: ok(점검 범위의 대여 가능 복본), wrong_location, borrowed, lost, other_status, unknown(없는 바코드), invalid(형식 오류)
+type InventoryScanResult struct {
+	Barcode    string `json:"barcode"`
+	CopyID     int    `json:"copy_id,omitempty"`
+	Result     string `json:"result"`
+	CopyStatus string `json:"copy_status,omitempty"`
+	Duplicate  bool   `json:"duplicate,omitempty"`
+}
+
+// 점검 결과에 나오는 복본
+type InventoryItem struct {
+	CopyID              int    `json:"copy_id"`
+	Barcode             string `json:"barcode"`
+	BookID              string `json:"book_id"`
+	BookTitle           string `json:"book_title"`
+	CopyNumber          int    `json:"copy_number"`
+	Status              string `json:"status"`
+	BranchID            *int   `json:"branch_id"`
+	BranchName          string `json:"branch_name,omitempty"`
+	LocationID          *int   `json:"location_id"`
+	LocationPath        string `json:"location_path,omitempty"`
+	ScannedLocationID   *int   `json:"scanned_location_id,omitempty"`
+	ScannedLocationPath string `json:"scanned_location_path,omitempty"`
+}
+
+// 점검 결과. 마감 전에는 지금 상태로 계산한 미리 보기이고(Final=false),
+// 마감 후에는 마감 시점에 확정한 대상 복본을 기준으로 한다.
+// 스캔한 복본은 상태(대출 중, 분실, 그 밖의 상태)를 먼저 보고, 대여 가능한 복본만 위치를 따진다.
+type InventoryReport struct {
+	Session       InventorySession `json:"session"`
+	Final         bool             `json:"final"`
+	Expected      int              `json:"expected"`
+	Scanned       int              `json:"scanned"`
+	Found         int              `json:"found"`
+	Missing       []InventoryItem  `json:"missing"`
+	WrongLocation []InventoryItem  `json:"wrong_location"`
+	Borrowed      []InventoryItem  `json:"borrowed"`
+	Lost          []InventoryItem  `json:"lost"`
+	OtherStatus   []InventoryItem  `json:"other_status"`
+	Unknown       []string         `json:"unknown"`
+}
+
+// 점검 범위: 분관 전체(Locations가 nil) 또는 그 분관의 위치 일부
+type inventoryScope struct {
+	BranchID  int
+	Locations map[int]bool
+}
+
+func (s inventoryScope) contains(branchID, locationID *int) bool {
+	if branchID == nil || *branchID != s.BranchID {
+		return false
+	}
+	if s.Locations == nil {
+		return true
+	}
+	return locationID != nil && s.Locations[*locationID]
+}
+
+// 분관 아래 위치를 트리 순서로 펼친 뒤 from~to 구간의 위치와 그 하위 위치를 고른다.
+// 둘 다 없으면 분관 전체(nil), 하나만 있으면 그 위치 하나다.
+func inventoryLocations(byID map[int]*Branch, branchID int, from, to *int) (map[int]bool, error) {
+	if from == nil && to == nil {
+		return nil, nil
+	}
+	if from == nil {
+		from = to
+	}
+	if to == nil {
+		to = from
+	}
+	for _, id := range []int{*from, *to} {
+		node, ok := byID[id]
+		if !ok || node.Level == "branch" {
+			return nil, errLocationNotFound
+		}
+		if branchRoot(byID, id) != branchID {
+			return nil, errLocationBranch
+		}
+	}
+
+	order := []*Branch{}
+	var walk func(nodes []*Branch)
+	walk = func(nodes []*Branch) {
+		for _, node := range nodes {
+			order = append(order, node)
+			walk(node.Children)
+		}
+	}
+	walk(byID[branchID].Children)
+
+	start, end := -1, -1
+	for i, node := range order {
+		if node.ID == *from {
+			start = i
+		}
+		if node.ID == *to {
+			end = i
+		}
+	}
+	if start > end {
+		start, end = end, start
+	}
+
+	scope := map[int]bool{}
+	var mark func(node *Branch)
+	mark = func(node *Branch) {
+		scope[node.ID] = true
+		for _, child := range node.Children {
+			mark(child)
+		}
+	}
+	for _, node := range order[start : end+1] {
+		mark(node)
+	}
+	return scope, nil
+}
+
+// 스캔한 복본 판정 (scanned는 스캔할 때 지정한 위치, 없으면 위치는 범위로만 따진다)
+func classifyInventoryCopy(scope inventoryScope, status string, branchID, locationID, scanned *int) string {
+	switch status {
+	case "available":
+	case "borrowed", "lost":
+		return status
+	default:
+		return "other_status"
+	}
+	if !scope.contains(branchID, locationID) {
+		return "wrong_location"
+	}
+	if scanned != nil && locationID != nil && *scanned != *locationID {
+		return "wrong_location"
+	}
+	return "ok"
+}
+
+const inventorySessionSelect = `SELECT s.id, s.branch_id, s.from_location_id, s.to_location_id, s.status, COALESCE(s.note, ''),
+       s.opened_by, s.opened_at, COALESCE(s.closed_by, ''), s.closed_at,
+       (SELECT COUNT(*) FROM inventory_scans sc WHERE sc.session_id = s.id)
+FROM inventory_sessions s`
+
+var inventorySort = sortSpec{Columns: []string{"s.id"}, Desc: true}
+
+func scanInventorySession(row interface {
+	Scan(dest ...interface{}) error
+}) (InventorySession, error) {
+	var s InventorySession
+	err := row.Scan(&s.ID, &s.BranchID, &s.FromLocationID, &s.ToLocationID, &s.Status, &s.Note,
+		&s.OpenedBy, &s.OpenedAt, &s.ClosedBy, &s.ClosedAt, &s.ScanCount)
+	return s, err
+}
+
+func (s *InventorySession) fillNames(byID map[int]*Branch) {
+	if branch, ok := byID[s.BranchID]; ok {
+		s.BranchName = branch.Name
+	}
+	if s.FromLocationID != nil {
+		s.FromLocationPath = branchPath(byID, *s.FromLocationID)
+	}
+	if s.ToLocationID != nil {
+		s.ToLocationPath = branchPath(byID, *s.ToLocationID)
+	}
+}
+
+func (item *InventoryItem) fillNames(byID map[int]*Branch) {
+	if item.BranchID != nil {
+		if branch, ok := byID[*item.BranchID]; ok {
+			item.BranchName = branch.Name
+		}
+	}
+	if item.LocationID != nil {
+		item.LocationPath = branchPath(byID, *item.LocationID)
+	}
+	if item.ScannedLocationID != nil {
+		item.ScannedLocationPath = branchPath(byID, *item.ScannedLocationID)
+	}
+}
+
+// 점검 ID로 점검과 점검 범위를 읽는다.
+func loadInventorySession(id string) (InventorySession, inventoryScope, map[int]*Branch, error) {
+	session, err := scanInventorySession(db.QueryRow(inventorySessionSelect+" WHERE s.id = ?", id))
+	if err == sql.ErrNoRows {
+		return session, inventoryScope{}, nil, errInventoryNotFound
+	}
+	if err != nil {
+		return session, inventoryScope{}, nil, err
+	}
+	_, byID, err := loadBranchTree()
+	if err != nil {
+		return session, inventoryScope{}, nil, err
+	}
+	session.fillNames(byID)
+	scope := inventoryScope{BranchID: session.BranchID}
+	scope.Locations, err = inventoryLocations(byID, session.BranchID, session.FromLocationID, session.ToLocationID)
+	return session, scope, byID, err
+}
+
+// 점검 범위 조건 (book_copies 별칭 bc)
+func (s inventoryScope) where() (string, []interface{}) {
+	where := " AND bc.branch_id = ?"
+	args := []interface{}{s.BranchID}
+	if s.Locations != nil {
+		placeholders := make([]string, 0, len(s.Locations))
+		for id := range s.Locations {
+			placeholders = append(placeholders, "?")
+			args = append(args, id)
+		}
+		where += " AND bc.location_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	return where, args
+}
+
+const inventoryItemColumns = `bc.id, COALESCE(bc.barcode, ''), bc.book_id, b.title, bc.copy_number, bc.status, bc.branch_id, bc.location_id`
+
+func buildInventoryReport(session InventorySession, scope inventoryScope, byID map[int]*Branch) (InventoryReport, error) {
+	report := InventoryReport{
+		Session: session, Final: session.Status == "closed",
+		Missing: []InventoryItem{}, WrongLocation: []InventoryItem{}, Borrowed: []InventoryItem{},
+		Lost: []InventoryItem{}, OtherStatus: []InventoryItem{}, Unknown: []string{},
+	}
+
+	// 서가에 있어야 할 복본
+	var rows *sql.Rows
+	var err error
+	if report.Final {
+		rows, err = db.Query(`SELECT `+inventoryItemColumns+`
+		                      FROM inventory_expected e
+		                      JOIN book_copies bc ON bc.id = e.copy_id
+		                      JOIN books b ON b.id = bc.book_id
+		                      WHERE e.session_id = ?
+		                      ORDER BY bc.location_id, b.title, bc.copy_number`, session.ID)
+	} else {
+		where, args := scope.where()
+		rows, err = db.Query(`SELECT `+inventoryItemColumns+`
+		                      FROM book_copies bc
+		                      JOIN books b ON b.id = bc.book_id
+		                      WHERE bc.status = 'available'`+where+`
+		                      ORDER BY bc.location_id, b.title, bc.copy_number`, args...)
+	}
+	if err != nil {
+		return report, err
+	}
+	expected := []InventoryItem{}
+	for rows.Next() {
+		var item InventoryItem
+		if err := rows.Scan(&item.CopyID, &item.Barcode, &item.BookID, &item.BookTitle, &item.CopyNumber,
+			&item.Status, &item.BranchID, &item.LocationID); err != nil {
+			rows.Close()
+			return report, err
+		}
+		expected = append(expected, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	// 스캔한 바코드
+	rows, err = db.Query(`SELECT s.barcode, s.location_id, s.copy_id, COALESCE(bc.barcode, ''), COALESCE(bc.book_id, ''),
+	                             COALESCE(b.title, ''), COALESCE(bc.copy_number, 0), COALESCE(bc.status, ''), bc.branch_id, bc.location_id
+	                      FROM inventory_scans s
+	                      LEFT JOIN book_copies bc ON bc.id = s.copy_id
+	                      LEFT JOIN books b ON b.id = bc.book_id
+	                      WHERE s.session_id = ?
+	                      ORDER BY s.id`, session.ID)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	scanned := map[int]bool{}
+	for rows.Next() {
+		var barcode string
+		var copyID *int
+		var item InventoryItem
+		if err := rows.Scan(&barcode, &item.ScannedLocationID, &copyID, &item.Barcode, &item.BookID, &item.BookTitle,
+			&item.CopyNumber, &item.Status, &item.BranchID, &item.LocationID); err != nil {
+			return report, err
+		}
+		report.Scanned++
+		if copyID == nil {
+			report.Unknown = append(report.Unknown, barcode)
+			continue
+		}
+		item.CopyID = *copyID
+		scanned[item.CopyID] = true
+		item.fillNames(byID)
+
+		switch classifyInventoryCopy(scope, item.Status, item.BranchID, item.LocationID, item.ScannedLocationID) {
+		case "wrong_location":
+			report.WrongLocation = append(report.WrongLocation, item)
+		case "borrowed":
+			report.Borrowed = append(report.Borrowed, item)
+		case "lost":
+			report.Lost = append(report.Lost, item)
+		case "other_status":
+			report.OtherStatus = append(report.OtherStatus, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	report.Expected = len(expected)
+	for _, item := range expected {
+		if scanned[item.CopyID] {
+			report.Found++
+			continue
+		}
+		item.fillNames(byID)
+		report.Missing = append(report.Missing, item)
+	}
+	return report, nil
+}
+
+// 관리자: 장서 점검 목록 (최신순, status/branch_id 필터)
+func handleGetInventorySessions(c *gin.Context) {
+	pageReq, err := parsePageRequest(c, "inventory", inventorySort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if status := c.Query("status"); status != "" {
+		where += " AND s.status = ?"
+		args = append(args, status)
+	}
+	if branchID := c.Query("branch_id"); branchID != "" {
+		where += " AND s.branch_id = ?"
+		args = append(args, branchID)
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM inventory_sessions s"+where, args...).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 목록 조회에 실패했습니다"})
+		return
+	}
+
+	_, byID, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 목록 조회에 실패했습니다"})
+		return
+	}
+
+	query := inventorySessionSelect + where
+	if pageReq.Cursor != nil {
+		query += " AND " + inventorySort.after()
+		args = append(args, pageReq.cursorArgs()...)
+	}
+	limitClause, limitArgs := pageReq.limitClause()
+	query += inventorySort.orderBy() + limitClause
+	args = append(args, limitArgs...)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 목록 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	sessions := []InventorySession{}
+	for rows.Next() {
+		s, err := scanInventorySession(rows)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		s.fillNames(byID)
+		sessions = append(sessions, s)
+	}
+
+	count := len(sessions)
+	if count > pageReq.Limit {
+		sessions = sessions[:pageReq.Limit]
+	}
+	c.JSON(http.StatusOK, newPageResponse(sessions, count, total, pageReq, "inventory", func() []string {
+		return []string{strconv.Itoa(sessions[len(sessions)-1].ID)}
+	}))
+}
+
+// 관리자: 장서 점검 시작 (분관 전체, 또는 from_location_id~to_location_id 범위)
+func handleCreateInventorySession(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var req struct {
+		BranchID       int    `json:"branch_id" binding:"required"`
+		FromLocationID *int   `json:"from_location_id"`
+		ToLocationID   *int   `json:"to_location_id"`
+		Note           string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len([]rune(req.Note)) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": map[string]string{"note": "메모는 255자 이하여야 합니다"}})
+		return
+	}
+
+	_, byID, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 시작에 실패했습니다"})
+		return
+	}
+	if node, ok := byID[req.BranchID]; !ok || node.Level != "branch" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errBranchNotFound.Error()})
+		return
+	}
+	if _, err := inventoryLocations(byID, req.BranchID, req.FromLocationID, req.ToLocationID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := db.Exec(`INSERT INTO inventory_sessions (branch_id, from_location_id, to_location_id, note, opened_by)
+	                        VALUES (?, ?, ?, ?, ?)`,
+		req.BranchID, req.FromLocationID, req.ToLocationID, nullIfEmpty(req.Note), actor)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 시작에 실패했습니다"})
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	session, _, _, err := loadInventorySession(strconv.FormatInt(id, 10))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+		return
+	}
+	log.Printf("Admin %s opened inventory session %d for branch %d", actor, id, req.BranchID)
+	c.JSON(http.StatusCreated, session)
+}
+
+// 관리자: 장서 점검 결과 (마감 전이면 미리 보기)
+func handleGetInventoryReport(c *gin.Context) {
+	session, scope, byID, err := loadInventorySession(c.Param("id"))
+	if err == errInventoryNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 결과 조회에 실패했습니다"})
+		return
+	}
+
+	report, err := buildInventoryReport(session, scope, byID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 결과 조회에 실패했습니다"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// 관리자: 스캔한 바코드 넣기 (스캐너에서 여러 건을 모아 보내도 된다).
+// location_id를 주면 그 위치에서 스캔한 것으로 보고 복본의 위치와 비교한다.
+func handleScanInventory(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var req struct {
+		Barcodes   []string `json:"barcodes" binding:"required"`
+		LocationID *int     `json:"location_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if len(req.Barcodes) > maxInventoryScanBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "한 번에 " + strconv.Itoa(maxInventoryScanBatch) + "건까지 보낼 수 있습니다"})
+		return
+	}
+
+	session, scope, byID, err := loadInventorySession(c.Param("id"))
+	if err == errInventoryNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+		return
+	}
+	if req.LocationID != nil {
+		if node, ok := byID[*req.LocationID]; !ok || node.Level == "branch" || branchRoot(byID, node.ID) != session.BranchID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errLocationBranch.Error()})
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 마감과 겹치지 않도록 점검을 잠근다
+	var status string
+	if err := tx.QueryRow("SELECT status FROM inventory_sessions WHERE id = ? FOR UPDATE", session.ID).Scan(&status); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+		return
+	}
+	if status != "open" {
+		c.JSON(http.StatusConflict, gin.H{"error": "진행 중인 점검이 아닙니다", "status": status})
+		return
+	}
+
+	results := []InventoryScanResult{}
+	summary := map[string]int{}
+	for _, raw := range req.Barcodes {
+		code, err := normalizeBarcode(raw)
+		if err != nil {
+			results = append(results, InventoryScanResult{Barcode: raw, Result: "invalid"})
+			summary["invalid"]++
+			continue
+		}
+		r := InventoryScanResult{Barcode: code, Result: "unknown"}
+
+		var copyID, branchID, locationID *int
+		err = tx.QueryRow("SELECT id, status, branch_id, location_id FROM book_copies WHERE barcode = ?", code).
+			Scan(&copyID, &r.CopyStatus, &branchID, &locationID)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+			return
+		}
+		if copyID != nil {
+			r.CopyID = *copyID
+			r.Result = classifyInventoryCopy(scope, r.CopyStatus, branchID, locationID, req.LocationID)
+		}
+
+		result, err := tx.Exec(`INSERT INTO inventory_scans (session_id, barcode, copy_id, location_id, scanned_by)
+		                        VALUES (?, ?, ?, ?, ?)
+		                        ON DUPLICATE KEY UPDATE copy_id = VALUES(copy_id), location_id = VALUES(location_id),
+		                                                scanned_by = VALUES(scanned_by)`,
+			session.ID, code, copyID, req.LocationID, actor)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+			return
+		}
+		// 새로 넣으면 1, 이미 있던 바코드면 0 또는 2
+		if n, _ := result.RowsAffected(); n != 1 {
+			r.Duplicate = true
+		}
+		results = append(results, r)
+		summary[r.Result]++
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "스캔 처리에 실패했습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": session.ID, "results": results, "summary": summary})
+}
+
+// 관리자: 장서 점검 마감. 지금 점검 범위에서 대여 가능한 복본을 서가에 있어야 할 복본으로 확정하고 결과를 돌려준다.
+func handleCloseInventorySession(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	session, scope, byID, err := loadInventorySession(c.Param("id"))
+	if err == errInventoryNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow("SELECT status FROM inventory_sessions WHERE id = ? FOR UPDATE", session.ID).Scan(&status); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+	if status != "open" {
+		c.JSON(http.StatusConflict, gin.H{"error": "진행 중인 점검이 아닙니다", "status": status})
+		return
+	}
+
+	where, args := scope.where()
+	_, err = tx.Exec(`INSERT INTO inventory_expected (session_id, copy_id)
+	                  SELECT ?, bc.id FROM book_copies bc WHERE bc.status = 'available'`+where,
+		append([]interface{}{session.ID}, args...)...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+	if _, err := tx.Exec(`UPDATE inventory_sessions SET status = 'closed', closed_by = ?, closed_at = NOW()
+	                      WHERE id = ?`, actor, session.ID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 마감에 실패했습니다"})
+		return
+	}
+
+	now := time.Now()
+	session.Status, session.ClosedBy, session.ClosedAt = "closed", actor, &now
+	report, err := buildInventoryReport(session, scope, byID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusOK, gin.H{"message": "장서 점검이 마감되었습니다", "session": session})
+		return
+	}
+
+	log.Printf("Admin %s closed inventory session %d (%d expected, %d found, %d missing)",
+		actor, session.ID, report.Expected, report.Found, len(report.Missing))
+	c.JSON(http.StatusOK, report)
+}
+
+// 관리자: 진행 중인 장서 점검 취소 (스캔 기록은 남는다)
+func handleCancelInventorySession(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	result, err := db.Exec("UPDATE inventory_sessions SET status = 'cancelled', closed_by = ?, closed_at = NOW() WHERE id = ? AND status = 'open'",
+		actor, c.Param("id"))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "장서 점검 취소에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "진행 중인 점검이 아닙니다"})
+		return
+	}
+
+	log.Printf("Admin %s cancelled inventory session %s", actor, c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{"message": "장서 점검이 취소되었습니다"})
+}
+
+// 관리자: 마감한 점검에서 찾지 못한 복본을 분실 처리.
+// copy_ids를 주면 그중 미발견 복본만, 생략하면 미발견 복본 전체. 그사이 상태가 바뀐 복본은 건너뛴다.
+func handleMarkInventoryMissingLost(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var req struct {
+		CopyIDs []int `json:"copy_ids"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var sessionID int
+	var status string
+	err = tx.QueryRow("SELECT id, status FROM inventory_sessions WHERE id = ? FOR UPDATE", c.Param("id")).Scan(&sessionID, &status)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": errInventoryNotFound.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+		return
+	}
+	if status != "closed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "마감한 점검에서만 분실 처리할 수 있습니다", "status": status})
+		return
+	}
+
+	rows, err := tx.Query(`SELECT bc.id FROM inventory_expected e
+	                       JOIN book_copies bc ON bc.id = e.copy_id
+	                       WHERE e.session_id = ? AND bc.status = 'available'
+	                         AND NOT EXISTS (SELECT 1 FROM inventory_scans s WHERE s.session_id = e.session_id AND s.copy_id = e.copy_id)
+	                       ORDER BY bc.id
+	                       FOR UPDATE`, sessionID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+		return
+	}
+	missing := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			log.Printf("Scan error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+			return
+		}
+		missing = append(missing, id)
+	}
+	rows.Close()
+
+	targets, skipped := missing, []int{}
+	if len(req.CopyIDs) > 0 {
+		eligible := map[int]bool{}
+		for _, id := range missing {
+			eligible[id] = true
+		}
+		targets = []int{}
+		for _, id := range req.CopyIDs {
+			if eligible[id] {
+				targets = append(targets, id)
+				eligible[id] = false
+			} else {
+				skipped = append(skipped, id)
+			}
+		}
+	}
+
+	note := "장서 점검 #" + strconv.Itoa(sessionID) + " 미발견"
+	for _, id := range targets {
+		if _, err := tx.Exec("UPDATE book_copies SET status = 'lost' WHERE id = ?", id); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+			return
+		}
+		if err := recordCopyStatus(tx, int64(id), "available", "lost", "missing", note, actor); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분실 처리에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s marked %d missing copies lost (inventory session %d)", actor, len(targets), sessionID)
+	c.JSON(http.StatusOK, gin.H{"marked": len(targets), "copy_ids": targets, "skipped": skipped})
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errISBNFormat   = errors.New("ISBN은 10자리 또는 13자리여야 합니다")
+	errISBNChecksum = errors.New("ISBN 체크 숫자가 올바르지 않습니다")
+	errISBNPrefix   = errors.New("ISBN-13은 978 또는 979로 시작해야 합니다")
+)
+
+// 하이픈과 공백을 제거하고 체크 숫자를 검증한 뒤 ISBN-13으로 변환한다.
+func normalizeISBN(raw string) (string, error) {
+	digits := isbnDigits(raw)
+	if digits == "" {
+		return "", errISBNFormat
+	}
+
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", errISBNChecksum
+		}
+		return isbn10To13(digits), nil
+	case 13:
+		if strings.ContainsRune(digits, 'X') {
+			return "", errISBNFormat
+		}
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", errISBNPrefix
+		}
+		if isbn13CheckDigit(digits[:12]) != digits[12] {
+			return "", errISBNChecksum
+		}
+		return digits, nil
+	default:
+		return "", errISBNFormat
+	}
+}
+
+// 구분 문자를 뺀 숫자열 (X는 ISBN-10의 마지막 자리에만 허용, 그 외 문자가 있으면 빈 문자열)
+func isbnDigits(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('X')
+		case r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+	digits := b.String()
+	if i := strings.IndexRune(digits, 'X'); i >= 0 && (i != len(digits)-1 || len(digits) != 10) {
+		return ""
+	}
+	return digits
+}
+
+func validISBN10(digits string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		value := int(digits[i] - '0')
+		if digits[i] == 'X' {
+			value = 10
+		}
+		sum += value * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func isbn13CheckDigit(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(first12[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isbn10To13(isbn10 string) string {
+	first12 := "978" + isbn10[:9]
+	return first12 + string(isbn13CheckDigit(first12))
+}
+
+// 978로 시작하는 ISBN-13만 ISBN-10으로 변환할 수 있다.
+func isbn13To10(isbn13 string) string {
+	if !strings.HasPrefix(isbn13, "978") {
+		return ""
+	}
+	body := isbn13[3:12]
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X"
+	}
+	return body + string(byte('0'+check))
+}
+
+// 저장된 ISBN이 하이픈이나 ISBN-10으로 남아 있어도 일치하도록 비교하는 조건과 인자
+func isbnMatchClause(isbn13 string) (string, []interface{}) {
+	isbn10 := isbn13To10(isbn13)
+	if isbn10 == "" {
+		isbn10 = isbn13
+	}
+	clause := "REPLACE(REPLACE(UPPER(isbn), '-', ''), ' ', '') IN (?, ?)"
+	return clause, []interface{}{isbn13, isbn10}
+}
+
+// ISBN(10자리 또는 13자리, 하이픈 허용)으로 도서 조회
+func handleGetBookByISBN(c *gin.Context) {
+	isbn13, err := normalizeISBN(c.Param("isbn"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clause, args := isbnMatchClause(isbn13)
+	var bookID string
+	err = db.QueryRow("SELECT id FROM books WHERE "+clause+" ORDER BY created_at LIMIT 1", args...).Scan(&bookID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found", "isbn13": isbn13})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	book, err := fetchBook(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"isbn13": isbn13, "isbn10": isbn13To10(isbn13), "book": book})
+}
+
+// ISBN 점검 결과 한 건
+type ISBNIssue struct {
+	BookID     string `json:"book_id"`
+	Title      string `json:"title"`
+	ISBN       string `json:"isbn"`
+	Problem    string `json:"problem"`
+	Message    string `json:"message"`
+	Normalized string `json:"normalized,omitempty"`
+	// duplicate일 때 같은 ISBN을 가진 다른 도서
+	DuplicateOf []string `json:"duplicate_of,omitempty"`
+}
+
+// 관리자: 저장된 ISBN 점검 보고서
+// problem: invalid(형식/체크 숫자 오류), not_normalized(유효하지만 ISBN-13 숫자열이 아님), duplicate(정규화하면 같은 ISBN)
+func handleISBNReport(c *gin.Context) {
+	rows, err := db.Query("SELECT id, title, isbn FROM books WHERE isbn IS NOT NULL AND isbn <> '' ORDER BY created_at, id")
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ISBN 점검에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	issues := []ISBNIssue{}
+	// 정규화한 ISBN별 도서 (처음 나온 순서 유지)
+	byISBN := map[string][]string{}
+	order := []string{}
+	titles := map[string]ISBNIssue{}
+	checked := 0
+	for rows.Next() {
+		var id, title, isbn string
+		if err := rows.Scan(&id, &title, &isbn); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		checked++
+
+		normalized, err := normalizeISBN(isbn)
+		if err != nil {
+			issues = append(issues, ISBNIssue{BookID: id, Title: title, ISBN: isbn, Problem: "invalid", Message: err.Error()})
+			continue
+		}
+		if normalized != isbn {
+			issues = append(issues, ISBNIssue{BookID: id, Title: title, ISBN: isbn, Problem: "not_normalized",
+				Message: "ISBN-13 숫자열로 저장되어 있지 않습니다", Normalized: normalized})
+		}
+		if _, ok := byISBN[normalized]; !ok {
+			order = append(order, normalized)
+		}
+		byISBN[normalized] = append(byISBN[normalized], id)
+		titles[id] = ISBNIssue{BookID: id, Title: title, ISBN: isbn, Normalized: normalized}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Rows error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ISBN 점검에 실패했습니다"})
+		return
+	}
+
+	for _, normalized := range order {
+		ids := byISBN[normalized]
+		if len(ids) < 2 {
+			continue
+		}
+		for _, id := range ids {
+			issue := titles[id]
+			issue.Problem = "duplicate"
+			issue.Message = "같은 ISBN을 가진 도서가 있습니다"
+			for _, other := range ids {
+				if other != id {
+					issue.DuplicateOf = append(issue.DuplicateOf, other)
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	summary := map[string]int{"invalid": 0, "not_normalized": 0, "duplicate": 0}
+	for _, issue := range issues {
+		summary[issue.Problem]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checked": checked, "summary": summary, "issues": issues})
+}
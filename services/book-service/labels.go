@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 한 번에 출력하는 최대 라벨 수
+	maxLabelCopies = 1000
+
+	// A4 라벨지 3열 8행 (70 x 37.125mm)
+	labelSheetWidth  = 595.28
+	labelSheetHeight = 841.89
+	labelColumns     = 3
+	labelRows        = 8
+	labelPadding     = 8.0
+
+	// Zebra 203dpi, 2 x 1인치 라벨
+	zplLabelWidth  = 406
+	zplLabelHeight = 203
+)
+
+// 라벨 한 장에 들어가는 복본 정보
+type copyLabel struct {
+	CopyID      int64
+	CopyNumber  int
+	Barcode     string
+	BookID      string
+	Title       string
+	Author      string
+	ClassNumber string
+}
+
+// 청구기호: 분류기호 / 도서기호(저자 첫 글자 + 서명 첫 글자) / 복본기호
+func (l copyLabel) callNumber() []string {
+	lines := []string{}
+	if l.ClassNumber != "" {
+		lines = append(lines, l.ClassNumber)
+	}
+	mark := ""
+	if r, _ := utf8.DecodeRuneInString(l.Author); r != utf8.RuneError {
+		mark += strings.ToUpper(string(r))
+	}
+	if r, _ := utf8.DecodeRuneInString(l.Title); r != utf8.RuneError {
+		mark += string(r)
+	}
+	if mark != "" {
+		lines = append(lines, mark)
+	}
+	if l.CopyNumber > 1 {
+		lines = append(lines, "c."+strconv.Itoa(l.CopyNumber))
+	}
+	return lines
+}
+
+// 관리자: 복본 라벨 출력
+// copy_ids 또는 book_ids(해당 도서의 모든 복본)로 고르며,
+// type은 barcode(기본, 서명+바코드) | spine(청구기호) | both, format은 pdf(기본) | zpl.
+// skip은 PDF에서 이미 사용한 라벨 칸 수로, 쓰다 남은 라벨지에 이어서 출력할 때 사용한다.
+func handlePrintLabels(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var req struct {
+		CopyIDs []int64  `json:"copy_ids"`
+		BookIDs []string `json:"book_ids"`
+		Type    string   `json:"type"`
+		Format  string   `json:"format"`
+		Skip    int      `json:"skip"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Type == "" {
+		req.Type = "barcode"
+	}
+	if req.Format == "" {
+		req.Format = "pdf"
+	}
+
+	errs := map[string]string{}
+	if len(req.CopyIDs) == 0 && len(req.BookIDs) == 0 {
+		errs["copy_ids"] = "copy_ids 또는 book_ids가 필요합니다"
+	}
+	if req.Type != "barcode" && req.Type != "spine" && req.Type != "both" {
+		errs["type"] = "barcode, spine, both 중 하나여야 합니다"
+	}
+	if req.Format != "pdf" && req.Format != "zpl" {
+		errs["format"] = "pdf 또는 zpl이어야 합니다"
+	}
+	if req.Skip < 0 || req.Skip >= labelColumns*labelRows {
+		errs["skip"] = fmt.Sprintf("0에서 %d 사이여야 합니다", labelColumns*labelRows-1)
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	labels, err := loadCopyLabels(req.CopyIDs, req.BookIDs)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "라벨 생성에 실패했습니다"})
+		return
+	}
+	if len(labels) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
+		return
+	}
+	if len(labels) > maxLabelCopies {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("한 번에 %d개까지 출력할 수 있습니다", maxLabelCopies)})
+		return
+	}
+
+	filename := "labels-" + time.Now().Format("20060102-150405")
+	if req.Format == "zpl" {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.zpl"`)
+		c.Data(http.StatusOK, "application/zpl; charset=utf-8", []byte(renderLabelsZPL(labels, req.Type)))
+	} else {
+		c.Header("Content-Disposition", `inline; filename="`+filename+`.pdf"`)
+		c.Data(http.StatusOK, "application/pdf", renderLabelsPDF(labels, req.Type, req.Skip))
+	}
+
+	log.Printf("Admin %v printed %d %s labels (%s)", adminID, len(labels), req.Type, req.Format)
+}
+
+// 라벨에 쓸 복본과 서지 (바코드가 없는 복본은 이때 부여한다)
+func loadCopyLabels(copyIDs []int64, bookIDs []string) ([]copyLabel, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	if len(copyIDs) > 0 {
+		conditions = append(conditions, "bc.id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(copyIDs)), ", ")+")")
+		for _, id := range copyIDs {
+			args = append(args, id)
+		}
+	}
+	if len(bookIDs) > 0 {
+		conditions = append(conditions, "bc.book_id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(bookIDs)), ", ")+")")
+		for _, id := range bookIDs {
+			args = append(args, id)
+		}
+	}
+
+	rows, err := db.Query(`SELECT bc.id, bc.copy_number, COALESCE(bc.barcode, ''), b.id, b.title, b.author,
+	                              COALESCE((SELECT MIN(bs.subject_code) FROM book_subjects bs WHERE bs.book_id = b.id), '')
+	                       FROM book_copies bc
+	                       JOIN books b ON b.id = bc.book_id
+	                       WHERE `+strings.Join(conditions, " OR ")+`
+	                       ORDER BY b.title, b.id, bc.copy_number
+	                       LIMIT ?`, append(args, maxLabelCopies+1)...)
+	if err != nil {
+		return nil, err
+	}
+	labels := []copyLabel{}
+	for rows.Next() {
+		var l copyLabel
+		if err := rows.Scan(&l.CopyID, &l.CopyNumber, &l.Barcode, &l.BookID, &l.Title, &l.Author, &l.ClassNumber); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range labels {
+		if labels[i].Barcode == "" {
+			if labels[i].Barcode, err = assignCopyBarcode(db, labels[i].CopyID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return labels, nil
+}
+
+// 출력 순서대로 펼친 라벨 종류 목록
+func labelKinds(labelType string) []string {
+	if labelType == "both" {
+		return []string{"spine", "barcode"}
+	}
+	return []string{labelType}
+}
+
+// 한글은 전각, ASCII는 반각으로 보고 폭을 어림한다.
+func estimateTextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 128 {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * size
+}
+
+func truncateToWidth(text string, size, max float64) string {
+	if estimateTextWidth(text, size) <= max {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && estimateTextWidth(string(runes)+"…", size) > max {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// PDF 문서를 만든다. 한글은 글꼴을 포함하지 않고 뷰어의 한국어 기본 글꼴(HYGoThic-Medium)을 사용한다.
+func renderLabelsPDF(labels []copyLabel, labelType string, skip int) []byte {
+	pages := []string{}
+	var content strings.Builder
+	slot := skip
+	perPage := labelColumns * labelRows
+	cellWidth := labelSheetWidth / labelColumns
+	cellHeight := labelSheetHeight / labelRows
+
+	for _, label := range labels {
+		for _, kind := range labelKinds(labelType) {
+			if slot == perPage {
+				pages = append(pages, content.String())
+				content.Reset()
+				slot = 0
+			}
+			x := float64(slot%labelColumns) * cellWidth
+			y := labelSheetHeight - float64(slot/labelColumns+1)*cellHeight
+			if kind == "spine" {
+				drawSpineLabel(&content, label, x, y, cellWidth, cellHeight)
+			} else {
+				drawBarcodeLabel(&content, label, x, y, cellWidth, cellHeight)
+			}
+			slot++
+		}
+	}
+	pages = append(pages, content.String())
+
+	doc := &pdfDocument{}
+	catalog := doc.reserve()
+	pagesID := doc.reserve()
+	helvetica := doc.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	descriptor := doc.add("<< /Type /FontDescriptor /FontName /HYGoThic-Medium /Flags 6 /FontBBox [-6 -145 1003 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	cidFont := doc.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HYGoThic-Medium "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Korea1) /Supplement 1 >> /FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>", descriptor))
+	korean := doc.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /HYGoThic-Medium /Encoding /UniKS-UCS2-H "+
+		"/DescendantFonts [%d 0 R] >>", cidFont))
+
+	kids := []string{}
+	for _, stream := range pages {
+		contentID := doc.add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+		pageID := doc.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, labelSheetWidth, labelSheetHeight, helvetica, korean, contentID))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+	doc.set(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	doc.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	return doc.bytes(catalog)
+}
+
+// 서명, 저자, Code 128 바코드와 사람이 읽을 수 있는 번호
+func drawBarcodeLabel(out *strings.Builder, label copyLabel, x, y, width, height float64) {
+	inner := width - 2*labelPadding
+	pdfKoreanText(out, truncateToWidth(label.Title, 8, inner), 8, x+labelPadding, y+height-labelPadding-8)
+	pdfKoreanText(out, truncateToWidth(label.Author, 7, inner), 7, x+labelPadding, y+height-labelPadding-18)
+
+	widths, err := encodeCode128(label.Barcode)
+	if err != nil {
+		pdfKoreanText(out, label.Barcode, 9, x+labelPadding, y+labelPadding+20)
+		return
+	}
+	modules := float64(code128Modules(widths))
+	module := inner / modules
+	if module > 1 {
+		module = 1
+	}
+	barX := x + (width-modules*module)/2 + code128QuietZone*module
+	barY := y + labelPadding + 12
+	barHeight := height - 2*labelPadding - 12 - 24
+	for i, w := range widths {
+		if i%2 == 0 {
+			fmt.Fprintf(out, "%.3f %.3f %.3f %.3f re\n", barX, barY, float64(w)*module, barHeight)
+		}
+		barX += float64(w) * module
+	}
+	out.WriteString("f\n")
+
+	textWidth := float64(len(label.Barcode)) * 9 * 0.6
+	fmt.Fprintf(out, "BT /F1 9 Tf %.2f %.2f Td (%s) Tj ET\n", x+(width-textWidth)/2, y+labelPadding+1, pdfEscape(label.Barcode))
+}
+
+// 청구기호를 가운데 정렬로 줄마다 출력
+func drawSpineLabel(out *strings.Builder, label copyLabel, x, y, width, height float64) {
+	const size = 14.0
+	lines := label.callNumber()
+	lineHeight := size * 1.3
+	top := y + (height+float64(len(lines))*lineHeight)/2 - size
+	for i, line := range lines {
+		line = truncateToWidth(line, size, width-2*labelPadding)
+		pdfKoreanText(out, line, size, x+(width-estimateTextWidth(line, size))/2, top-float64(i)*lineHeight)
+	}
+}
+
+func pdfKoreanText(out *strings.Builder, text string, size, x, y float64) {
+	var hex strings.Builder
+	for _, r := range text {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&hex, "%04X", r)
+	}
+	fmt.Fprintf(out, "BT /F2 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, hex.String())
+}
+
+func pdfEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
+
+// 객체 번호와 xref 표를 관리하는 최소한의 PDF 작성기
+type pdfDocument struct {
+	objects []string
+}
+
+func (d *pdfDocument) reserve() int {
+	d.objects = append(d.objects, "")
+	return len(d.objects)
+}
+
+func (d *pdfDocument) add(object string) int {
+	id := d.reserve()
+	d.set(id, object)
+	return id
+}
+
+func (d *pdfDocument) set(id int, object string) {
+	d.objects[id-1] = object
+}
+
+func (d *pdfDocument) bytes(rootID int) []byte {
+	var out strings.Builder
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, object := range d.objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, rootID, xref)
+	return []byte(out.String())
+}
+
+// Zebra ZPL II. 한글을 출력하려면 프린터에 한글 글꼴을 내려받고 LABEL_ZPL_FONT(예: E:NANUMGOTHIC.TTF)로 지정한다.
+func renderLabelsZPL(labels []copyLabel, labelType string) string {
+	font := func(size int) string {
+		if name := getEnv("LABEL_ZPL_FONT", ""); name != "" {
+			return fmt.Sprintf("^A@N,%d,%d,%s", size, size, name)
+		}
+		return fmt.Sprintf("^A0N,%d,%d", size, size)
+	}
+	inner := zplLabelWidth - 32
+
+	var out strings.Builder
+	for _, label := range labels {
+		for _, kind := range labelKinds(labelType) {
+			fmt.Fprintf(&out, "^XA\n^CI28\n^PW%d\n^LL%d\n", zplLabelWidth, zplLabelHeight)
+			if kind == "spine" {
+				lines := label.callNumber()
+				fmt.Fprintf(&out, "^FO16,30%s^FB%d,%d,8,C^FH^FD%s^FS\n",
+					font(40), inner, len(lines), zplEscape(strings.Join(lines, "\\&")))
+			} else {
+				fmt.Fprintf(&out, "^FO16,12%s^FB%d,1,0,L^FH^FD%s^FS\n", font(22), inner, zplEscape(label.Title))
+				fmt.Fprintf(&out, "^FO16,40^BY2^BCN,100,Y,N,N^FH^FD%s^FS\n", zplEscape(label.Barcode))
+			}
+			out.WriteString("^XZ\n")
+		}
+	}
+	return out.String()
+}
+
+// ^FH로 16진 이스케이프를 켠 필드에서 제어 문자(^, ~)와 이스케이프 문자(_)를 바꾼다.
+func zplEscape(text string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(text)
+}
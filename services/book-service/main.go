@@ -1,28 +1,43 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 )
 
 type Book struct {
-	ID               string  `json:"id"`
-	Title            string  `json:"title"`
-	Author           string  `json:"author"`
-	Publisher        string  `json:"publisher"`
-	Year             int     `json:"year"`
-	ISBN             string  `json:"isbn"`
-	Description      string  `json:"description"`
-	Price            float64 `json:"price"`
-	CoverImage       string  `json:"cover_image"`
-	TotalCopies      int     `json:"total_copies"`
-	AvailableCopies  int     `json:"available_copies"`
+	ID               string    `json:"id"`
+	Title            string    `json:"title"`
+	Author           string    `json:"author"`
+	Publisher        string    `json:"publisher"`
+	Year             int       `json:"year"`
+	ISBN             string    `json:"isbn"`
+	Description      string    `json:"description"`
+	Price            float64   `json:"price"`
+	CoverImage       string    `json:"cover_image"`
+	CreatedAt        time.Time `json:"created_at"`
+	TotalCopies      int       `json:"total_copies"`
+	AvailableCopies  int       `json:"available_copies"`
+	// q 검색 시 일치 부분을 <mark>로 표시한 필드별 조각
+	Highlights       map[string]string `json:"highlights,omitempty"`
+	// 상세 조회에서만 채우는 주제 분류와 기여자
+	Subjects         []SubjectRef      `json:"subjects,omitempty"`
+	Contributors     []Contributor     `json:"contributors,omitempty"`
+	// 상세 조회에서만 채우는 분관별 소장 현황
+	Holdings         []BranchHolding   `json:"holdings,omitempty"`
 }
 
 type BookCopy struct {
@@ -30,12 +45,37 @@ type BookCopy struct {
 	BookID       string `json:"book_id"`
 	CopyNumber   int    `json:"copy_number"`
 	Status       string `json:"status"`
+	// 소속 분관과 세부 위치(층 또는 서가). location은 자유 형식 위치 메모다.
+	BranchID     *int   `json:"branch_id"`
+	BranchName   string `json:"branch_name"`
+	LocationID   *int   `json:"location_id"`
+	LocationPath string `json:"location_path"`
 	Location     string `json:"location"`
 	AcquiredDate string `json:"acquired_date"`
 	Notes        string `json:"notes"`
+	Barcode      string `json:"barcode"`
 }
 
-var db *sql.DB
+// 분관 트리에서 복본의 분관 이름과 위치 경로를 채운다.
+func (copy *BookCopy) fillPlacement(byID map[int]*Branch) {
+	if copy.BranchID != nil {
+		if branch, ok := byID[*copy.BranchID]; ok {
+			copy.BranchName = branch.Name
+		}
+	}
+	if copy.LocationID != nil {
+		copy.LocationPath = branchPath(byID, *copy.LocationID)
+	}
+}
+
+var (
+	db          *sql.DB
+	redisClient *redis.Client
+	ctx         = context.Background()
+)
+
+// 역할별 권한 캐시 유효 시간
+const permissionCacheTTL = 5 * time.Minute
 
 func main() {
 	// .env.local 파일 로드 (파일이 없어도 에러 무시)
@@ -48,6 +88,7 @@ func main() {
 	dbUser := getEnv("DB_USER", "root")
 	dbPassword := getEnv("DB_PASSWORD", "rootpassword")
 	dbName := getEnv("DB_NAME", "library")
+	redisAddr := getEnv("REDIS_ADDR", "redis-central.default.svc.cluster.local:6379")
 
 	// MariaDB 연결
 	var err error
@@ -64,6 +105,37 @@ func main() {
 	}
 	log.Println("Successfully connected to MariaDB")
 
+	// Redis 연결 (세션 확인 및 권한 캐시)
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: redisAddr,
+	})
+
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	log.Println("Successfully connected to Redis")
+
+	// 복본 바코드 형식과 기존 복본 바코드 부여
+	itemBarcode = loadBarcodeFormat()
+	if err := backfillCopyBarcodes(); err != nil {
+		log.Fatalf("Failed to assign copy barcodes: %v", err)
+	}
+
+	// 표지 저장소
+	coverStore, err = newBlobStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure cover store: %v", err)
+	}
+
+	// 검색 동의어 사전과 색인 생성 (이후 변경은 주기적으로 반영)
+	if err := loadSynonyms(); err != nil {
+		log.Fatalf("Failed to load search synonyms: %v", err)
+	}
+	if err := loadSearchIndex(); err != nil {
+		log.Fatalf("Failed to build search index: %v", err)
+	}
+	go refreshSearchIndexPeriodically()
+
 	// Gin 라우터 설정
 	router := gin.Default()
 
@@ -77,14 +149,84 @@ func main() {
 
 	// 도서 API
 	router.GET("/books", handleGetBooks)
+	router.GET("/books/suggest", handleSuggestBooks)
+	router.GET("/books/isbn/:isbn", handleGetBookByISBN)
 	router.GET("/books/:id", handleGetBook)
 	router.GET("/books/:id/copies", handleGetBookCopies)
+	router.GET("/books/:id/marcxml", handleGetBookMARC)
+	router.GET("/books/:id/cover/:version/:size", handleGetCover)
+	router.GET("/subjects", handleGetSubjects)
+	router.GET("/subjects/:code", handleGetSubject)
+	router.GET("/authors", handleSearchAuthors)
+	router.GET("/authors/:id", handleGetAuthor)
+	router.GET("/branches", handleGetBranches)
+
+	// 도서 서지 관리 API (관리자용)
+	router.POST("/admin/books", authMiddleware(), requirePermission("books:write"), handleCreateBook)
+	router.PUT("/admin/books/:id", authMiddleware(), requirePermission("books:write"), handleReplaceBook)
+	router.PATCH("/admin/books/:id", authMiddleware(), requirePermission("books:write"), handlePatchBook)
+	router.DELETE("/admin/books/:id", authMiddleware(), requirePermission("books:write"), handleDeleteBook)
+	router.GET("/admin/books/isbn-report", authMiddleware(), requirePermission("books:write"), handleISBNReport)
+	router.GET("/admin/books/duplicates", authMiddleware(), requirePermission("books:write"), handleGetDuplicates)
+	router.POST("/admin/books/duplicates/scan", authMiddleware(), requirePermission("books:write"), handleStartDuplicateScan)
+	router.GET("/admin/books/duplicates/scans/:scan_id", authMiddleware(), requirePermission("books:write"), handleGetDuplicateScan)
+	router.POST("/admin/books/duplicates/:id/dismiss", authMiddleware(), requirePermission("books:write"), handleDismissDuplicate)
+	router.POST("/admin/books/:id/merge", authMiddleware(), requirePermission("books:write"), handleMergeBooks)
+	router.POST("/admin/books/import", authMiddleware(), requirePermission("books:write"), handleStartImport)
+	router.GET("/admin/books/import", authMiddleware(), requirePermission("books:write"), handleGetImportJobs)
+	router.GET("/admin/books/import/:job_id", authMiddleware(), requirePermission("books:write"), handleGetImportJob)
+	router.GET("/admin/books/import/:job_id/errors", authMiddleware(), requirePermission("books:write"), handleGetImportErrors)
+	router.GET("/admin/books/export", authMiddleware(), requirePermission("books:write"), handleExportBooks)
+	router.POST("/admin/books/:id/cover", authMiddleware(), requirePermission("books:write"), handleUploadCover)
+	router.DELETE("/admin/books/:id/cover", authMiddleware(), requirePermission("books:write"), handleDeleteCover)
+	router.POST("/admin/books/:id/cover/import", authMiddleware(), requirePermission("books:write"), handleImportCover)
+	router.POST("/admin/covers/import-remote", authMiddleware(), requirePermission("books:write"), handleImportRemoteCovers)
+	router.PUT("/admin/books/:id/subjects", authMiddleware(), requirePermission("books:write"), handleSetBookSubjects)
+	router.PUT("/admin/books/:id/contributors", authMiddleware(), requirePermission("books:write"), handleSetBookContributors)
+	router.POST("/admin/authors", authMiddleware(), requirePermission("books:write"), handleCreateAuthor)
+	router.PUT("/admin/authors/:id", authMiddleware(), requirePermission("books:write"), handleUpdateAuthor)
+	router.DELETE("/admin/authors/:id", authMiddleware(), requirePermission("books:write"), handleDeleteAuthor)
+	router.POST("/admin/subjects", authMiddleware(), requirePermission("books:write"), handleCreateSubject)
+	router.PUT("/admin/subjects/:code", authMiddleware(), requirePermission("books:write"), handleUpdateSubject)
+	router.DELETE("/admin/subjects/:code", authMiddleware(), requirePermission("books:write"), handleDeleteSubject)
+	router.POST("/admin/marc/import", authMiddleware(), requirePermission("books:write"), handleImportMARC)
+	router.GET("/admin/marc/export", authMiddleware(), requirePermission("books:write"), handleExportMARC)
+
+	// 분관/층/서가 관리 API (관리자용)
+	router.POST("/admin/branches", authMiddleware(), requirePermission("branches:write"), handleCreateBranch)
+	router.PUT("/admin/branches/:id", authMiddleware(), requirePermission("branches:write"), handleUpdateBranch)
+	router.DELETE("/admin/branches/:id", authMiddleware(), requirePermission("branches:write"), handleDeleteBranch)
 
 	// 복본 관리 API (관리자용)
-	router.GET("/admin/copies", handleGetAllCopies)
-	router.POST("/admin/copies", handleAddCopy)
-	router.PUT("/admin/copies/:id", handleUpdateCopy)
-	router.DELETE("/admin/copies/:id", handleDeleteCopy)
+	router.GET("/admin/copies", authMiddleware(), requirePermission("copies:read"), handleGetAllCopies)
+	router.POST("/admin/copies", authMiddleware(), requirePermission("copies:write"), handleAddCopy)
+	router.PUT("/admin/copies/:id", authMiddleware(), requirePermission("copies:write"), handleUpdateCopy)
+	router.DELETE("/admin/copies/:id", authMiddleware(), requirePermission("copies:write"), handleDeleteCopy)
+	router.POST("/admin/copies/withdraw", authMiddleware(), requirePermission("copies:write"), handleWithdrawCopies)
+	router.GET("/admin/copies/weeding", authMiddleware(), requirePermission("copies:read"), handleGetWeedingCandidates)
+	router.POST("/admin/copies/labels", authMiddleware(), requirePermission("copies:write"), handlePrintLabels)
+	router.GET("/admin/copies/statuses", authMiddleware(), requirePermission("copies:read"), handleGetCopyStatuses)
+	router.GET("/admin/copies/:id/history", authMiddleware(), requirePermission("copies:read"), handleGetCopyHistory)
+
+	// 분관 간 이관 API (관리자용)
+	router.GET("/admin/transfers", authMiddleware(), requirePermission("transfers:read"), handleGetTransfers)
+	router.GET("/admin/transfers/manifest", authMiddleware(), requirePermission("transfers:read"), handleGetTransferManifest)
+	router.POST("/admin/transfers", authMiddleware(), requirePermission("transfers:write"), handleCreateTransfer)
+	router.POST("/admin/transfers/:id/ship", authMiddleware(), requirePermission("transfers:write"), handleShipTransfer)
+	router.POST("/admin/transfers/:id/receive", authMiddleware(), requirePermission("transfers:write"), handleReceiveTransfer)
+	router.POST("/admin/transfers/:id/cancel", authMiddleware(), requirePermission("transfers:write"), handleCancelTransfer)
+
+	// 장서 점검 API (관리자용)
+	router.GET("/admin/inventory", authMiddleware(), requirePermission("inventory:read"), handleGetInventorySessions)
+	router.GET("/admin/inventory/:id", authMiddleware(), requirePermission("inventory:read"), handleGetInventoryReport)
+	router.POST("/admin/inventory", authMiddleware(), requirePermission("inventory:write"), handleCreateInventorySession)
+	router.POST("/admin/inventory/:id/scans", authMiddleware(), requirePermission("inventory:write"), handleScanInventory)
+	router.POST("/admin/inventory/:id/close", authMiddleware(), requirePermission("inventory:write"), handleCloseInventorySession)
+	router.POST("/admin/inventory/:id/cancel", authMiddleware(), requirePermission("inventory:write"), handleCancelInventorySession)
+	router.POST("/admin/inventory/:id/mark-lost", authMiddleware(), requirePermission("inventory:write"), handleMarkInventoryMissingLost)
+
+	// 복본 바코드 조회 (대출 데스크)
+	router.GET("/copies/barcode/:code", authMiddleware(), requirePermission("copies:read"), handleGetCopyByBarcode)
 
 	// 서버 시작
 	port := getEnv("BOOK_SERVICE_PORT", getEnv("PORT", "8082"))
@@ -94,40 +236,204 @@ func main() {
 	}
 }
 
+// 도서 목록 정렬 기준 (기본: newest, q가 있으면 relevance)
+var bookSorts = map[string]sortSpec{
+	"newest":       {Columns: []string{"b.created_at", "b.id"}, Desc: true},
+	"title":        {Columns: []string{"b.title", "b.id"}},
+	"author":       {Columns: []string{"b.author", "b.id"}},
+	"year":         {Columns: []string{"COALESCE(b.year, 0)", "b.id"}, Desc: true},
+	"availability": {Columns: []string{"available_copies", "b.id"}, Desc: true, Aggregate: true},
+}
+
+// 관련도 정렬은 SQL이 아니라 검색 색인 점수로 정렬한다 (점수, ID).
+var relevanceSort = sortSpec{Columns: []string{"score", "b.id"}, Desc: true}
+
 func handleGetBooks(c *gin.Context) {
 	// 쿼리 파라미터 읽기
-	search := c.Query("search")      // 제목 검색
-	author := c.Query("author")      // 저자 필터
-	publisher := c.Query("publisher") // 출판사 필터
-	year := c.Query("year")          // 연도 필터
+	q := strings.TrimSpace(c.Query("q")) // 통합 검색 (제목, 저자, 출판사, 설명, ISBN)
+	search := c.Query("search")          // 제목 검색
+	author := c.Query("author")          // 저자 필터 (역자, 편자 등 모든 기여자)
+	publisher := c.Query("publisher")    // 출판사 필터
+	year := c.Query("year")              // 연도 필터
+	subject := c.Query("subject")        // 주제 분류 필터 (하위 분류 포함)
+	branch := c.Query("branch")          // 분관 필터 (코드 또는 ID, 복본 수도 그 분관 기준)
 
-	// 동적 쿼리 생성 - 복본 정보 포함
-	query := `SELECT b.id, b.title, b.author, b.publisher, b.year, b.isbn, b.description, b.price, b.cover_image,
-	          COALESCE(COUNT(bc.id), 0) as total_copies,
-	          COALESCE(SUM(CASE WHEN bc.status = 'available' THEN 1 ELSE 0 END), 0) as available_copies
-	          FROM books b
-	          LEFT JOIN book_copies bc ON b.id = bc.book_id
-	          WHERE 1=1`
+	// 정렬 기준과 방향 (order를 생략하면 정렬 기준의 기본 방향)
+	defaultSort := "newest"
+	if q != "" {
+		defaultSort = "relevance"
+	}
+	sortName := c.DefaultQuery("sort", defaultSort)
+	spec, ok := bookSorts[sortName]
+	if sortName == "relevance" {
+		if q == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "relevance 정렬은 q 검색어가 필요합니다"})
+			return
+		}
+		spec, ok = relevanceSort, true
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 정렬 기준입니다"})
+		return
+	}
+	switch c.Query("order") {
+	case "asc":
+		spec.Desc = false
+	case "desc":
+		spec.Desc = true
+	case "":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order는 asc 또는 desc여야 합니다"})
+		return
+	}
+	if sortName == "relevance" && !spec.Desc {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "relevance 정렬은 내림차순만 지원합니다"})
+		return
+	}
+	sortKey := sortName + ":asc"
+	if spec.Desc {
+		sortKey = sortName + ":desc"
+	}
+
+	pageReq, err := parsePageRequest(c, sortKey, spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
+
+	facetReq, err := parseFacetRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	where := " WHERE 1=1"
 	args := []interface{}{}
 
+	// 검색 색인에서 후보 도서를 찾고, 나머지 필터는 DB에서 적용
+	var scores map[string]float64
+	var highlightQuery string
+	if q != "" {
+		result := searchIdx.searchFuzzy(q, maxSearchHits)
+		hits := result.Hits
+		highlightQuery = strings.Join(result.Queries, " ")
+		if len(hits) == 0 {
+			c.JSON(http.StatusOK, BookPageResponse{
+				PageResponse: PageResponse{Items: []Book{}, Page: pageReq.Page, Limit: pageReq.Limit},
+				Facets:       emptyFacets(facetReq),
+			})
+			return
+		}
+		scores = make(map[string]float64, len(hits))
+		placeholders := make([]string, len(hits))
+		for i, hit := range hits {
+			scores[hit.ID] = hit.Score
+			placeholders[i] = "?"
+			args = append(args, hit.ID)
+		}
+		where += " AND b.id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
 	if search != "" {
-		query += " AND b.title LIKE ?"
+		where += " AND b.title LIKE ?"
 		args = append(args, "%"+search+"%")
 	}
 	if author != "" {
-		query += " AND b.author LIKE ?"
-		args = append(args, "%"+author+"%")
+		where += ` AND (b.author LIKE ? OR EXISTS (SELECT 1 FROM book_contributors ct JOIN authors a ON a.id = ct.author_id
+		           WHERE ct.book_id = b.id AND (a.name LIKE ? OR a.variant_names LIKE ?)))`
+		args = append(args, "%"+author+"%", "%"+author+"%", "%"+author+"%")
 	}
 	if publisher != "" {
-		query += " AND b.publisher LIKE ?"
+		where += " AND b.publisher LIKE ?"
 		args = append(args, "%"+publisher+"%")
 	}
 	if year != "" {
-		query += " AND b.year = ?"
+		where += " AND b.year = ?"
 		args = append(args, year)
 	}
+	if subject != "" {
+		codes, err := subjectSubtree(subject)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch books"})
+			return
+		}
+		if len(codes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 분류입니다"})
+			return
+		}
+		where += " AND EXISTS (SELECT 1 FROM book_subjects bs WHERE bs.book_id = b.id AND bs.subject_code IN (" +
+			strings.TrimSuffix(strings.Repeat("?, ", len(codes)), ", ") + "))"
+		for _, code := range codes {
+			args = append(args, code)
+		}
+	}
 
-	query += " GROUP BY b.id ORDER BY b.created_at DESC"
+	// 분관을 지정하면 그 분관에 복본이 있는 도서만, 복본 수도 그 분관 것만 센다
+	copyJoin := ""
+	copyJoinArgs := []interface{}{}
+	if branch != "" {
+		branchID, err := resolveBranch(branch)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 분관입니다"})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch books"})
+			return
+		}
+		where += " AND EXISTS (SELECT 1 FROM book_copies fb WHERE fb.book_id = b.id AND fb.branch_id = ? AND fb.status <> 'withdrawn')"
+		args = append(args, branchID)
+		copyJoin = " AND bc.branch_id = ?"
+		copyJoinArgs = append(copyJoinArgs, branchID)
+	}
+
+	// 패싯 버킷은 자기 패싯의 선택을 뺀 조건으로 센다
+	var facets map[string][]FacetBucket
+	if len(facetReq.Names) > 0 {
+		facets, err = countFacets(facetReq, where, args)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch books"})
+			return
+		}
+	}
+	facetWhere, facetArgs := facetReq.where("")
+	where += facetWhere
+	args = append(args, facetArgs...)
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM books b"+where, args...).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch books"})
+		return
+	}
+
+	// 동적 쿼리 생성 - 복본 정보 포함
+	query := `SELECT b.id, b.title, b.author, b.publisher, COALESCE(b.year, 0) AS year, COALESCE(b.isbn, '') AS isbn, b.description, b.price, b.cover_image,
+	          b.created_at,
+	          COALESCE(COUNT(bc.id), 0) as total_copies,
+	          COALESCE(SUM(CASE WHEN bc.status = 'available' THEN 1 ELSE 0 END), 0) as available_copies
+	          FROM books b
+	          LEFT JOIN book_copies bc ON b.id = bc.book_id AND bc.status <> 'withdrawn'` + copyJoin + where
+	args = append(copyJoinArgs, args...)
+	if sortName == "relevance" {
+		// 후보 전체를 읽어 점수 순으로 정렬한 뒤 페이지를 자른다 (최대 maxSearchHits건)
+		query += " GROUP BY b.id"
+	} else {
+		if pageReq.Cursor != nil && !spec.Aggregate {
+			query += " AND " + spec.after()
+			args = append(args, pageReq.cursorArgs()...)
+		}
+		query += " GROUP BY b.id"
+		if pageReq.Cursor != nil && spec.Aggregate {
+			query += " HAVING " + spec.after()
+			args = append(args, pageReq.cursorArgs()...)
+		}
+		limitClause, limitArgs := pageReq.limitClause()
+		query += spec.orderBy() + limitClause
+		args = append(args, limitArgs...)
+	}
 
 	log.Printf("Executing query: %s with args: %v", query, args)
 
@@ -152,6 +458,7 @@ func handleGetBooks(c *gin.Context) {
 			&book.Description,
 			&book.Price,
 			&book.CoverImage,
+			&book.CreatedAt,
 			&book.TotalCopies,
 			&book.AvailableCopies,
 		)
@@ -168,18 +475,132 @@ func handleGetBooks(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Retrieved %d books (filtered)", len(books))
-	c.JSON(http.StatusOK, books)
+	if sortName == "relevance" {
+		books = pageByRelevance(books, scores, pageReq)
+	}
+
+	count := len(books)
+	if count > pageReq.Limit {
+		books = books[:pageReq.Limit]
+	}
+	if highlightQuery != "" {
+		for i := range books {
+			books[i].Highlights = highlightBook(books[i], highlightQuery)
+		}
+	}
+
+	log.Printf("Retrieved %d of %d books (filtered)", len(books), total)
+	c.JSON(http.StatusOK, BookPageResponse{
+		PageResponse: newPageResponse(books, count, total, pageReq, sortKey, func() []string {
+			last := books[len(books)-1]
+			if sortName == "relevance" {
+				return []string{strconv.FormatFloat(scores[last.ID], 'g', -1, 64), last.ID}
+			}
+			return bookSortValues(last, sortName)
+		}),
+		Facets: facets,
+	})
+}
+
+// 커서에 담을 정렬 값 (bookSorts의 컬럼 순서와 같아야 함)
+func bookSortValues(book Book, sortName string) []string {
+	switch sortName {
+	case "title":
+		return []string{book.Title, book.ID}
+	case "author":
+		return []string{book.Author, book.ID}
+	case "year":
+		return []string{strconv.Itoa(book.Year), book.ID}
+	case "availability":
+		return []string{strconv.Itoa(book.AvailableCopies), book.ID}
+	default:
+		return []string{book.CreatedAt.Format("2006-01-02 15:04:05.999999"), book.ID}
+	}
+}
+
+// 점수 내림차순(동점이면 ID 오름차순)으로 정렬하고 요청한 페이지를 limit+1건까지 반환한다.
+func pageByRelevance(books []Book, scores map[string]float64, pageReq pageRequest) []Book {
+	sort.Slice(books, func(i, j int) bool {
+		si, sj := scores[books[i].ID], scores[books[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return books[i].ID < books[j].ID
+	})
+
+	start := 0
+	if pageReq.Cursor != nil {
+		cursorScore, err := strconv.ParseFloat(pageReq.Cursor[0], 64)
+		if err != nil {
+			return []Book{}
+		}
+		cursorID := pageReq.Cursor[1]
+		start = sort.Search(len(books), func(i int) bool {
+			score := scores[books[i].ID]
+			return score < cursorScore || (score == cursorScore && books[i].ID > cursorID)
+		})
+	} else {
+		start = (pageReq.Page - 1) * pageReq.Limit
+	}
+
+	if start >= len(books) {
+		return []Book{}
+	}
+	end := minInt(len(books), start+pageReq.Limit+1)
+	return books[start:end]
 }
 
 func handleGetBook(c *gin.Context) {
 	bookID := c.Param("id")
 
-	query := `SELECT b.id, b.title, b.author, b.publisher, b.year, b.isbn, b.description, b.price, b.cover_image,
+	book, err := fetchBook(bookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// 병합으로 없어진 도서는 남은 도서로 보낸다 (상대 경로라 게이트웨이를 거쳐도 맞는 주소가 된다)
+			mergedInto, err := redirectedBookID(bookID)
+			if err != nil {
+				log.Printf("Database error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+				return
+			}
+			if mergedInto != "" {
+				c.Redirect(http.StatusMovedPermanently, url.PathEscape(mergedInto))
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	book.Subjects, err = fetchBookSubjects(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	book.Holdings, err = fetchBranchHoldings(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	log.Printf("Retrieved book: %s (Total: %d, Available: %d)", book.Title, book.TotalCopies, book.AvailableCopies)
+	c.JSON(http.StatusOK, book)
+}
+
+// 복본 집계를 포함한 도서 한 권 조회
+func fetchBook(bookID string) (Book, error) {
+	query := `SELECT b.id, b.title, b.author, b.publisher, COALESCE(b.year, 0) AS year, COALESCE(b.isbn, '') AS isbn, b.description, b.price, b.cover_image,
+	          b.created_at,
 	          COALESCE(COUNT(bc.id), 0) as total_copies,
 	          COALESCE(SUM(CASE WHEN bc.status = 'available' THEN 1 ELSE 0 END), 0) as available_copies
 	          FROM books b
-	          LEFT JOIN book_copies bc ON b.id = bc.book_id
+	          LEFT JOIN book_copies bc ON b.id = bc.book_id AND bc.status <> 'withdrawn'
 	          WHERE b.id = ?
 	          GROUP BY b.id`
 	var book Book
@@ -193,29 +614,31 @@ func handleGetBook(c *gin.Context) {
 		&book.Description,
 		&book.Price,
 		&book.CoverImage,
+		&book.CreatedAt,
 		&book.TotalCopies,
 		&book.AvailableCopies,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
-			return
-		}
-		log.Printf("Database error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
-		return
+		return book, err
 	}
-
-	log.Printf("Retrieved book: %s (Total: %d, Available: %d)", book.Title, book.TotalCopies, book.AvailableCopies)
-	c.JSON(http.StatusOK, book)
+	book.Contributors, err = fetchBookContributors(bookID)
+	return book, err
 }
 
 func handleGetBookCopies(c *gin.Context) {
 	bookID := c.Param("id")
 
-	query := `SELECT id, book_id, copy_number, status, location, acquired_date, COALESCE(notes, '') as notes
+	_, branches, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book copies"})
+		return
+	}
+
+	query := `SELECT id, book_id, copy_number, status, branch_id, location_id, location, acquired_date, COALESCE(notes, '') as notes,
+	          COALESCE(barcode, '') as barcode
 	          FROM book_copies
-	          WHERE book_id = ?
+	          WHERE book_id = ? AND status <> 'withdrawn'
 	          ORDER BY copy_number`
 
 	rows, err := db.Query(query, bookID)
@@ -234,14 +657,18 @@ func handleGetBookCopies(c *gin.Context) {
 			&copy.BookID,
 			&copy.CopyNumber,
 			&copy.Status,
+			&copy.BranchID,
+			&copy.LocationID,
 			&copy.Location,
 			&copy.AcquiredDate,
 			&copy.Notes,
+			&copy.Barcode,
 		)
 		if err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
+		copy.fillPlacement(branches)
 		copies = append(copies, copy)
 	}
 
@@ -254,7 +681,7 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -265,23 +692,199 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization token"})
+			c.Abort()
+			return
+		}
+
+		if len(token) > 7 && token[:7] == "Bearer " {
+			token = token[7:]
+		}
+
+		sessionKey := "session:" + token
+		username, err := redisClient.Get(ctx, sessionKey).Result()
+		if err != nil {
+			if err == redis.Nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+				c.Abort()
+				return
+			}
+			log.Printf("Redis error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+			c.Abort()
+			return
+		}
+
+		// 사용자 역할 및 상태 조회 (username으로 조회)
+		var role, status string
+		query := "SELECT role, status FROM users WHERE username = ?"
+		err = db.QueryRow(query, username).Scan(&role, &status)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user role"})
+			c.Abort()
+			return
+		}
+
+		// 정지된 계정의 세션은 유효하지 않음
+		if status != "active" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", username)
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// 역할에 지정된 권한이 있는지 확인 (authMiddleware 다음에 사용)
+// requirePermission, getRolePermissions, permissionSet은 book-service, borrow-service, user-service에
+// 같은 코드로 들어 있다 (서비스마다 Go 모듈이 따로여서 공유 패키지가 없다).
+// 세 서비스가 Redis 캐시 키(role_permissions:<역할>)를 함께 쓰므로 고칠 때는 세 곳을 똑같이 고친다.
+func requirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
+			c.Abort()
+			return
+		}
+
+		permissions, err := getRolePermissions(role.(string))
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "권한 확인에 실패했습니다"})
+			c.Abort()
+			return
+		}
+
+		if !permissions[permission] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "권한이 없습니다", "permission": permission})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// 역할별 권한 목록 조회 (Redis 캐시 우선, 없으면 DB 조회 후 캐시)
+func getRolePermissions(role string) (map[string]bool, error) {
+	cacheKey := "role_permissions:" + role
+	cached, err := redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		return permissionSet(strings.Split(cached, ",")), nil
+	}
+	if err != redis.Nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	rows, err := db.Query("SELECT permission FROM role_permissions WHERE role = ?", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := redisClient.Set(ctx, cacheKey, strings.Join(names, ","), permissionCacheTTL).Err(); err != nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	return permissionSet(names), nil
+}
+
+func permissionSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// 복본 목록 정렬 기준: 도서 제목, 도서 ID, 복본 번호 순
+var copySort = sortSpec{Columns: []string{"b.title", "bc.book_id", "bc.copy_number"}}
+
 // 관리자: 모든 복본 조회 (책 정보와 함께)
 func handleGetAllCopies(c *gin.Context) {
-	bookID := c.Query("book_id") // 선택적 필터
+	bookID := c.Query("book_id")     // 선택적 필터
+	branchID := c.Query("branch_id") // 분관 필터
+	status := c.Query("status")      // 상태 필터 (available, borrowed 등)
+	if _, ok := copyStatuses[status]; status != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "알 수 없는 상태입니다: " + status})
+		return
+	}
 
-	query := `SELECT bc.id, bc.book_id, bc.copy_number, bc.status, bc.location, bc.acquired_date,
-	          COALESCE(bc.notes, '') as notes, b.title, b.author
-	          FROM book_copies bc
-	          JOIN books b ON bc.book_id = b.id
-	          WHERE 1=1`
+	pageReq, err := parsePageRequest(c, "copies", copySort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
+
+	where := " WHERE 1=1"
 	args := []interface{}{}
 
 	if bookID != "" {
-		query += " AND bc.book_id = ?"
+		where += " AND bc.book_id = ?"
 		args = append(args, bookID)
 	}
+	if branchID != "" {
+		where += " AND bc.branch_id = ?"
+		args = append(args, branchID)
+	}
+	if status != "" {
+		where += " AND bc.status = ?"
+		args = append(args, status)
+	}
+	// 제적한 복본은 include_withdrawn=true이거나 제적 상태로 거를 때만 함께 보여 준다
+	if c.Query("include_withdrawn") != "true" && status != "withdrawn" {
+		where += " AND bc.status <> 'withdrawn'"
+	}
 
-	query += " ORDER BY b.title, bc.copy_number"
+	_, branches, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch copies"})
+		return
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM book_copies bc"+where, args...).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch copies"})
+		return
+	}
+
+	query := `SELECT bc.id, bc.book_id, bc.copy_number, bc.status, bc.branch_id, bc.location_id, bc.location, bc.acquired_date,
+	          COALESCE(bc.notes, '') as notes, COALESCE(bc.barcode, '') as barcode, b.title, b.author,
+	          bc.withdrawn_at, COALESCE(bc.withdrawn_reason, ''), COALESCE(bc.withdrawn_note, '')
+	          FROM book_copies bc
+	          JOIN books b ON bc.book_id = b.id` + where
+	if pageReq.Cursor != nil {
+		query += " AND " + copySort.after()
+		args = append(args, pageReq.cursorArgs()...)
+	}
+	limitClause, limitArgs := pageReq.limitClause()
+	query += copySort.orderBy() + limitClause
+	args = append(args, limitArgs...)
 
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -295,6 +898,10 @@ func handleGetAllCopies(c *gin.Context) {
 		BookCopy
 		BookTitle  string `json:"book_title"`
 		BookAuthor string `json:"book_author"`
+		// 제적한 복본의 제적일, 사유, 메모
+		WithdrawnAt     *string `json:"withdrawn_at,omitempty"`
+		WithdrawnReason string  `json:"withdrawn_reason,omitempty"`
+		WithdrawnNote   string  `json:"withdrawn_note,omitempty"`
 	}
 
 	copies := []CopyWithBook{}
@@ -305,21 +912,36 @@ func handleGetAllCopies(c *gin.Context) {
 			&copy.BookID,
 			&copy.CopyNumber,
 			&copy.Status,
+			&copy.BranchID,
+			&copy.LocationID,
 			&copy.Location,
 			&copy.AcquiredDate,
 			&copy.Notes,
+			&copy.Barcode,
 			&copy.BookTitle,
 			&copy.BookAuthor,
+			&copy.WithdrawnAt,
+			&copy.WithdrawnReason,
+			&copy.WithdrawnNote,
 		)
 		if err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
+		copy.fillPlacement(branches)
 		copies = append(copies, copy)
 	}
 
-	log.Printf("Retrieved %d copies", len(copies))
-	c.JSON(http.StatusOK, copies)
+	count := len(copies)
+	if count > pageReq.Limit {
+		copies = copies[:pageReq.Limit]
+	}
+
+	log.Printf("Retrieved %d of %d copies", len(copies), total)
+	c.JSON(http.StatusOK, newPageResponse(copies, count, total, pageReq, "copies", func() []string {
+		last := copies[len(copies)-1]
+		return []string{last.BookTitle, last.BookID, strconv.Itoa(last.CopyNumber)}
+	}))
 }
 
 // 관리자: 복본 추가
@@ -328,9 +950,13 @@ func handleAddCopy(c *gin.Context) {
 		BookID       string `json:"book_id" binding:"required"`
 		CopyNumber   int    `json:"copy_number" binding:"required"`
 		Status       string `json:"status"`
+		BranchID     *int   `json:"branch_id"`   // 생략하면 기본 분관
+		LocationID   *int   `json:"location_id"` // 분관의 층 또는 서가
 		Location     string `json:"location"`
 		AcquiredDate string `json:"acquired_date"`
 		Notes        string `json:"notes"`
+		// 기존 라벨을 그대로 쓸 때만 지정 (생략하면 자동 부여)
+		Barcode string `json:"barcode"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -342,11 +968,55 @@ func handleAddCopy(c *gin.Context) {
 	if req.Status == "" {
 		req.Status = "available"
 	}
+	if !containsString(initialCopyStatuses, req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "새 복본의 상태는 이용 가능, 이동 중, 수선 중 중 하나여야 합니다"})
+		return
+	}
+	if req.Barcode != "" {
+		code, err := normalizeBarcode(req.Barcode)
+		if err == nil {
+			err = itemBarcode.verify(code)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var used int
+		if err := db.QueryRow("SELECT COUNT(*) FROM book_copies WHERE barcode = ?", code).Scan(&used); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
+			return
+		}
+		if used > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 바코드입니다"})
+			return
+		}
+		req.Barcode = code
+	}
 
-	query := `INSERT INTO book_copies (book_id, copy_number, status, location, acquired_date, notes)
-	          VALUES (?, ?, ?, ?, ?, ?)`
+	branchID, locationID, err := resolveCopyPlacement(req.BranchID, req.LocationID)
+	if err == errBranchNotFound || err == errLocationNotFound || err == errLocationBranch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
+		return
+	}
 
-	result, err := db.Exec(query, req.BookID, req.CopyNumber, req.Status, req.Location, req.AcquiredDate, req.Notes)
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO book_copies (book_id, copy_number, status, branch_id, location_id, location, acquired_date, notes, barcode)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(query, req.BookID, req.CopyNumber, req.Status, nullIfZero(branchID), locationID, req.Location, req.AcquiredDate, req.Notes, nullIfEmpty(req.Barcode))
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
@@ -354,16 +1024,43 @@ func handleAddCopy(c *gin.Context) {
 	}
 
 	id, _ := result.LastInsertId()
+	barcode := req.Barcode
+	if barcode == "" {
+		barcode, err = assignCopyBarcode(tx, id)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "바코드 부여에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := recordCopyStatus(tx, id, "", req.Status, "acquired", "", c.GetString("user_id")); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 추가에 실패했습니다"})
+		return
+	}
+
 	log.Printf("Added copy %d for book %s", id, req.BookID)
-	c.JSON(http.StatusOK, gin.H{"message": "복본이 추가되었습니다", "id": id})
+	c.JSON(http.StatusOK, gin.H{"message": "복본이 추가되었습니다", "id": id, "barcode": barcode})
 }
 
 // 관리자: 복본 수정
+// 상태를 바꿀 때는 허용된 전이인지 확인하고 사유 코드(reason)를 받아 이력에 남긴다.
 func handleUpdateCopy(c *gin.Context) {
 	copyID := c.Param("id")
 
 	var req struct {
 		Status       string `json:"status"`
+		Reason       string `json:"reason"`
+		ReasonNote   string `json:"reason_note"`
+		BranchID     *int   `json:"branch_id"`   // 생략하면 현재 분관 유지
+		LocationID   *int   `json:"location_id"` // 생략하면 위치 없음
 		Location     string `json:"location"`
 		AcquiredDate string `json:"acquired_date"`
 		Notes        string `json:"notes"`
@@ -374,64 +1071,143 @@ func handleUpdateCopy(c *gin.Context) {
 		return
 	}
 
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var bookID, current string
+	var currentBranch sql.NullInt64
+	err = tx.QueryRow("SELECT book_id, status, branch_id FROM book_copies WHERE id = ? FOR UPDATE", copyID).Scan(&bookID, &current, &currentBranch)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
+		return
+	}
+
+	// 상태를 비워 두면 현재 상태를 유지한다
+	if req.Status == "" {
+		req.Status = current
+	}
+	changed := req.Status != current
+	if changed {
+		if req.Reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "상태를 바꾸려면 사유(reason)가 필요합니다"})
+			return
+		}
+		message, err := validateCopyTransition(tx, bookID, copyID, current, req.Status, req.Reason)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
+			return
+		}
+		if message != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": message, "status": current, "allowed": copyTransitions[current]})
+			return
+		}
+	}
+
+	if req.BranchID == nil && req.LocationID == nil && currentBranch.Valid {
+		branch := int(currentBranch.Int64)
+		req.BranchID = &branch
+	}
+	branchID, locationID, err := resolveCopyPlacement(req.BranchID, req.LocationID)
+	if err == errBranchNotFound || err == errLocationNotFound || err == errLocationBranch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
+		return
+	}
+
 	query := `UPDATE book_copies
-	          SET status = ?, location = ?, acquired_date = ?, notes = ?
+	          SET status = ?, branch_id = ?, location_id = ?, location = ?, acquired_date = ?, notes = ?
 	          WHERE id = ?`
 
-	result, err := db.Exec(query, req.Status, req.Location, req.AcquiredDate, req.Notes, copyID)
-	if err != nil {
+	if _, err := tx.Exec(query, req.Status, nullIfZero(branchID), locationID, req.Location, req.AcquiredDate, req.Notes, copyID); err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
+	if changed {
+		id, _ := strconv.ParseInt(copyID, 10, 64)
+		if err := recordCopyStatus(tx, id, current, req.Status, req.Reason, truncateRunes(req.ReasonNote, 255), c.GetString("user_id")); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 수정에 실패했습니다"})
 		return
 	}
 
+	if changed {
+		log.Printf("Copy %s status %s -> %s (%s)", copyID, current, req.Status, req.Reason)
+	}
 	log.Printf("Updated copy %s", copyID)
 	c.JSON(http.StatusOK, gin.H{"message": "복본이 수정되었습니다"})
 }
 
-// 관리자: 복본 삭제
+// 관리자: 복본 제적 (기록을 남기는 삭제, reason/note/withdrawn_date는 쿼리 파라미터)
 func handleDeleteCopy(c *gin.Context) {
-	copyID := c.Param("id")
-
-	// 대여 중인 복본인지 확인
-	var status string
-	err := db.QueryRow("SELECT status FROM book_copies WHERE id = ?", copyID).Scan(&status)
+	actor := c.GetString("user_id")
+	copyID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
-			return
-		}
-		log.Printf("Database error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 확인에 실패했습니다"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
 		return
 	}
 
-	if status == "borrowed" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "대여 중인 복본은 삭제할 수 없습니다"})
+	// 행을 지우지 않고 제적 처리한다 (사유를 생략하면 기타)
+	reason, note, date := c.DefaultQuery("reason", "other"), c.Query("note"), c.Query("withdrawn_date")
+	if errs := validateWithdrawal(&reason, &note, &date); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM book_copies WHERE id = ?", copyID)
+	tx, err := db.Begin()
 	if err != nil {
-		log.Printf("Database error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 삭제에 실패했습니다"})
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 제적에 실패했습니다"})
 		return
 	}
+	defer tx.Rollback()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	message, err := withdrawCopy(tx, copyID, reason, note, date, actor)
+	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
 		return
 	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 제적에 실패했습니다"})
+		return
+	}
+	if message != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": message})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 제적에 실패했습니다"})
+		return
+	}
 
-	log.Printf("Deleted copy %s", copyID)
-	c.JSON(http.StatusOK, gin.H{"message": "복본이 삭제되었습니다"})
+	log.Printf("Withdrew copy %d (%s)", copyID, reason)
+	c.JSON(http.StatusOK, gin.H{"message": "복본이 제적되었습니다", "withdrawn_date": date, "reason": reason})
 }
 
 func getEnv(key, defaultValue string) string {
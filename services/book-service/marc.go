@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ISO 2709 구분 문자
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+	marcLeaderLength      = 24
+	marcDirectoryEntry    = 12
+)
+
+const marcXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+type marcSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcDataField struct {
+	Tag       string         `xml:"tag,attr"`
+	Ind1      string         `xml:"ind1,attr"`
+	Ind2      string         `xml:"ind2,attr"`
+	Subfields []marcSubfield `xml:"subfield"`
+}
+
+type marcControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MARC21 레코드 (MARCXML 구조와 같다)
+type marcRecord struct {
+	XMLName       xml.Name           `xml:"record"`
+	Leader        string             `xml:"leader"`
+	ControlFields []marcControlField `xml:"controlfield"`
+	DataFields    []marcDataField    `xml:"datafield"`
+}
+
+type marcCollection struct {
+	XMLName xml.Name     `xml:"collection"`
+	Xmlns   string       `xml:"xmlns,attr,omitempty"`
+	Records []marcRecord `xml:"record"`
+}
+
+func (r *marcRecord) control(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func (r *marcRecord) fields(tags ...string) []marcDataField {
+	found := []marcDataField{}
+	for _, f := range r.DataFields {
+		for _, tag := range tags {
+			if f.Tag == tag {
+				found = append(found, f)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// 첫 번째 해당 서브필드 값
+func (f marcDataField) sub(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+func (f marcDataField) subs(code string) []string {
+	values := []string{}
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			values = append(values, s.Value)
+		}
+	}
+	return values
+}
+
+// 파일 형식을 판별해 레코드를 읽는다 ('<'로 시작하면 MARCXML, 아니면 ISO 2709).
+// 레코드 단위 오류는 errs에 (레코드 순번, 오류)로 담고 나머지 레코드는 계속 읽는다.
+func parseMARC(data []byte) (records []marcRecord, errs map[int]error, err error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\xef\xbb\xbf")
+	if len(trimmed) == 0 {
+		return nil, nil, errors.New("빈 파일입니다")
+	}
+	if trimmed[0] == '<' {
+		records, err := parseMARCXML(trimmed)
+		return records, map[int]error{}, err
+	}
+	records, errs = parseISO2709(trimmed)
+	return records, errs, nil
+}
+
+func parseMARCXML(data []byte) ([]marcRecord, error) {
+	var collection marcCollection
+	if err := xml.Unmarshal(data, &collection); err == nil {
+		return collection.Records, nil
+	}
+	// 단일 <record> 문서
+	var record marcRecord
+	if err := xml.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("MARCXML 형식이 올바르지 않습니다: %v", err)
+	}
+	return []marcRecord{record}, nil
+}
+
+func parseISO2709(data []byte) ([]marcRecord, map[int]error) {
+	records := []marcRecord{}
+	errs := map[int]error{}
+	index := 0
+	for _, raw := range bytes.Split(data, []byte{marcRecordTerminator}) {
+		raw = bytes.TrimLeft(raw, "\r\n")
+		if len(raw) == 0 {
+			continue
+		}
+		record, err := parseISO2709Record(raw)
+		if err != nil {
+			errs[index] = err
+		}
+		records = append(records, record)
+		index++
+	}
+	return records, errs
+}
+
+// 레코드 종단 문자를 뺀 ISO 2709 레코드 하나를 읽는다.
+func parseISO2709Record(raw []byte) (marcRecord, error) {
+	var record marcRecord
+	if len(raw) < marcLeaderLength {
+		return record, errors.New("리더가 24바이트보다 짧습니다")
+	}
+	record.Leader = string(raw[:marcLeaderLength])
+	if raw[9] != 'a' {
+		return record, errors.New("UTF-8(리더 09='a') 레코드만 지원합니다")
+	}
+	base, err := strconv.Atoi(string(raw[12:17]))
+	if err != nil || base <= marcLeaderLength || base > len(raw) {
+		return record, errors.New("데이터 시작 위치가 올바르지 않습니다")
+	}
+
+	directory := raw[marcLeaderLength : base-1]
+	if len(directory)%marcDirectoryEntry != 0 {
+		return record, errors.New("디렉토리 길이가 올바르지 않습니다")
+	}
+	body := raw[base:]
+	for i := 0; i < len(directory); i += marcDirectoryEntry {
+		entry := directory[i : i+marcDirectoryEntry]
+		tag := string(entry[:3])
+		length, err1 := strconv.Atoi(string(entry[3:7]))
+		start, err2 := strconv.Atoi(string(entry[7:12]))
+		if err1 != nil || err2 != nil || start+length > len(body) || length == 0 {
+			return record, fmt.Errorf("%s 필드 위치가 올바르지 않습니다", tag)
+		}
+		field := bytes.TrimSuffix(body[start:start+length], []byte{marcFieldTerminator})
+		if !utf8.Valid(field) {
+			return record, fmt.Errorf("%s 필드가 UTF-8이 아닙니다", tag)
+		}
+
+		if strings.HasPrefix(tag, "00") {
+			record.ControlFields = append(record.ControlFields, marcControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+		if len(field) < 2 {
+			return record, fmt.Errorf("%s 필드에 지시기호가 없습니다", tag)
+		}
+		df := marcDataField{Tag: tag, Ind1: string(field[0]), Ind2: string(field[1])}
+		for _, part := range bytes.Split(field[2:], []byte{marcSubfieldDelimiter}) {
+			if len(part) == 0 {
+				continue
+			}
+			df.Subfields = append(df.Subfields, marcSubfield{Code: string(part[0]), Value: string(part[1:])})
+		}
+		record.DataFields = append(record.DataFields, df)
+	}
+	return record, nil
+}
+
+// MARC 레코드에서 읽은 서지 정보
+type marcBook struct {
+	Title        string
+	Publisher    string
+	Year         int
+	ISBN         string
+	RawISBN      string
+	ISBNError    error
+	Description  string
+	Price        float64
+	CoverImage   string
+	Contributors []ContributorInput
+	KDC          []string
+	DDC          []string
+}
+
+// 700 $4 관계 코드와 $e 관계어 (한국어 표기 포함)
+var marcRelators = map[string]string{
+	"aut": "author", "trl": "translator", "edt": "editor", "ill": "illustrator",
+	"author": "author", "translator": "translator", "editor": "editor", "illustrator": "illustrator",
+	"지음": "author", "저": "author", "글": "author",
+	"옮김": "translator", "역": "translator", "번역": "translator",
+	"엮음": "editor", "편": "editor", "편저": "editor",
+	"그림": "illustrator", "삽화": "illustrator",
+}
+
+// 020, 100/700, 245, 260/264, 520, 056(KDC), 082(DDC), 856을 서지 필드로 옮긴다.
+func (r *marcRecord) toBook() marcBook {
+	var b marcBook
+
+	for _, f := range r.fields("245") {
+		title := trimMARCPunct(f.sub("a"))
+		if subtitle := trimMARCPunct(f.sub("b")); subtitle != "" {
+			title += " : " + subtitle
+		}
+		b.Title = title
+		break
+	}
+
+	for _, f := range r.fields("020") {
+		raw := strings.TrimSpace(strings.SplitN(f.sub("a"), " ", 2)[0])
+		if raw == "" {
+			continue
+		}
+		normalized, err := normalizeISBN(raw)
+		if b.RawISBN == "" || (b.ISBNError != nil && err == nil) {
+			b.RawISBN, b.ISBN, b.ISBNError = raw, normalized, err
+		}
+		if b.Price == 0 {
+			b.Price = parseMARCPrice(f.sub("c"))
+		}
+	}
+
+	for _, f := range r.fields("264", "260") {
+		if f.Tag == "264" && f.Ind2 != "1" {
+			continue
+		}
+		if b.Publisher == "" {
+			b.Publisher = trimMARCPunct(f.sub("b"))
+		}
+		if b.Year == 0 {
+			b.Year = firstYear(f.sub("c"))
+		}
+	}
+	if b.Year == 0 {
+		// 008/07-10 발행년
+		if fixed := r.control("008"); len(fixed) >= 11 {
+			b.Year, _ = strconv.Atoi(fixed[7:11])
+		}
+	}
+
+	for _, f := range r.fields("520") {
+		if text := strings.TrimSpace(f.sub("a")); text != "" {
+			b.Description = text
+			break
+		}
+	}
+
+	for _, f := range r.fields("100") {
+		if name := trimMARCPunct(f.sub("a")); name != "" {
+			b.Contributors = append(b.Contributors, ContributorInput{Name: name, Role: "author"})
+		}
+	}
+	for _, f := range r.fields("700") {
+		name := trimMARCPunct(f.sub("a"))
+		if name == "" {
+			continue
+		}
+		role := "author"
+		for _, term := range append(f.subs("4"), f.subs("e")...) {
+			if mapped, ok := marcRelators[strings.ToLower(trimMARCPunct(term))]; ok {
+				role = mapped
+				break
+			}
+		}
+		b.Contributors = append(b.Contributors, ContributorInput{Name: name, Role: role})
+	}
+	if !b.hasAuthor() {
+		// 100/700에 저자가 없으면(역자만 있는 레코드 등) 245 $c 책임표시의 저자를 쓴다
+		for _, f := range r.fields("245") {
+			b.Contributors = append(statementAuthors(f.sub("c")), b.Contributors...)
+			break
+		}
+	}
+
+	for _, f := range r.fields("056") {
+		if code := strings.TrimSpace(f.sub("a")); code != "" {
+			b.KDC = append(b.KDC, code)
+		}
+	}
+	for _, f := range r.fields("082") {
+		for _, code := range f.subs("a") {
+			if code = strings.Trim(strings.TrimSpace(code), "/'"); code != "" {
+				b.DDC = append(b.DDC, strings.ReplaceAll(code, "/", ""))
+			}
+		}
+	}
+
+	for _, f := range r.fields("856") {
+		if url := strings.TrimSpace(f.sub("u")); url != "" && len(url) <= 500 {
+			b.CoverImage = url
+			break
+		}
+	}
+	return b
+}
+
+// 저자 역할 기여자가 있는지 (books.author는 저자 역할 기여자 이름으로 채운다)
+func (b marcBook) hasAuthor() bool {
+	for _, c := range b.Contributors {
+		if c.Role == "author" {
+			return true
+		}
+	}
+	return false
+}
+
+// 245 $c 책임표시의 첫 부분("한강, 김연수 지음 ; 홍길동 옮김"의 "한강, 김연수")을 저자로 읽는다.
+// 첫 부분의 관계어가 저자가 아니면(옮김, 엮음 등) 저자가 없는 것으로 본다.
+func statementAuthors(statement string) []ContributorInput {
+	first := trimMARCPunct(strings.SplitN(statement, ";", 2)[0])
+	if words := strings.Fields(first); len(words) > 1 {
+		if role, ok := marcRelators[strings.ToLower(words[len(words)-1])]; ok {
+			if role != "author" {
+				return nil
+			}
+			first = strings.Join(words[:len(words)-1], " ")
+		}
+	}
+	authors := []ContributorInput{}
+	for _, name := range strings.Split(first, ",") {
+		if name = trimMARCPunct(name); name != "" {
+			authors = append(authors, ContributorInput{Name: name, Role: "author"})
+		}
+	}
+	return authors
+}
+
+// 목록 기술 구두점(끝의 / : ; , . =)을 제거한다.
+func trimMARCPunct(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,.="))
+}
+
+func firstYear(value string) int {
+	for i := 0; i+4 <= len(value); i++ {
+		if year, err := strconv.Atoi(value[i : i+4]); err == nil && year >= minPublicationYear {
+			return year
+		}
+	}
+	return 0
+}
+
+// "₩32000", "32,000원" 같은 가격 표기에서 숫자만 읽는다.
+func parseMARCPrice(value string) float64 {
+	var b strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+	price, _ := strconv.ParseFloat(b.String(), 64)
+	return price
+}
+
+// 도서를 MARC21 레코드로 만든다.
+func bookToMARC(book Book, subjects []SubjectRef) marcRecord {
+	record := marcRecord{Leader: "00000nam a2200000 i 4500"}
+
+	fixed := []byte(strings.Repeat(" ", 40))
+	copy(fixed[0:6], book.CreatedAt.Format("060102"))
+	fixed[6] = 's'
+	if book.Year > 0 {
+		copy(fixed[7:11], fmt.Sprintf("%04d", book.Year))
+	}
+	copy(fixed[15:18], "ko ")
+	copy(fixed[35:38], "kor")
+	fixed[39] = 'd'
+
+	record.ControlFields = []marcControlField{
+		{Tag: "001", Value: book.ID},
+		{Tag: "005", Value: time.Now().Format("20060102150405.0")},
+		{Tag: "008", Value: string(fixed)},
+	}
+
+	add := func(tag, ind1, ind2 string, subfields ...marcSubfield) {
+		kept := []marcSubfield{}
+		for _, s := range subfields {
+			if s.Value != "" {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) > 0 {
+			record.DataFields = append(record.DataFields, marcDataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: kept})
+		}
+	}
+
+	price := ""
+	if book.Price > 0 {
+		price = "₩" + strconv.FormatFloat(book.Price, 'f', -1, 64)
+	}
+	add("020", " ", " ", marcSubfield{"a", book.ISBN}, marcSubfield{"c", price})
+	for _, s := range subjects {
+		add("056", " ", " ", marcSubfield{"a", s.Code}, marcSubfield{"2", "6"})
+	}
+	for _, s := range subjects {
+		add("082", "0", "4", marcSubfield{"a", s.DDCCode})
+	}
+
+	mainEntry := false
+	for _, ct := range book.Contributors {
+		relator := map[string]string{"author": "aut", "translator": "trl", "editor": "edt", "illustrator": "ill"}[ct.Role]
+		if !mainEntry && ct.Role == "author" {
+			add("100", "1", " ", marcSubfield{"a", ct.Name})
+			mainEntry = true
+			continue
+		}
+		add("700", "1", " ", marcSubfield{"a", ct.Name}, marcSubfield{"e", ct.RoleLabel}, marcSubfield{"4", relator})
+	}
+	if len(book.Contributors) == 0 && book.Author != "" {
+		add("100", "1", " ", marcSubfield{"a", book.Author})
+	}
+
+	add("245", "1", "0", marcSubfield{"a", book.Title}, marcSubfield{"c", book.Author})
+	year := ""
+	if book.Year > 0 {
+		year = strconv.Itoa(book.Year)
+	}
+	add("264", " ", "1", marcSubfield{"b", book.Publisher}, marcSubfield{"c", year})
+	add("520", " ", " ", marcSubfield{"a", book.Description})
+	add("856", "4", "2", marcSubfield{"u", book.CoverImage}, marcSubfield{"3", "표지 이미지"})
+	return record
+}
+
+// 레코드를 MARCXML collection 문서로 쓴다.
+func writeMARCXML(w io.Writer, records []marcRecord) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(marcCollection{Xmlns: marcXMLNamespace, Records: records}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
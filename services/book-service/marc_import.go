@@ -0,0 +1,513 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// 가져오기 파일 최대 크기
+	maxMARCUploadBytes = 20 << 20
+	// 한 번에 내보내는 최대 레코드 수
+	maxMARCExportRecords = 1000
+)
+
+var (
+	errUploadMissing  = errors.New("가져올 파일이 없습니다 (multipart file 필드 또는 요청 본문)")
+	errUploadTooLarge = errors.New("파일은 20MB 이하여야 합니다")
+)
+
+// 가져오기 레코드별 처리 결과
+// action: create, update, unchanged, conflict, error
+type marcImportResult struct {
+	Index    int      `json:"index"`
+	Action   string   `json:"action"`
+	BookID   string   `json:"book_id,omitempty"`
+	ISBN     string   `json:"isbn,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Changes  []string `json:"changes,omitempty"`
+	Subjects []string `json:"subjects,omitempty"`
+	Message  string   `json:"message,omitempty"`
+
+	book marcBook
+}
+
+// 분류 번호를 등록된 분류 코드로 바꾸기 위한 조회표
+type subjectResolver struct {
+	codes map[string]bool
+	ddc   map[string]string // DDC 번호 -> 분류 코드 (코드 순으로 처음 나온 것)
+}
+
+func loadSubjectResolver() (*subjectResolver, error) {
+	rows, err := db.Query("SELECT code, COALESCE(ddc_code, '') FROM subjects ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := &subjectResolver{codes: map[string]bool{}, ddc: map[string]string{}}
+	for rows.Next() {
+		var code, ddc string
+		if err := rows.Scan(&code, &ddc); err != nil {
+			return nil, err
+		}
+		r.codes[code] = true
+		if _, ok := r.ddc[ddc]; ddc != "" && !ok {
+			r.ddc[ddc] = code
+		}
+	}
+	return r, rows.Err()
+}
+
+// 분류 번호를 뒤에서부터 줄여 가며 가장 구체적인 등록 분류를 찾는다 (예: 005.133 -> 005.13 -> ... -> 005 -> 000).
+func classificationCandidates(number string) []string {
+	number = strings.TrimSpace(number)
+	candidates := []string{}
+	for n := number; len(n) >= 3; n = strings.TrimSuffix(n[:len(n)-1], ".") {
+		candidates = append(candidates, n)
+	}
+	if len(number) >= 3 {
+		candidates = append(candidates, number[:2]+"0", number[:1]+"00")
+	}
+	return candidates
+}
+
+func (r *subjectResolver) resolve(b marcBook) []string {
+	codes := []string{}
+	for _, kdc := range b.KDC {
+		for _, candidate := range classificationCandidates(kdc) {
+			if r.codes[candidate] {
+				codes = append(codes, candidate)
+				break
+			}
+		}
+	}
+	// KDC가 없을 때만 DDC 대응표 사용
+	if len(codes) == 0 {
+		for _, ddc := range b.DDC {
+			for _, candidate := range classificationCandidates(ddc) {
+				if code, ok := r.ddc[candidate]; ok {
+					codes = append(codes, code)
+					break
+				}
+			}
+		}
+	}
+	return uniqueStrings(codes)
+}
+
+// 관리자: MARC21(ISO 2709) 또는 MARCXML 가져오기
+// 기본은 dry_run=true로 결과 보고서만 만들고, dry_run=false이면 create/update를 반영한다.
+// ISBN이 같은 기존 도서는 갱신하고, 파일 안 중복이나 여러 권과 일치하는 ISBN은 충돌로 건너뛴다.
+func handleImportMARC(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	dryRun := c.DefaultQuery("dry_run", "true") != "false"
+
+	data, err := readUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, parseErrs, err := parseMARC(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resolver, err := loadSubjectResolver()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 가져오기에 실패했습니다"})
+		return
+	}
+
+	results := make([]marcImportResult, len(records))
+	seenISBN := map[string]int{}
+	for i := range records {
+		result := &results[i]
+		result.Index = i
+		if parseErr, ok := parseErrs[i]; ok {
+			result.Action, result.Message = "error", parseErr.Error()
+			continue
+		}
+		if err := planMARCRecord(result, records[i].toBook(), resolver, seenISBN); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 가져오기에 실패했습니다"})
+			return
+		}
+	}
+
+	if !dryRun {
+		changed, err := applyMARCImport(results)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 가져오기에 실패했습니다"})
+			return
+		}
+		reindexBooks(changed)
+		log.Printf("Admin %v imported MARC records (%d changed)", adminID, len(changed))
+	}
+
+	summary := map[string]int{"create": 0, "update": 0, "unchanged": 0, "conflict": 0, "error": 0}
+	for _, r := range results {
+		summary[r.Action]++
+	}
+	c.JSON(http.StatusOK, gin.H{"dry_run": dryRun, "total": len(results), "summary": summary, "records": results})
+}
+
+// multipart의 file 필드 또는 요청 본문 전체를 읽는다.
+func readUpload(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMARCUploadBytes)
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, errUploadMissing
+		}
+		defer file.Close()
+		reader = file
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errUploadTooLarge
+	}
+	if len(data) == 0 {
+		return nil, errUploadMissing
+	}
+	return data, nil
+}
+
+const marcNoAuthorMessage = "저자가 없습니다 (100/700 저자 또는 245 $c 책임표시가 필요합니다)"
+
+// 레코드 한 건을 검증하고 기존 도서와 비교해 처리 방법을 정한다.
+// 새로 등록하는 레코드와 기여자를 바꾸는 레코드는 저자 역할 기여자가 있어야 한다.
+func planMARCRecord(result *marcImportResult, b marcBook, resolver *subjectResolver, seenISBN map[string]int) error {
+	result.Title = b.Title
+	result.ISBN = b.ISBN
+	result.Subjects = resolver.resolve(b)
+	result.book = b
+
+	switch {
+	case b.Title == "":
+		result.Action, result.Message = "error", "245 $a 본표제가 없습니다"
+		return nil
+	case utf8.RuneCountInString(b.Title) > 255:
+		result.Action, result.Message = "error", "본표제가 255자를 넘습니다"
+		return nil
+	case b.ISBNError != nil:
+		result.Action, result.Message = "error", "020 $a "+b.RawISBN+": "+b.ISBNError.Error()
+		return nil
+	case !b.hasAuthor() && len(b.Contributors) > 0:
+		// 기여자를 바꾸면 저자가 없어지므로 갱신도 하지 않는다
+		result.Action, result.Message = "error", marcNoAuthorMessage
+		return nil
+	}
+
+	if b.ISBN == "" {
+		if !b.hasAuthor() {
+			result.Action, result.Message = "error", marcNoAuthorMessage
+			return nil
+		}
+		result.Action, result.Message = "create", "ISBN이 없어 새 도서로 등록합니다"
+		return nil
+	}
+	if first, ok := seenISBN[b.ISBN]; ok {
+		result.Action, result.Message = "conflict", "파일 안에서 ISBN이 중복됩니다 (레코드 "+strconv.Itoa(first)+")"
+		return nil
+	}
+	seenISBN[b.ISBN] = result.Index
+
+	clause, args := isbnMatchClause(b.ISBN)
+	rows, err := db.Query("SELECT id FROM books WHERE "+clause, args...)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	switch len(ids) {
+	case 0:
+		if !b.hasAuthor() {
+			result.Action, result.Message = "error", marcNoAuthorMessage
+			return nil
+		}
+		result.Action = "create"
+		return nil
+	case 1:
+	default:
+		result.Action, result.Message = "conflict", "같은 ISBN을 가진 도서가 여러 권입니다: "+strings.Join(ids, ", ")
+		return nil
+	}
+
+	current, err := fetchBook(ids[0])
+	if err != nil {
+		return err
+	}
+	currentSubjects, err := fetchBookSubjects(ids[0])
+	if err != nil {
+		return err
+	}
+	result.BookID = current.ID
+	result.Changes = marcChanges(current, currentSubjects, b, result.Subjects)
+	if len(result.Changes) == 0 {
+		result.Action = "unchanged"
+	} else {
+		result.Action = "update"
+	}
+	return nil
+}
+
+// 가져오기로 바뀌는 필드 목록 (레코드에 없는 값은 기존 값을 유지하므로 제외)
+func marcChanges(current Book, currentSubjects []SubjectRef, b marcBook, subjects []string) []string {
+	changes := []string{}
+	if b.Title != current.Title {
+		changes = append(changes, "title")
+	}
+	if b.Publisher != "" && b.Publisher != current.Publisher {
+		changes = append(changes, "publisher")
+	}
+	if b.Year != 0 && b.Year != current.Year {
+		changes = append(changes, "year")
+	}
+	if b.ISBN != current.ISBN {
+		changes = append(changes, "isbn")
+	}
+	if b.Description != "" && b.Description != current.Description {
+		changes = append(changes, "description")
+	}
+	if b.Price > 0 && b.Price != current.Price {
+		changes = append(changes, "price")
+	}
+	if b.CoverImage != "" && b.CoverImage != current.CoverImage {
+		changes = append(changes, "cover_image")
+	}
+
+	if len(b.Contributors) > 0 {
+		same := len(b.Contributors) == len(current.Contributors)
+		for i := 0; same && i < len(b.Contributors); i++ {
+			same = b.Contributors[i].Name == current.Contributors[i].Name && b.Contributors[i].Role == current.Contributors[i].Role
+		}
+		if !same {
+			changes = append(changes, "contributors")
+		}
+	}
+
+	assigned := map[string]bool{}
+	for _, s := range currentSubjects {
+		assigned[s.Code] = true
+	}
+	for _, code := range subjects {
+		if !assigned[code] {
+			changes = append(changes, "subjects")
+			break
+		}
+	}
+	return changes
+}
+
+// create/update 결과를 한 트랜잭션으로 반영하고 변경된 도서 ID를 반환한다.
+func applyMARCImport(results []marcImportResult) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changed := []string{}
+	for i := range results {
+		r := &results[i]
+		b := r.book
+
+		switch r.Action {
+		case "create":
+			r.BookID = uuid.New().String()
+			_, err = tx.Exec(`INSERT INTO books (id, title, author, publisher, year, isbn, description, price, cover_image)
+			                  VALUES (?, ?, '', ?, ?, ?, ?, ?, ?)`,
+				r.BookID, b.Title, truncateRunes(b.Publisher, 100), nullIfZero(b.Year), nullIfEmpty(b.ISBN), b.Description, b.Price, b.CoverImage)
+		case "update":
+			_, err = tx.Exec(`UPDATE books
+			                  SET title = ?,
+			                      publisher = IF(? = '', publisher, ?),
+			                      year = IF(? = 0, year, ?),
+			                      isbn = ?,
+			                      description = IF(? = '', description, ?),
+			                      price = IF(? = 0, price, ?),
+			                      cover_image = IF(? = '', cover_image, ?)
+			                  WHERE id = ?`,
+				b.Title, b.Publisher, truncateRunes(b.Publisher, 100), b.Year, b.Year, b.ISBN,
+				b.Description, b.Description, b.Price, b.Price, b.CoverImage, b.CoverImage, r.BookID)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b.Contributors) > 0 || r.Action == "create" {
+			if err := replaceContributors(tx, r.BookID, b.Contributors); err != nil {
+				return nil, err
+			}
+		}
+		for _, code := range r.Subjects {
+			if _, err := tx.Exec("INSERT IGNORE INTO book_subjects (book_id, subject_code) VALUES (?, ?)", r.BookID, code); err != nil {
+				return nil, err
+			}
+		}
+		changed = append(changed, r.BookID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
+
+// 도서 한 권을 MARCXML로 내보내기
+func handleGetBookMARC(c *gin.Context) {
+	records, err := marcRecordsFor([]string{c.Param("id")})
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+	if len(records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+	writeMARCResponse(c, records, "")
+}
+
+// 관리자: 검색 결과를 MARCXML collection으로 내보내기
+// ids(쉼표 구분)를 주면 해당 도서만, 아니면 q(통합 검색)와 subject(하위 분류 포함)로 고른다.
+func handleExportMARC(c *gin.Context) {
+	ids := []string{}
+	if raw := strings.TrimSpace(c.Query("ids")); raw != "" {
+		for _, id := range strings.Split(raw, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		where := " WHERE 1=1"
+		args := []interface{}{}
+		if q := strings.TrimSpace(c.Query("q")); q != "" {
+			hits := searchIdx.searchFuzzy(q, maxMARCExportRecords).Hits
+			if len(hits) == 0 {
+				writeMARCResponse(c, []marcRecord{}, "catalog")
+				return
+			}
+			where += " AND b.id IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(hits)), ", ") + ")"
+			for _, hit := range hits {
+				args = append(args, hit.ID)
+			}
+		}
+		if subject := c.Query("subject"); subject != "" {
+			codes, err := subjectSubtree(subject)
+			if err != nil {
+				log.Printf("Database error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 내보내기에 실패했습니다"})
+				return
+			}
+			if len(codes) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 분류입니다"})
+				return
+			}
+			where += " AND EXISTS (SELECT 1 FROM book_subjects bs WHERE bs.book_id = b.id AND bs.subject_code IN (" +
+				strings.TrimSuffix(strings.Repeat("?, ", len(codes)), ", ") + "))"
+			for _, code := range codes {
+				args = append(args, code)
+			}
+		}
+
+		rows, err := db.Query("SELECT b.id FROM books b"+where+" ORDER BY b.created_at, b.id LIMIT ?",
+			append(args, maxMARCExportRecords+1)...)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 내보내기에 실패했습니다"})
+			return
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				log.Printf("Scan error: %v", err)
+				continue
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+	}
+
+	if len(ids) > maxMARCExportRecords {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "한 번에 " + strconv.Itoa(maxMARCExportRecords) + "건까지 내보낼 수 있습니다"})
+		return
+	}
+
+	records, err := marcRecordsFor(ids)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "MARC 내보내기에 실패했습니다"})
+		return
+	}
+	writeMARCResponse(c, records, "catalog")
+}
+
+// 존재하지 않는 ID는 건너뛴다.
+func marcRecordsFor(ids []string) ([]marcRecord, error) {
+	records := []marcRecord{}
+	for _, id := range ids {
+		book, err := fetchBook(id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		subjects, err := fetchBookSubjects(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, bookToMARC(book, subjects))
+	}
+	return records, nil
+}
+
+// filename을 주면 첨부 파일로 내려준다.
+func writeMARCResponse(c *gin.Context, records []marcRecord, filename string) {
+	c.Header("Content-Type", "application/marcxml+xml; charset=utf-8")
+	if filename != "" {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+"-"+time.Now().Format("20060102")+`.xml"`)
+	}
+	c.Status(http.StatusOK)
+	if err := writeMARCXML(c.Writer, records); err != nil {
+		log.Printf("MARC export error: %v", err)
+	}
+}
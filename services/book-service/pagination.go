@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// 목록 응답 공통 형식
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	Limit      int         `json:"limit"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// 정렬 기준: 마지막 컬럼은 유일한 값이어야 커서가 안정적으로 동작한다.
+// Aggregate가 true이면 집계 컬럼이므로 커서 조건을 HAVING 절에 붙인다.
+type sortSpec struct {
+	Columns   []string
+	Desc      bool
+	Aggregate bool
+}
+
+// 요청에서 읽은 페이지 정보 (Cursor가 있으면 Page는 무시)
+type pageRequest struct {
+	Page   int
+	Limit  int
+	Cursor []string
+}
+
+// 커서 내부 구조 (클라이언트에는 base64 문자열로만 노출)
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// page, limit, cursor 쿼리 파라미터를 읽는다.
+// 커서는 발급될 때의 정렬 기준(sortKey)과 같아야 한다.
+func parsePageRequest(c *gin.Context, sortKey string, spec sortSpec) (pageRequest, error) {
+	req := pageRequest{Page: 1, Limit: defaultPageSize}
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit >= 1 && limit <= maxPageSize {
+		req.Limit = limit
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		values, err := decodeCursor(token, sortKey)
+		if err != nil {
+			return req, err
+		}
+		if len(values) != len(spec.Columns) {
+			return req, errInvalidCursor
+		}
+		req.Cursor = values
+		req.Page = 0
+		return req, nil
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 1 {
+		req.Page = page
+	}
+	return req, nil
+}
+
+// ORDER BY 절 (모든 컬럼에 같은 방향 적용)
+func (s sortSpec) orderBy() string {
+	direction := " ASC"
+	if s.Desc {
+		direction = " DESC"
+	}
+	parts := make([]string, len(s.Columns))
+	for i, column := range s.Columns {
+		parts[i] = column + direction
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// 커서 이후 행만 남기는 조건: (c1, c2) > (?, ?)
+func (s sortSpec) after() string {
+	op := " > "
+	if s.Desc {
+		op = " < "
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(s.Columns)), ", ")
+	return "(" + strings.Join(s.Columns, ", ") + ")" + op + "(" + placeholders + ")"
+}
+
+// LIMIT 절과 인자: 다음 페이지 확인을 위해 한 건 더 조회하고, 커서가 없으면 OFFSET 사용
+func (p pageRequest) limitClause() (string, []interface{}) {
+	if p.Cursor != nil {
+		return " LIMIT ?", []interface{}{p.Limit + 1}
+	}
+	return " LIMIT ? OFFSET ?", []interface{}{p.Limit + 1, (p.Page - 1) * p.Limit}
+}
+
+func (p pageRequest) cursorArgs() []interface{} {
+	args := make([]interface{}, len(p.Cursor))
+	for i, value := range p.Cursor {
+		args[i] = value
+	}
+	return args
+}
+
+func encodeCursor(sortKey string, values []string) string {
+	data, _ := json.Marshal(cursorPayload{Sort: sortKey, Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token, sortKey string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Sort != sortKey || len(payload.Values) == 0 {
+		return nil, errInvalidCursor
+	}
+	return payload.Values, nil
+}
+
+// limit+1건 조회 결과로 응답을 만든다. lastValues는 현재 페이지 마지막 행의 정렬 값이다.
+func newPageResponse(items interface{}, count int, total int, req pageRequest, sortKey string, lastValues func() []string) PageResponse {
+	resp := PageResponse{Items: items, Total: total, Page: req.Page, Limit: req.Limit}
+	if count > req.Limit {
+		next := encodeCursor(sortKey, lastValues())
+		resp.NextCursor = &next
+	}
+	return resp
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 기본 동의어/음역 사전 (SEARCH_SYNONYMS_FILE로 교체 가능)
+//
+//go:embed synonyms.txt
+var defaultSynonyms string
+
+// 같은 의미로 취급하는 표기 묶음 (모두 소문자)
+var synonymGroups [][]string
+
+const (
+	// 질의 확장으로 만드는 최대 변형 수
+	maxQueryVariants = 8
+	// 원래 질의가 아닌 변형으로 찾은 결과의 점수 배율
+	variantScoreFactor    = 0.9
+	correctionScoreFactor = 0.8
+)
+
+// 동의어 사전을 읽는다. 파일을 지정하지 않으면 내장 사전을 사용한다.
+func loadSynonyms() error {
+	data := defaultSynonyms
+	if path := getEnv("SEARCH_SYNONYMS_FILE", ""); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data = string(content)
+	}
+
+	synonymGroups = parseSynonyms(data)
+	log.Printf("Loaded %d synonym groups", len(synonymGroups))
+	return nil
+}
+
+func parseSynonyms(data string) [][]string {
+	groups := [][]string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		group := []string{}
+		for _, term := range strings.Split(line, ",") {
+			if term = strings.ToLower(strings.TrimSpace(term)); term != "" {
+				group = append(group, term)
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// 검색 결과와, 하이라이트에 사용할 실제 검색 문자열 목록
+type fuzzyResult struct {
+	Hits    []searchHit
+	Queries []string
+}
+
+// 초성, 동의어/음역, 영문 자판 입력, 오타를 고려해 검색한다.
+// 원래 질의(와 동의어)로 결과가 없을 때만 자판 변환과 오타 교정을 차례로 시도한다.
+func (idx *searchIndex) searchFuzzy(query string, limit int) fuzzyResult {
+	if containsChoseong(query) {
+		return fuzzyResult{Hits: idx.searchChoseong(query, limit)}
+	}
+
+	queries := expandSynonyms(query)
+	scores := map[string]float64{}
+	idx.mergeSearch(scores, queries, 1, limit)
+
+	if len(scores) == 0 {
+		if converted, ok := convertDubeolsik(query); ok {
+			variants := expandSynonyms(converted)
+			if idx.mergeSearch(scores, variants, variantScoreFactor, limit) {
+				queries = append(queries, variants...)
+			}
+		}
+	}
+
+	if len(scores) == 0 {
+		if corrected := idx.correctQuery(query); corrected != strings.ToLower(query) {
+			variants := expandSynonyms(corrected)
+			if idx.mergeSearch(scores, variants, correctionScoreFactor, limit) {
+				queries = append(queries, variants...)
+			}
+		}
+	}
+
+	return fuzzyResult{Hits: sortedHits(scores, limit), Queries: queries}
+}
+
+// 각 질의의 검색 결과를 합친다 (도서별 최고 점수). 첫 질의가 원래 질의이고 나머지는 변형이다.
+func (idx *searchIndex) mergeSearch(scores map[string]float64, queries []string, factor float64, limit int) bool {
+	found := false
+	for i, q := range queries {
+		weight := factor
+		if i > 0 {
+			weight *= variantScoreFactor
+		}
+		for _, hit := range idx.search(q, limit) {
+			if score := hit.Score * weight; score > scores[hit.ID] {
+				scores[hit.ID] = score
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+func sortedHits(scores map[string]float64, limit int) []searchHit {
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, searchHit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// 한글 표기 뒤에 붙어도 같은 단어로 보는 조사
+var hangulParticles = map[string]bool{
+	"은": true, "는": true, "이": true, "가": true, "을": true, "를": true, "의": true, "에": true,
+	"와": true, "과": true, "도": true, "로": true, "만": true, "나": true, "랑": true,
+	"으로": true, "에서": true, "에게": true, "까지": true, "부터": true, "보다": true, "처럼": true,
+	"이나": true, "이랑": true,
+}
+
+// 질의에 포함된 사전 표기를 같은 묶음의 다른 표기로 바꾼 변형을 만든다 (첫 항목은 원래 질의).
+// 긴 표기부터 찾고 이미 찾은 위치와 겹치는 표기는 건너뛴다 ("자바스크립트"가 "자바"로 바뀌지 않도록).
+func expandSynonyms(query string) []string {
+	lower := strings.ToLower(strings.TrimSpace(query))
+	variants := []string{lower}
+	seen := map[string]bool{lower: true}
+
+	type entry struct {
+		term  string
+		group int
+	}
+	entries := []entry{}
+	for i, group := range synonymGroups {
+		for _, term := range group {
+			entries = append(entries, entry{term, i})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len([]rune(entries[i].term)) > len([]rune(entries[j].term))
+	})
+
+	usedGroups := map[int]bool{}
+	var spans [][2]int
+	for _, e := range entries {
+		if usedGroups[e.group] {
+			continue
+		}
+		pos := findTerm(lower, e.term, spans)
+		if pos < 0 {
+			continue
+		}
+		usedGroups[e.group] = true
+		spans = append(spans, [2]int{pos, pos + len(e.term)})
+		for _, other := range synonymGroups[e.group] {
+			variant := lower[:pos] + other + lower[pos+len(e.term):]
+			if !seen[variant] && len(variants) < maxQueryVariants {
+				seen[variant] = true
+				variants = append(variants, variant)
+			}
+		}
+	}
+	return variants
+}
+
+// 표기가 단어로 나타나는 첫 위치 (없으면 -1). 이미 찾은 위치(spans)와 겹치는 곳은 제외한다.
+func findTerm(text, term string, spans [][2]int) int {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return -1
+		}
+		pos := offset + i
+		if containsTermAt(text, term, pos) && !overlapsSpan(pos, pos+len(term), spans) {
+			return pos
+		}
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		offset = pos + size
+	}
+	return -1
+}
+
+func overlapsSpan(start, end int, spans [][2]int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// pos에 있는 표기가 단어 단위로 일치하는지 확인한다.
+// 영문 표기는 단어 경계에서만, 한글 표기는 splitWords와 같은 기준의 한 단어 전체이거나
+// 그 뒤에 조사만 붙은 경우에만 일치로 본다 ("깃"은 "깃발"과 일치하지 않는다).
+func containsTermAt(text, term string, pos int) bool {
+	end := pos + len(term)
+	if !isHangulSyllable([]rune(term)[0]) {
+		before := pos == 0 || !isASCIIAlnum(text[pos-1])
+		after := end == len(text) || !isASCIIAlnum(text[end])
+		return before && after
+	}
+
+	if pos > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:pos]); isHangulSyllable(r) {
+			return false
+		}
+	}
+	rest := end
+	for rest < len(text) {
+		r, size := utf8.DecodeRuneInString(text[rest:])
+		if !isHangulSyllable(r) {
+			break
+		}
+		rest += size
+	}
+	return rest == end || hangulParticles[text[end:rest]]
+}
+
+func isASCIIAlnum(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func containsChoseong(query string) bool {
+	for _, r := range query {
+		if isChoseong(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// 초성 검색: 자음은 음절의 초성과, 완성 음절은 그대로 비교한다 (예: "ㅋㅂㄴㅌㅅ", "쿠ㅂㄴ").
+// 제목은 저자보다 높은 점수를 주고, 앞부분에서 일치하면 가산한다.
+func (idx *searchIndex) searchChoseong(query string, limit int) []searchHit {
+	pattern := []rune(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	if len(pattern) < 2 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := map[string]float64{}
+	for id, doc := range idx.docs {
+		fields := []struct {
+			text   string
+			weight float64
+		}{
+			{doc.Title, searchFieldWeights["title"]},
+			{doc.Author, searchFieldWeights["author"]},
+		}
+		for _, field := range fields {
+			text := []rune(strings.ToLower(strings.Join(strings.Fields(field.text), "")))
+			pos := matchChoseong(text, pattern)
+			if pos < 0 {
+				continue
+			}
+			score := field.weight
+			if pos == 0 {
+				score *= 1.5
+			}
+			if score > scores[id] {
+				scores[id] = score
+			}
+		}
+	}
+	return sortedHits(scores, limit)
+}
+
+// 패턴이 처음 일치하는 위치 (없으면 -1)
+func matchChoseong(text, pattern []rune) int {
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		matched := true
+		for j, p := range pattern {
+			t := text[i+j]
+			if isChoseong(p) {
+				matched = isHangulSyllable(t) && choseongOf(t) == p
+			} else {
+				matched = t == p
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// 색인에 없는 단어를 편집 거리가 가장 가까운 색인 단어로 바꾼다.
+// 한글은 자모 단위로 비교해 "쿠버네티즈" -> "쿠버네티스" 같은 오타를 한 번의 편집으로 본다.
+func (idx *searchIndex) correctQuery(query string) string {
+	words := strings.Fields(strings.ToLower(query))
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	for i, word := range words {
+		if idx.vocab[word] > 0 || idx.hasAllTerms(word) {
+			continue
+		}
+		jamo := decomposeJamo(word)
+		limit := maxEditsFor(len(jamo))
+		if limit == 0 {
+			continue
+		}
+
+		best, bestDistance, bestCount := "", limit+1, 0
+		for candidate, count := range idx.vocab {
+			cj := decomposeJamo(candidate)
+			if absInt(len(cj)-len(jamo)) > limit {
+				continue
+			}
+			d := editDistance(jamo, cj, limit)
+			if d > limit {
+				continue
+			}
+			if d < bestDistance || (d == bestDistance && count > bestCount) ||
+				(d == bestDistance && count == bestCount && candidate < best) {
+				best, bestDistance, bestCount = candidate, d, count
+			}
+		}
+		if best != "" {
+			words[i] = best
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// 단어의 모든 색인 term이 존재하는지 (부분 단어 검색은 교정하지 않음)
+func (idx *searchIndex) hasAllTerms(word string) bool {
+	for _, term := range tokenize(word) {
+		if len(idx.postings[term]) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 길이(자모 수)에 따른 허용 편집 거리
+func maxEditsFor(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length <= 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// 편집 거리 (limit을 넘으면 limit+1 반환)
+func editDistance(a, b []rune, limit int) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			rowMin = minInt(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	if prev[len(b)] > limit {
+		return limit + 1
+	}
+	return prev[len(b)]
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
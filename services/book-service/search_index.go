@@ -0,0 +1,580 @@
+package main
+
+import (
+	"html"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 검색 대상 필드별 가중치
+var searchFieldWeights = map[string]float64{
+	"title":        3.0,
+	"author":       2.0,
+	"contributors": 2.0,
+	"publisher":    1.0,
+	"isbn":         3.0,
+	"description":  0.5,
+}
+
+const (
+	// 한 번의 검색에서 고려하는 최대 결과 수
+	maxSearchHits = 1000
+	// 다른 인스턴스의 서지 변경을 확인하는 주기
+	searchIndexRefreshInterval = time.Minute
+	// 설명 필드 하이라이트 앞뒤로 보여줄 글자 수
+	snippetRadius = 40
+)
+
+// 색인 대상 서지 정보
+type catalogDoc struct {
+	ID          string
+	Title       string
+	Author      string
+	Publisher   string
+	ISBN        string
+	Description string
+	// 대표 저자 외의 기여자 이름 (역자, 편자, 공저자 등)
+	Contributors []string
+}
+
+func (d catalogDoc) fields() map[string]string {
+	return map[string]string{
+		"title":        d.Title,
+		"author":       d.Author,
+		"contributors": strings.Join(d.Contributors, " "),
+		"publisher":    d.Publisher,
+		"isbn":         d.ISBN,
+		"description":  d.Description,
+	}
+}
+
+type searchHit struct {
+	ID    string
+	Score float64
+}
+
+// 메모리 역색인: 한글은 2-gram, 그 외 문자는 단어 단위로 색인한다.
+// MariaDB FULLTEXT는 한글 n-gram 파서를 지원하지 않아 서비스 안에서 직접 관리한다.
+type searchIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]catalogDoc
+	postings map[string]map[string]float64 // term -> book ID -> 가중 빈도
+	vocab    map[string]int                // 제목/저자/출판사 단어 -> 포함 도서 수 (오타 교정 후보)
+	version  string                        // 마지막으로 반영한 DB 상태 (도서 수/최종 수정 시각)
+	// 자동완성 색인 (nil이면 다음 요청에서 다시 만든다)
+	suggestions *suggestIndex
+}
+
+var searchIdx = newSearchIndex()
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     map[string]catalogDoc{},
+		postings: map[string]map[string]float64{},
+		vocab:    map[string]int{},
+	}
+}
+
+// DB에서 전체 서지를 읽어 색인을 다시 만든다.
+func loadSearchIndex() error {
+	version, err := catalogVersion()
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT b.id, b.title, b.author, COALESCE(b.publisher, ''), COALESCE(b.isbn, ''), COALESCE(b.description, ''),
+	                       COALESCE((SELECT GROUP_CONCAT(a.name ORDER BY ct.position SEPARATOR '\n')
+	                                 FROM book_contributors ct JOIN authors a ON a.id = ct.author_id
+	                                 WHERE ct.book_id = b.id), '')
+	                       FROM books b`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	docs := []catalogDoc{}
+	for rows.Next() {
+		var doc catalogDoc
+		var contributors string
+		if err := rows.Scan(&doc.ID, &doc.Title, &doc.Author, &doc.Publisher, &doc.ISBN, &doc.Description, &contributors); err != nil {
+			return err
+		}
+		if contributors != "" {
+			doc.Contributors = otherContributors(doc.Author, strings.Split(contributors, "\n"))
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	searchIdx.rebuild(docs, version)
+	log.Printf("Search index built with %d books", len(docs))
+	return nil
+}
+
+// 도서 수와 최종 수정 시각으로 DB 상태를 요약한다.
+func catalogVersion() (string, error) {
+	var count int
+	var updatedAt *time.Time
+	if err := db.QueryRow("SELECT COUNT(*), MAX(updated_at) FROM books").Scan(&count, &updatedAt); err != nil {
+		return "", err
+	}
+	version := strconv.Itoa(count)
+	if updatedAt != nil {
+		version += "/" + updatedAt.Format(time.RFC3339Nano)
+	}
+	return version, nil
+}
+
+// 주기적으로 DB 상태를 확인하고, 다른 인스턴스에서 변경이 있으면 색인을 다시 만든다.
+func refreshSearchIndexPeriodically() {
+	ticker := time.NewTicker(searchIndexRefreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		version, err := catalogVersion()
+		if err != nil {
+			log.Printf("Search index refresh error: %v", err)
+			continue
+		}
+		if version == searchIdx.currentVersion() {
+			continue
+		}
+		if err := loadSearchIndex(); err != nil {
+			log.Printf("Search index refresh error: %v", err)
+		}
+	}
+}
+
+// 서지 등록/수정 후 색인 반영 (book은 fetchBook으로 기여자까지 읽은 값)
+func indexBook(book Book) {
+	names := make([]string, len(book.Contributors))
+	for i, ct := range book.Contributors {
+		names[i] = ct.Name
+	}
+	searchIdx.upsert(catalogDoc{
+		ID:           book.ID,
+		Title:        book.Title,
+		Author:       book.Author,
+		Publisher:    book.Publisher,
+		ISBN:         book.ISBN,
+		Description:  book.Description,
+		Contributors: otherContributors(book.Author, names),
+	})
+}
+
+// 대표 저자 문자열에 이미 포함된 이름은 중복 가중치를 피하려고 제외한다.
+func otherContributors(author string, names []string) []string {
+	others := []string{}
+	for _, name := range uniqueStrings(names) {
+		if !strings.Contains(author, name) {
+			others = append(others, name)
+		}
+	}
+	return others
+}
+
+// 서지 삭제 후 색인 반영
+func unindexBook(bookID string) {
+	searchIdx.remove(bookID)
+}
+
+func (idx *searchIndex) currentVersion() string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.version
+}
+
+func (idx *searchIndex) rebuild(docs []catalogDoc, version string) {
+	postings := map[string]map[string]float64{}
+	vocab := map[string]int{}
+	byID := make(map[string]catalogDoc, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+		addPostings(postings, doc)
+		for word := range docWords(doc) {
+			vocab[word]++
+		}
+	}
+
+	suggestions := buildSuggestIndex(byID)
+
+	idx.mu.Lock()
+	idx.docs = byID
+	idx.postings = postings
+	idx.vocab = vocab
+	idx.version = version
+	idx.suggestions = suggestions
+	idx.mu.Unlock()
+}
+
+func (idx *searchIndex) upsert(doc catalogDoc) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(doc.ID)
+	idx.docs[doc.ID] = doc
+	addPostings(idx.postings, doc)
+	for word := range docWords(doc) {
+		idx.vocab[word]++
+	}
+	// 로컬 변경이므로 다음 주기에 DB 상태와 다시 맞춘다.
+	idx.version = ""
+	idx.suggestions = nil
+}
+
+func (idx *searchIndex) remove(bookID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(bookID)
+	idx.version = ""
+	idx.suggestions = nil
+}
+
+func (idx *searchIndex) removeLocked(bookID string) {
+	doc, ok := idx.docs[bookID]
+	if !ok {
+		return
+	}
+	for term := range docTerms(doc) {
+		delete(idx.postings[term], bookID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	for word := range docWords(doc) {
+		if idx.vocab[word]--; idx.vocab[word] <= 0 {
+			delete(idx.vocab, word)
+		}
+	}
+	delete(idx.docs, bookID)
+}
+
+func addPostings(postings map[string]map[string]float64, doc catalogDoc) {
+	for term, weight := range docTerms(doc) {
+		if postings[term] == nil {
+			postings[term] = map[string]float64{}
+		}
+		postings[term][doc.ID] = weight
+	}
+}
+
+// 문서의 term별 가중 빈도
+func docTerms(doc catalogDoc) map[string]float64 {
+	terms := map[string]float64{}
+	for field, text := range doc.fields() {
+		weight := searchFieldWeights[field]
+		var tokens []string
+		if field == "isbn" {
+			// 저장된 형식과 ISBN-13 정규형 모두로 찾을 수 있게 한다
+			tokens = isbnTokens(text)
+			if normalized, err := normalizeISBN(text); err == nil {
+				tokens = uniqueStrings(append(tokens, normalized))
+			}
+		} else {
+			tokens = tokenize(text)
+		}
+		for _, token := range tokens {
+			terms[token] += weight
+		}
+	}
+	return terms
+}
+
+// 오타 교정 후보가 되는 단어 집합 (설명은 제외)
+func docWords(doc catalogDoc) map[string]bool {
+	words := map[string]bool{}
+	texts := append([]string{doc.Title, doc.Author, doc.Publisher}, doc.Contributors...)
+	for _, text := range texts {
+		for _, word := range splitWords(text) {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// 질의와 관련도가 높은 순으로 도서 ID를 반환한다.
+// 질의 term의 60% 이상이 일치해야 결과에 포함된다.
+func (idx *searchIndex) search(query string, limit int) []searchHit {
+	// ISBN 형식의 질의는 하이픈 구분 조각이 아니라 숫자열 전체로만 찾는다 (유효하면 ISBN-13으로).
+	terms := isbnTokens(query)
+	if normalized, err := normalizeISBN(query); err == nil {
+		terms = []string{normalized}
+	}
+	if terms == nil {
+		terms = uniqueStrings(tokenize(query))
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.docs))
+	scores := map[string]float64{}
+	matched := map[string]int{}
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		idf := math.Log(1 + total/float64(len(docs)))
+		for id, tf := range docs {
+			// 빈도가 높아질수록 점수 증가폭이 줄어들도록 포화
+			scores[id] += idf * tf / (tf + 1.2)
+			matched[id]++
+		}
+	}
+
+	required := int(math.Ceil(float64(len(terms)) * 0.6))
+	hits := []searchHit{}
+	for id, score := range scores {
+		if matched[id] >= required {
+			hits = append(hits, searchHit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// 텍스트를 색인 term으로 나눈다.
+// 한글 음절은 2-gram(한 글자 단어는 그대로), 영문/숫자는 소문자 단어 단위.
+func tokenize(text string) []string {
+	tokens := []string{}
+	for _, word := range splitWords(text) {
+		runes := []rune(word)
+		if isHangulSyllable(runes[0]) {
+			tokens = append(tokens, hangulBigrams(runes)...)
+		} else {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// 소문자로 바꾼 단어 목록. 한글 음절과 그 외 문자가 이어지면 서로 다른 단어로 나눈다.
+func splitWords(text string) []string {
+	words := []string{}
+	var run []rune
+	runHangul := false
+
+	flush := func() {
+		if len(run) > 0 {
+			words = append(words, string(run))
+			run = run[:0]
+		}
+	}
+
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case isHangulSyllable(r):
+			if !runHangul {
+				flush()
+				runHangul = true
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if runHangul {
+				flush()
+				runHangul = false
+			}
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func hangulBigrams(run []rune) []string {
+	if len(run) == 1 {
+		return []string{string(run)}
+	}
+	grams := make([]string, 0, len(run)-1)
+	for i := 0; i+1 < len(run); i++ {
+		grams = append(grams, string(run[i:i+2]))
+	}
+	return grams
+}
+
+// ISBN은 하이픈을 제거한 숫자열 하나로 색인한다.
+func isbnTokens(text string) []string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('x')
+		case r == '-' || r == ' ':
+		default:
+			return nil
+		}
+	}
+	if b.Len() < 10 {
+		return nil
+	}
+	return []string{b.String()}
+}
+
+func isHangulSyllable(r rune) bool {
+	return r >= 0xAC00 && r <= 0xD7A3
+}
+
+// 일치한 부분을 <mark>로 감싼 필드별 하이라이트 (일치가 없는 필드는 제외)
+func highlightBook(book Book, query string) map[string]string {
+	needles := highlightNeedles(query)
+	if len(needles) == 0 {
+		return nil
+	}
+
+	highlights := map[string]string{}
+	for field, text := range map[string]string{
+		"title":       book.Title,
+		"author":      book.Author,
+		"publisher":   book.Publisher,
+		"description": book.Description,
+	} {
+		if snippet, ok := highlightText(text, needles, field == "description"); ok {
+			highlights[field] = snippet
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
+
+// 하이라이트할 문자열: 질의 단어와, 긴 한글 단어의 2-gram (부분 일치 표시용)
+func highlightNeedles(query string) [][]rune {
+	needles := [][]rune{}
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		needles = append(needles, []rune(word))
+		for _, gram := range tokenize(word) {
+			if gram != word {
+				needles = append(needles, []rune(gram))
+			}
+		}
+	}
+	return needles
+}
+
+func highlightText(text string, needles [][]rune, snippet bool) (string, bool) {
+	original := []rune(text)
+	lower := make([]rune, len(original))
+	for i, r := range original {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(original))
+	found := false
+	for _, needle := range needles {
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(needle)], needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	start, end := 0, len(original)
+	if snippet {
+		first, last := -1, -1
+		for i, m := range marked {
+			if m {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+		start = maxInt(0, first-snippetRadius)
+		end = minInt(len(original), last+1+snippetRadius)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	inMark := false
+	for i := start; i < end; i++ {
+		if marked[i] && !inMark {
+			b.WriteString("<mark>")
+			inMark = true
+		} else if !marked[i] && inMark {
+			b.WriteString("</mark>")
+			inMark = false
+		}
+		b.WriteString(html.EscapeString(string(original[i])))
+	}
+	if inMark {
+		b.WriteString("</mark>")
+	}
+	if end < len(original) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
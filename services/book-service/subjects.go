@@ -0,0 +1,492 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 주제 분류 (KDC 번호가 코드이고, DDCCode는 대응하는 듀이십진분류 번호)
+// BookCount는 하위 분류까지 포함한 도서 수다.
+type Subject struct {
+	Code       string     `json:"code"`
+	Name       string     `json:"name"`
+	ParentCode *string    `json:"parent_code"`
+	DDCCode    string     `json:"ddc_code,omitempty"`
+	BookCount  int        `json:"book_count"`
+	Children   []*Subject `json:"children,omitempty"`
+}
+
+// 도서에 배정된 분류
+type SubjectRef struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	DDCCode string `json:"ddc_code,omitempty"`
+}
+
+// 분류 등록/수정 요청
+type SubjectInput struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	ParentCode string `json:"parent_code"`
+	DDCCode    string `json:"ddc_code"`
+}
+
+// 분류별 도서 수 (하위 분류에 배정된 도서를 중복 없이 합산)
+const subjectCountsQuery = `WITH RECURSIVE subject_tree (code, root) AS (
+	  SELECT code, code FROM subjects
+	  UNION ALL
+	  SELECT s.code, t.root FROM subjects s JOIN subject_tree t ON s.parent_code = t.code
+	)
+	SELECT s.code, s.name, s.parent_code, COALESCE(s.ddc_code, ''), COALESCE(c.book_count, 0)
+	FROM subjects s
+	LEFT JOIN (
+	  SELECT t.root, COUNT(DISTINCT bs.book_id) AS book_count
+	  FROM subject_tree t
+	  JOIN book_subjects bs ON bs.subject_code = t.code
+	  GROUP BY t.root
+	) c ON c.root = s.code
+	ORDER BY s.code`
+
+// 분류 전체를 도서 수와 함께 읽어 트리로 만든다 (코드 순 정렬).
+func loadSubjectTree() (roots []*Subject, byCode map[string]*Subject, err error) {
+	rows, err := db.Query(subjectCountsQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ordered := []*Subject{}
+	byCode = map[string]*Subject{}
+	for rows.Next() {
+		var s Subject
+		var parent sql.NullString
+		if err := rows.Scan(&s.Code, &s.Name, &parent, &s.DDCCode, &s.BookCount); err != nil {
+			return nil, nil, err
+		}
+		if parent.Valid {
+			s.ParentCode = &parent.String
+		}
+		ordered = append(ordered, &s)
+		byCode[s.Code] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	roots = []*Subject{}
+	for _, s := range ordered {
+		if s.ParentCode == nil {
+			roots = append(roots, s)
+			continue
+		}
+		if parent, ok := byCode[*s.ParentCode]; ok {
+			parent.Children = append(parent.Children, s)
+		}
+	}
+	return roots, byCode, nil
+}
+
+// depth 단계까지만 남기고(0이면 전체), nonEmpty이면 도서가 없는 분류를 뺀 사본을 만든다.
+func pruneSubjects(nodes []*Subject, depth int, nonEmpty bool) []*Subject {
+	pruned := []*Subject{}
+	for _, node := range nodes {
+		if nonEmpty && node.BookCount == 0 {
+			continue
+		}
+		copied := *node
+		copied.Children = nil
+		if depth != 1 && len(node.Children) > 0 {
+			next := depth - 1
+			if depth == 0 {
+				next = 0
+			}
+			copied.Children = pruneSubjects(node.Children, next, nonEmpty)
+		}
+		pruned = append(pruned, &copied)
+	}
+	return pruned
+}
+
+// 분류와 모든 하위 분류의 코드 (분류가 없으면 빈 목록)
+func subjectSubtree(code string) ([]string, error) {
+	query := `WITH RECURSIVE subject_tree (code) AS (
+	            SELECT code FROM subjects WHERE code = ?
+	            UNION ALL
+	            SELECT s.code FROM subjects s JOIN subject_tree t ON s.parent_code = t.code
+	          )
+	          SELECT code FROM subject_tree`
+	rows, err := db.Query(query, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []string{}
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+	return codes, rows.Err()
+}
+
+// 도서에 배정된 분류 목록
+func fetchBookSubjects(bookID string) ([]SubjectRef, error) {
+	query := `SELECT s.code, s.name, COALESCE(s.ddc_code, '')
+	          FROM book_subjects bs
+	          JOIN subjects s ON s.code = bs.subject_code
+	          WHERE bs.book_id = ?
+	          ORDER BY s.code`
+	rows, err := db.Query(query, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []SubjectRef{}
+	for rows.Next() {
+		var s SubjectRef
+		if err := rows.Scan(&s.Code, &s.Name, &s.DDCCode); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+// 분류 트리 조회 (root: 특정 분류 아래만, depth: 표시할 단계 수, nonempty=true: 도서가 있는 분류만)
+func handleGetSubjects(c *gin.Context) {
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depth는 0 이상의 정수여야 합니다"})
+		return
+	}
+	nonEmpty := c.Query("nonempty") == "true"
+
+	roots, byCode, err := loadSubjectTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 조회에 실패했습니다"})
+		return
+	}
+
+	if root := c.Query("root"); root != "" {
+		node, ok := byCode[root]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "분류를 찾을 수 없습니다"})
+			return
+		}
+		roots = node.Children
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subjects": pruneSubjects(roots, depth, nonEmpty)})
+}
+
+// 분류 한 건 조회: 상위 분류 경로와 바로 아래 분류
+func handleGetSubject(c *gin.Context) {
+	_, byCode, err := loadSubjectTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 조회에 실패했습니다"})
+		return
+	}
+
+	node, ok := byCode[c.Param("code")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분류를 찾을 수 없습니다"})
+		return
+	}
+
+	path := []SubjectRef{}
+	for parent := node.ParentCode; parent != nil; {
+		p, ok := byCode[*parent]
+		if !ok {
+			break
+		}
+		path = append([]SubjectRef{{Code: p.Code, Name: p.Name, DDCCode: p.DDCCode}}, path...)
+		parent = p.ParentCode
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subject": pruneSubjects([]*Subject{node}, 2, false)[0], "path": path})
+}
+
+func validateSubjectInput(input *SubjectInput) map[string]string {
+	input.Code = strings.TrimSpace(input.Code)
+	input.Name = strings.TrimSpace(input.Name)
+	input.ParentCode = strings.TrimSpace(input.ParentCode)
+	input.DDCCode = strings.TrimSpace(input.DDCCode)
+
+	errs := map[string]string{}
+	if input.Code == "" {
+		errs["code"] = "분류 코드는 필수입니다"
+	} else if len(input.Code) > 20 {
+		errs["code"] = "분류 코드는 20자 이하여야 합니다"
+	}
+	if input.Name == "" {
+		errs["name"] = "분류명은 필수입니다"
+	} else if utf8.RuneCountInString(input.Name) > 100 {
+		errs["name"] = "분류명은 100자 이하여야 합니다"
+	}
+	if len(input.DDCCode) > 20 {
+		errs["ddc_code"] = "DDC 번호는 20자 이하여야 합니다"
+	}
+	if input.ParentCode != "" && input.ParentCode == input.Code {
+		errs["parent_code"] = "자기 자신을 상위 분류로 지정할 수 없습니다"
+	}
+	return errs
+}
+
+// 관리자: 분류 등록
+func handleCreateSubject(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var input SubjectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateSubjectInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM subjects WHERE code = ?", input.Code).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 등록에 실패했습니다"})
+		return
+	}
+	if exists > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 등록된 분류 코드입니다"})
+		return
+	}
+	if ok, err := subjectExists(input.ParentCode); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 등록에 실패했습니다"})
+		return
+	} else if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "상위 분류를 찾을 수 없습니다"})
+		return
+	}
+
+	_, err := db.Exec("INSERT INTO subjects (code, name, parent_code, ddc_code) VALUES (?, ?, ?, ?)",
+		input.Code, input.Name, nullIfEmpty(input.ParentCode), nullIfEmpty(input.DDCCode))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 등록에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v created subject %s (%s)", adminID, input.Code, input.Name)
+	c.JSON(http.StatusCreated, subjectResponse(input))
+}
+
+// 관리자: 분류명, 상위 분류, DDC 번호 수정 (코드는 변경 불가)
+func handleUpdateSubject(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	code := c.Param("code")
+
+	var input SubjectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	input.Code = code
+	if errs := validateSubjectInput(&input); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+
+	if ok, err := subjectExists(code); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 수정에 실패했습니다"})
+		return
+	} else if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분류를 찾을 수 없습니다"})
+		return
+	}
+
+	if input.ParentCode != "" {
+		// 하위 분류를 상위로 지정하면 순환이 생긴다
+		subtree, err := subjectSubtree(code)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 수정에 실패했습니다"})
+			return
+		}
+		for _, descendant := range subtree {
+			if descendant == input.ParentCode {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "하위 분류를 상위 분류로 지정할 수 없습니다"})
+				return
+			}
+		}
+		if ok, err := subjectExists(input.ParentCode); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 수정에 실패했습니다"})
+			return
+		} else if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "상위 분류를 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	_, err := db.Exec("UPDATE subjects SET name = ?, parent_code = ?, ddc_code = ? WHERE code = ?",
+		input.Name, nullIfEmpty(input.ParentCode), nullIfEmpty(input.DDCCode), code)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 수정에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v updated subject %s", adminID, code)
+	c.JSON(http.StatusOK, subjectResponse(input))
+}
+
+// 관리자: 분류 삭제 (하위 분류나 배정된 도서가 있으면 거부)
+func handleDeleteSubject(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	code := c.Param("code")
+
+	var children, books int
+	err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM subjects WHERE parent_code = ?),
+	                           (SELECT COUNT(*) FROM book_subjects WHERE subject_code = ?)`, code, code).Scan(&children, &books)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 삭제에 실패했습니다"})
+		return
+	}
+	if children > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "하위 분류가 있는 분류는 삭제할 수 없습니다"})
+		return
+	}
+	if books > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "도서가 배정된 분류는 삭제할 수 없습니다", "book_count": books})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM subjects WHERE code = ?", code)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 삭제에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "분류를 찾을 수 없습니다"})
+		return
+	}
+
+	log.Printf("Admin %v deleted subject %s", adminID, code)
+	c.JSON(http.StatusOK, gin.H{"message": "분류가 삭제되었습니다"})
+}
+
+// 관리자: 도서의 분류 배정을 요청한 목록으로 교체
+func handleSetBookSubjects(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	bookID := c.Param("id")
+
+	var req struct {
+		Codes []string `json:"codes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	codes := []string{}
+	for _, code := range req.Codes {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	codes = uniqueStrings(codes)
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM books WHERE id = ? FOR UPDATE", bookID).Scan(&exists); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	if len(codes) > 0 {
+		args := make([]interface{}, len(codes))
+		for i, code := range codes {
+			args[i] = code
+		}
+		var found int
+		query := "SELECT COUNT(*) FROM subjects WHERE code IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(codes)), ", ") + ")"
+		if err := tx.QueryRow(query, args...).Scan(&found); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+			return
+		}
+		if found != len(codes) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 분류가 포함되어 있습니다"})
+			return
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM book_subjects WHERE book_id = ?", bookID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+		return
+	}
+	for _, code := range codes {
+		if _, err := tx.Exec("INSERT INTO book_subjects (book_id, subject_code) VALUES (?, ?)", bookID, code); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 배정에 실패했습니다"})
+		return
+	}
+
+	subjects, err := fetchBookSubjects(bookID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "분류 조회에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v set subjects of book %s: %v", adminID, bookID, codes)
+	c.JSON(http.StatusOK, gin.H{"book_id": bookID, "subjects": subjects})
+}
+
+// 코드가 비어 있으면 확인할 분류가 없으므로 true
+func subjectExists(code string) (bool, error) {
+	if code == "" {
+		return true, nil
+	}
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM subjects WHERE code = ?", code).Scan(&count)
+	return count > 0, err
+}
+
+func subjectResponse(input SubjectInput) Subject {
+	s := Subject{Code: input.Code, Name: input.Name, DDCCode: input.DDCCode}
+	if input.ParentCode != "" {
+		s.ParentCode = &input.ParentCode
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+	// 짧은 접두어에서 후보를 모을 때 확인하는 최대 키 수
+	maxSuggestScan = 500
+)
+
+// 자동완성 필드 종류와 표시 이름
+var suggestTypeLabels = map[string]string{
+	"title":     "제목",
+	"author":    "저자",
+	"publisher": "출판사",
+}
+
+// 자동완성 항목 (제목이 한 권에만 해당하면 BookID를 함께 준다)
+type Suggestion struct {
+	Type   string `json:"type"`
+	Label  string `json:"label"`
+	Text   string `json:"text"`
+	BookID string `json:"book_id,omitempty"`
+	Count  int    `json:"count"`
+}
+
+type suggestKey struct {
+	key   string
+	entry int
+	// 전체 문자열의 시작에서 일치하는 키인지 (단어 중간 시작보다 우선)
+	leading bool
+}
+
+// 접두어 색인: 정렬된 키 목록을 이진 탐색한다.
+// 키는 자모로 분해한 문자열과 초성 문자열이며, 각 단어의 시작 위치마다 만든다.
+type suggestIndex struct {
+	entries []Suggestion
+	keys    []suggestKey
+}
+
+func buildSuggestIndex(docs map[string]catalogDoc) *suggestIndex {
+	idx := &suggestIndex{}
+	positions := map[string]int{}
+
+	add := func(fieldType, text, bookID string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		id := fieldType + "\x00" + strings.ToLower(text)
+		if pos, ok := positions[id]; ok {
+			idx.entries[pos].Count++
+			if idx.entries[pos].BookID != bookID {
+				idx.entries[pos].BookID = ""
+			}
+			return
+		}
+		positions[id] = len(idx.entries)
+		entry := Suggestion{Type: fieldType, Label: suggestTypeLabels[fieldType], Text: text, Count: 1}
+		if fieldType == "title" {
+			entry.BookID = bookID
+		}
+		idx.entries = append(idx.entries, entry)
+	}
+
+	for _, doc := range docs {
+		add("title", doc.Title, doc.ID)
+		add("author", doc.Author, doc.ID)
+		for _, name := range doc.Contributors {
+			add("author", name, doc.ID)
+		}
+		add("publisher", doc.Publisher, doc.ID)
+	}
+	for i, entry := range idx.entries {
+		words := strings.Fields(strings.ToLower(entry.Text))
+		for w := range words {
+			rest := strings.Join(words[w:], "")
+			seen := map[string]bool{}
+			for _, key := range []string{string(decomposeJamo(rest)), choseongString(rest)} {
+				if key == "" || seen[key] {
+					continue
+				}
+				seen[key] = true
+				idx.keys = append(idx.keys, suggestKey{key: key, entry: i, leading: w == 0})
+			}
+		}
+	}
+	sort.Slice(idx.keys, func(i, j int) bool { return idx.keys[i].key < idx.keys[j].key })
+	return idx
+}
+
+// 한글 음절을 초성으로 바꾼 문자열 (한글이 없으면 빈 문자열)
+func choseongString(text string) string {
+	var b strings.Builder
+	hasHangul := false
+	for _, r := range text {
+		if isHangulSyllable(r) {
+			hasHangul = true
+		}
+		b.WriteRune(choseongOf(r))
+	}
+	if !hasHangul {
+		return ""
+	}
+	return b.String()
+}
+
+// 접두어에 일치하는 항목을 우선순위 순으로 반환한다.
+// 문자열 시작 일치 > 단어 시작 일치, 그 다음 도서 수가 많은 순, 짧은 순.
+func (idx *suggestIndex) lookup(prefix string, limit int) []Suggestion {
+	key := string(decomposeJamo(strings.ToLower(strings.Join(strings.Fields(prefix), ""))))
+	if key == "" {
+		return []Suggestion{}
+	}
+
+	start := sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].key >= key })
+	best := map[int]bool{}
+	for i := start; i < len(idx.keys) && i-start < maxSuggestScan; i++ {
+		k := idx.keys[i]
+		if !strings.HasPrefix(k.key, key) {
+			break
+		}
+		best[k.entry] = best[k.entry] || k.leading
+	}
+
+	type candidate struct {
+		entry   int
+		leading bool
+	}
+	candidates := make([]candidate, 0, len(best))
+	for entry, leading := range best {
+		candidates = append(candidates, candidate{entry, leading})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.leading != b.leading {
+			return a.leading
+		}
+		ea, eb := idx.entries[a.entry], idx.entries[b.entry]
+		if ea.Count != eb.Count {
+			return ea.Count > eb.Count
+		}
+		if len(ea.Text) != len(eb.Text) {
+			return len(ea.Text) < len(eb.Text)
+		}
+		return ea.Text < eb.Text
+	})
+
+	suggestions := []Suggestion{}
+	for _, c := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, idx.entries[c.entry])
+	}
+	return suggestions
+}
+
+// 자동완성 색인 (서지 변경 후 첫 요청에서 다시 만든다)
+func (idx *searchIndex) suggest(prefix string, limit int) []Suggestion {
+	idx.mu.RLock()
+	s := idx.suggestions
+	idx.mu.RUnlock()
+
+	if s == nil {
+		idx.mu.Lock()
+		if idx.suggestions == nil {
+			idx.suggestions = buildSuggestIndex(idx.docs)
+		}
+		s = idx.suggestions
+		idx.mu.Unlock()
+	}
+	return s.lookup(prefix, limit)
+}
+
+// 검색어 자동완성
+func handleSuggestBooks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuggestLimit)))
+	if err != nil || limit < 1 || limit > maxSuggestLimit {
+		limit = defaultSuggestLimit
+	}
+
+	suggestions := []Suggestion{}
+	if q != "" {
+		suggestions = searchIdx.suggest(q, limit)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"q": q, "suggestions": suggestions})
+}
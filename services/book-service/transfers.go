@@ -0,0 +1,662 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errTransferNotFound = errors.New("이관을 찾을 수 없습니다")
+
+// 이관 종류 (migrations/015_copy_transfers.sql 참고)
+var transferKinds = map[string]string{
+	"return":   "소속 분관 복귀",
+	"hold":     "예약 도서 배송",
+	"relocate": "소속 변경",
+}
+
+var transferStatuses = map[string]string{
+	"requested":  "발송 대기",
+	"in_transit": "이동 중",
+	"received":   "도착",
+	"cancelled":  "취소",
+}
+
+// 분관 간 복본 이관
+type Transfer struct {
+	ID             int        `json:"id"`
+	CopyID         int        `json:"copy_id"`
+	Barcode        string     `json:"barcode"`
+	BookID         string     `json:"book_id"`
+	BookTitle      string     `json:"book_title"`
+	BookAuthor     string     `json:"book_author"`
+	CopyNumber     int        `json:"copy_number"`
+	FromBranchID   *int       `json:"from_branch_id"`
+	FromBranchName string     `json:"from_branch_name"`
+	ToBranchID     int        `json:"to_branch_id"`
+	ToBranchName   string     `json:"to_branch_name"`
+	Kind           string     `json:"kind"`
+	ReservationID  *int       `json:"reservation_id"`
+	Status         string     `json:"status"`
+	Note           string     `json:"note,omitempty"`
+	RequestedBy    string     `json:"requested_by"`
+	RequestedAt    time.Time  `json:"requested_at"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	ReceivedBy     string     `json:"received_by,omitempty"`
+	ReceivedAt     *time.Time `json:"received_at"`
+}
+
+// 발송 목록: 도착 분관별로 묶은 이관
+type TransferManifest struct {
+	FromBranchID *int                    `json:"from_branch_id"`
+	Status       string                  `json:"status"`
+	GeneratedAt  time.Time               `json:"generated_at"`
+	Total        int                     `json:"total"`
+	Destinations []TransferManifestGroup `json:"destinations"`
+}
+
+type TransferManifestGroup struct {
+	BranchID int        `json:"branch_id"`
+	Name     string     `json:"name"`
+	Items    []Transfer `json:"items"`
+}
+
+const transferSelect = `SELECT t.id, t.copy_id, COALESCE(bc.barcode, ''), bc.book_id, b.title, b.author, bc.copy_number,
+       t.from_branch_id, COALESCE(fb.name, ''), t.to_branch_id, tb.name, t.kind, t.reservation_id, t.status,
+       COALESCE(t.note, ''), t.requested_by, t.requested_at, t.shipped_at, COALESCE(t.received_by, ''), t.received_at
+FROM copy_transfers t
+JOIN book_copies bc ON bc.id = t.copy_id
+JOIN books b ON b.id = bc.book_id
+LEFT JOIN branches fb ON fb.id = t.from_branch_id
+JOIN branches tb ON tb.id = t.to_branch_id`
+
+var transferSort = sortSpec{Columns: []string{"t.id"}, Desc: true}
+
+func scanTransfer(row interface {
+	Scan(dest ...interface{}) error
+}) (Transfer, error) {
+	var t Transfer
+	err := row.Scan(&t.ID, &t.CopyID, &t.Barcode, &t.BookID, &t.BookTitle, &t.BookAuthor, &t.CopyNumber,
+		&t.FromBranchID, &t.FromBranchName, &t.ToBranchID, &t.ToBranchName, &t.Kind, &t.ReservationID, &t.Status,
+		&t.Note, &t.RequestedBy, &t.RequestedAt, &t.ShippedAt, &t.ReceivedBy, &t.ReceivedAt)
+	return t, err
+}
+
+func fetchTransfer(id int) (Transfer, error) {
+	return scanTransfer(db.QueryRow(transferSelect+" WHERE t.id = ?", id))
+}
+
+// 진행 상태를 바꿀 이관과 그 복본을 잠근다.
+type lockedTransfer struct {
+	ID            int
+	CopyID        int
+	ToBranchID    int
+	Kind          string
+	Status        string
+	ReservationID sql.NullInt64
+	CopyStatus    string
+	HomeBranchID  sql.NullInt64
+}
+
+func lockTransfer(tx *sql.Tx, id string) (lockedTransfer, error) {
+	var t lockedTransfer
+	err := tx.QueryRow(`SELECT id, copy_id, to_branch_id, kind, status, reservation_id
+	                    FROM copy_transfers WHERE id = ? FOR UPDATE`, id).
+		Scan(&t.ID, &t.CopyID, &t.ToBranchID, &t.Kind, &t.Status, &t.ReservationID)
+	if err == sql.ErrNoRows {
+		return t, errTransferNotFound
+	}
+	if err != nil {
+		return t, err
+	}
+	err = tx.QueryRow("SELECT status, branch_id FROM book_copies WHERE id = ? FOR UPDATE", t.CopyID).
+		Scan(&t.CopyStatus, &t.HomeBranchID)
+	return t, err
+}
+
+// 복본이 지금 있는 분관. 예약 도서로 다른 분관에 가 있는 복본은 마지막으로 도착한 분관이다.
+func currentCopyBranch(tx *sql.Tx, copyID int, status string, home sql.NullInt64) (int, error) {
+	if status == "on_hold" {
+		var branch int
+		err := tx.QueryRow(`SELECT to_branch_id FROM copy_transfers
+		                    WHERE copy_id = ? AND status = 'received'
+		                    ORDER BY received_at DESC, id DESC LIMIT 1`, copyID).Scan(&branch)
+		if err == nil {
+			return branch, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+	if home.Valid {
+		return int(home.Int64), nil
+	}
+	return 0, nil
+}
+
+// 이관 발송: 복본을 이동 중으로 바꾼다.
+func shipTransfer(tx *sql.Tx, transferID, copyID int, copyStatus, actor string) error {
+	if _, err := tx.Exec("UPDATE book_copies SET status = 'in_transit' WHERE id = ?", copyID); err != nil {
+		return err
+	}
+	if err := recordCopyStatus(tx, int64(copyID), copyStatus, "in_transit", "transfer", "이관 #"+strconv.Itoa(transferID), actor); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE copy_transfers SET status = 'in_transit', shipped_at = NOW() WHERE id = ?", transferID)
+	return err
+}
+
+// 관리자: 이관 목록 (최신순, status/from_branch_id/to_branch_id/copy_id 필터)
+func handleGetTransfers(c *gin.Context) {
+	pageReq, err := parsePageRequest(c, "transfers", transferSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if status := c.Query("status"); status != "" {
+		if _, ok := transferStatuses[status]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "알 수 없는 상태입니다: " + status})
+			return
+		}
+		where += " AND t.status = ?"
+		args = append(args, status)
+	}
+	for _, filter := range []string{"from_branch_id", "to_branch_id", "copy_id"} {
+		if value := c.Query(filter); value != "" {
+			where += " AND t." + filter + " = ?"
+			args = append(args, value)
+		}
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM copy_transfers t"+where, args...).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 목록 조회에 실패했습니다"})
+		return
+	}
+
+	query := transferSelect + where
+	if pageReq.Cursor != nil {
+		query += " AND " + transferSort.after()
+		args = append(args, pageReq.cursorArgs()...)
+	}
+	limitClause, limitArgs := pageReq.limitClause()
+	query += transferSort.orderBy() + limitClause
+	args = append(args, limitArgs...)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 목록 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	transfers := []Transfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		transfers = append(transfers, t)
+	}
+
+	count := len(transfers)
+	if count > pageReq.Limit {
+		transfers = transfers[:pageReq.Limit]
+	}
+	c.JSON(http.StatusOK, newPageResponse(transfers, count, total, pageReq, "transfers", func() []string {
+		return []string{strconv.Itoa(transfers[len(transfers)-1].ID)}
+	}))
+}
+
+// 관리자: 이관 요청.
+// 예약 ID가 있으면 예약의 받을 분관으로 보내는 예약 도서 배송, 도착 분관을 생략하거나 소속 분관이면 복귀,
+// 다른 분관이면 소속 변경이다. ship=true이면 바로 발송 처리한다.
+func handleCreateTransfer(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var req struct {
+		CopyID        int    `json:"copy_id"`
+		Barcode       string `json:"barcode"`
+		ToBranchID    int    `json:"to_branch_id"`
+		ReservationID int    `json:"reservation_id"`
+		Note          string `json:"note"`
+		Ship          bool   `json:"ship"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.CopyID == 0 && req.Barcode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "copy_id 또는 barcode가 필요합니다"})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len([]rune(req.Note)) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": map[string]string{"note": "메모는 255자 이하여야 합니다"}})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	copyQuery := "SELECT id, book_id, status, branch_id FROM book_copies WHERE id = ? FOR UPDATE"
+	copyKey := interface{}(req.CopyID)
+	if req.CopyID == 0 {
+		code, err := normalizeBarcode(req.Barcode)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		copyQuery = "SELECT id, book_id, status, branch_id FROM book_copies WHERE barcode = ? FOR UPDATE"
+		copyKey = code
+	}
+	var copyID int
+	var bookID, status string
+	var home sql.NullInt64
+	err = tx.QueryRow(copyQuery, copyKey).Scan(&copyID, &bookID, &status, &home)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "복본을 찾을 수 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+	if status != "available" && status != "on_hold" {
+		c.JSON(http.StatusConflict, gin.H{"error": copyStatuses[status] + " 상태의 복본은 이관할 수 없습니다", "copy_status": status})
+		return
+	}
+
+	var openID int
+	err = tx.QueryRow(`SELECT id FROM copy_transfers WHERE copy_id = ? AND status IN ('requested', 'in_transit') LIMIT 1`, copyID).Scan(&openID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "진행 중인 이관이 있는 복본입니다", "transfer_id": openID})
+		return
+	}
+	if err != sql.ErrNoRows {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+
+	from, err := currentCopyBranch(tx, copyID, status, home)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+
+	kind, to := "relocate", req.ToBranchID
+	switch {
+	case req.ReservationID > 0:
+		var reservationBook, reservationStatus string
+		var pickup sql.NullInt64
+		err := tx.QueryRow("SELECT book_id, status, pickup_branch_id FROM reservations WHERE id = ?", req.ReservationID).
+			Scan(&reservationBook, &reservationStatus, &pickup)
+		if err == sql.ErrNoRows || (err == nil && (reservationBook != bookID || reservationStatus != "active")) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "이 도서의 진행 중인 예약이 아닙니다"})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+			return
+		}
+		if !pickup.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "받을 분관이 정해지지 않은 예약입니다"})
+			return
+		}
+		kind, to = "hold", int(pickup.Int64)
+	case req.ToBranchID == 0 || (home.Valid && req.ToBranchID == int(home.Int64)):
+		if !home.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "소속 분관이 없는 복본입니다. 도착 분관을 지정해 주세요"})
+			return
+		}
+		kind, to = "return", int(home.Int64)
+	default:
+		var active bool
+		err := tx.QueryRow("SELECT active FROM branches WHERE id = ? AND level = 'branch'", req.ToBranchID).Scan(&active)
+		if err == sql.ErrNoRows || (err == nil && !active) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "도착 분관을 찾을 수 없습니다"})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+			return
+		}
+	}
+	if to == from {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "이미 도착 분관에 있는 복본입니다"})
+		return
+	}
+
+	result, err := tx.Exec(`INSERT INTO copy_transfers (copy_id, from_branch_id, to_branch_id, kind, reservation_id, note, requested_by)
+	                        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		copyID, nullIfZero(from), to, kind, nullIfZero(req.ReservationID), nullIfEmpty(req.Note), actor)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+	id64, _ := result.LastInsertId()
+	id := int(id64)
+
+	if req.Ship {
+		if err := shipTransfer(tx, id, copyID, status, actor); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 요청에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s requested %s transfer %d for copy %d (%d -> %d)", actor, kind, id, copyID, from, to)
+	transfer, err := fetchTransfer(id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+		return
+	}
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// 관리자: 이관 발송 (요청 -> 이동 중)
+func handleShipTransfer(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 발송에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := lockTransfer(tx, c.Param("id"))
+	if err == errTransferNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 발송에 실패했습니다"})
+		return
+	}
+	if t.Status != "requested" {
+		c.JSON(http.StatusConflict, gin.H{"error": transferStatuses[t.Status] + " 상태의 이관은 발송할 수 없습니다"})
+		return
+	}
+	if t.CopyStatus != "available" && t.CopyStatus != "on_hold" {
+		c.JSON(http.StatusConflict, gin.H{"error": copyStatuses[t.CopyStatus] + " 상태의 복본은 발송할 수 없습니다", "copy_status": t.CopyStatus})
+		return
+	}
+
+	if err := shipTransfer(tx, t.ID, t.CopyID, t.CopyStatus, actor); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 발송에 실패했습니다"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 발송에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s shipped transfer %d (copy %d)", actor, t.ID, t.CopyID)
+	respondTransfer(c, t.ID, nil)
+}
+
+// 관리자: 이관 도착 처리.
+// 소속 변경이면 복본의 분관을 바꾸고, 예약 도서이면 예약이 살아 있는 동안 예약 보관(on_hold)으로 둔다.
+// 예약이 끝난 예약 도서가 소속 분관이 아닌 곳에 도착하면 바로 소속 분관으로 복귀 이관을 만든다.
+func handleReceiveTransfer(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := lockTransfer(tx, c.Param("id"))
+	if err == errTransferNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+		return
+	}
+	if t.Status != "in_transit" {
+		c.JSON(http.StatusConflict, gin.H{"error": transferStatuses[t.Status] + " 상태의 이관은 도착 처리할 수 없습니다"})
+		return
+	}
+	if t.CopyStatus != "in_transit" {
+		c.JSON(http.StatusConflict, gin.H{"error": copyStatuses[t.CopyStatus] + " 상태의 복본은 도착 처리할 수 없습니다", "copy_status": t.CopyStatus})
+		return
+	}
+
+	to, note := "available", "이관 #"+strconv.Itoa(t.ID)
+	home := int(t.HomeBranchID.Int64)
+	returnHome := false
+	switch t.Kind {
+	case "relocate":
+		if _, err := tx.Exec("UPDATE book_copies SET branch_id = ?, location_id = NULL WHERE id = ?", t.ToBranchID, t.CopyID); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+			return
+		}
+	case "hold":
+		var reservationStatus string
+		err := tx.QueryRow("SELECT status FROM reservations WHERE id = ?", t.ReservationID.Int64).Scan(&reservationStatus)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+			return
+		}
+		if reservationStatus == "active" {
+			to = "on_hold"
+			note += ", 예약 #" + strconv.FormatInt(t.ReservationID.Int64, 10)
+		} else if t.HomeBranchID.Valid && home != t.ToBranchID {
+			returnHome = true
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE copy_transfers SET status = 'received', received_by = ?, received_at = NOW()
+	                      WHERE id = ?`, actor, t.ID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+		return
+	}
+
+	var next gin.H
+	if returnHome {
+		// 복본은 이동 중 상태 그대로 소속 분관으로 다시 보낸다
+		result, err := tx.Exec(`INSERT INTO copy_transfers (copy_id, from_branch_id, to_branch_id, kind, status, note, requested_by, shipped_at)
+		                        VALUES (?, ?, ?, 'return', 'in_transit', ?, ?, NOW())`,
+			t.CopyID, t.ToBranchID, home, "예약이 끝난 예약 도서 복귀", actor)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+			return
+		}
+		nextID, _ := result.LastInsertId()
+		next = gin.H{"next_transfer_id": nextID}
+	} else {
+		if _, err := tx.Exec("UPDATE book_copies SET status = ? WHERE id = ?", to, t.CopyID); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+			return
+		}
+		if err := recordCopyStatus(tx, int64(t.CopyID), "in_transit", to, "received", note, actor); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 도착 처리에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s received transfer %d (copy %d)", actor, t.ID, t.CopyID)
+	respondTransfer(c, t.ID, next)
+}
+
+// 관리자: 발송 전 이관 취소
+func handleCancelTransfer(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	result, err := db.Exec("UPDATE copy_transfers SET status = 'cancelled' WHERE id = ? AND status = 'requested'", c.Param("id"))
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 취소에 실패했습니다"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var status string
+		err := db.QueryRow("SELECT status FROM copy_transfers WHERE id = ?", c.Param("id")).Scan(&status)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": errTransferNotFound.Error()})
+			return
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 취소에 실패했습니다"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": transferStatuses[status] + " 상태의 이관은 취소할 수 없습니다"})
+		return
+	}
+
+	log.Printf("Admin %s cancelled transfer %s", actor, c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{"message": "이관이 취소되었습니다"})
+}
+
+func respondTransfer(c *gin.Context, id int, extra gin.H) {
+	transfer, err := fetchTransfer(id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이관 조회에 실패했습니다"})
+		return
+	}
+	response := gin.H{"transfer": transfer}
+	for key, value := range extra {
+		response[key] = value
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// 관리자: 발송 목록 (도착 분관별).
+// status는 requested(발송할 복본, 기본값), in_transit(이동 중), open(둘 다), format은 json|csv.
+func handleGetTransferManifest(c *gin.Context) {
+	status := c.DefaultQuery("status", "requested")
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 형식입니다: " + format})
+		return
+	}
+
+	where := " WHERE t.status IN ('requested', 'in_transit')"
+	args := []interface{}{}
+	switch status {
+	case "open":
+	case "requested", "in_transit":
+		where = " WHERE t.status = ?"
+		args = append(args, status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "알 수 없는 상태입니다: " + status})
+		return
+	}
+
+	manifest := TransferManifest{Status: status, GeneratedAt: time.Now(), Destinations: []TransferManifestGroup{}}
+	if value := c.Query("from_branch_id"); value != "" {
+		fromID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "분관을 찾을 수 없습니다"})
+			return
+		}
+		manifest.FromBranchID = &fromID
+		where += " AND t.from_branch_id = ?"
+		args = append(args, fromID)
+	}
+	if value := c.Query("to_branch_id"); value != "" {
+		where += " AND t.to_branch_id = ?"
+		args = append(args, value)
+	}
+
+	rows, err := db.Query(transferSelect+where+" ORDER BY tb.sort_order, tb.id, b.title, bc.copy_number, t.id", args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "발송 목록 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		groups := manifest.Destinations
+		if len(groups) == 0 || groups[len(groups)-1].BranchID != t.ToBranchID {
+			manifest.Destinations = append(groups, TransferManifestGroup{BranchID: t.ToBranchID, Name: t.ToBranchName, Items: []Transfer{}})
+		}
+		last := &manifest.Destinations[len(manifest.Destinations)-1]
+		last.Items = append(last.Items, t)
+		manifest.Total++
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, manifest)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="transfer-manifest-`+manifest.GeneratedAt.Format("20060102")+`.csv"`)
+	c.Status(http.StatusOK)
+	c.Writer.WriteString("\xef\xbb\xbf")
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"to_branch", "transfer_id", "barcode", "title", "author", "copy_number", "from_branch", "kind", "status", "requested_at", "note"})
+	for _, group := range manifest.Destinations {
+		for _, t := range group.Items {
+			w.Write([]string{
+				group.Name, strconv.Itoa(t.ID), t.Barcode, t.BookTitle, t.BookAuthor, strconv.Itoa(t.CopyNumber),
+				t.FromBranchName, transferKinds[t.Kind], transferStatuses[t.Status],
+				t.RequestedAt.Format("2006-01-02 15:04"), t.Note,
+			})
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 제적 사유
+var withdrawalReasons = map[string]string{
+	"worn":      "노후/훼손",
+	"damaged":   "수선 불가 파손",
+	"lost":      "분실 후 미회수",
+	"outdated":  "내용 노후",
+	"duplicate": "복본 과다",
+	"low_use":   "이용 저조",
+	"other":     "기타",
+}
+
+// 제적 후보 복본과 점수 계산 근거
+type WeedingCandidate struct {
+	CopyID            int            `json:"copy_id"`
+	Barcode           string         `json:"barcode"`
+	BookID            string         `json:"book_id"`
+	BookTitle         string         `json:"book_title"`
+	BookAuthor        string         `json:"book_author"`
+	Year              int            `json:"year"`
+	CopyNumber        int            `json:"copy_number"`
+	Status            string         `json:"status"`
+	BranchID          *int           `json:"branch_id"`
+	BranchName        string         `json:"branch_name,omitempty"`
+	LocationPath      string         `json:"location_path,omitempty"`
+	AcquiredDate      *time.Time     `json:"acquired_date"`
+	LastBorrowedAt    *time.Time     `json:"last_borrowed_at"`
+	IdleDays          int            `json:"idle_days"`
+	Circulations      int            `json:"circulations"`
+	TitleCirculations int            `json:"title_circulations"`
+	TitleCopies       int            `json:"title_copies"`
+	Score             float64        `json:"score"`
+	Factors           WeedingFactors `json:"factors"`
+}
+
+// 점수 항목 (합계가 Score, 최대 100)
+type WeedingFactors struct {
+	Idle        float64 `json:"idle"`
+	Circulation float64 `json:"circulation"`
+	Age         float64 `json:"age"`
+	Duplicates  float64 `json:"duplicates"`
+}
+
+const (
+	maxWeedingCandidates = 500
+	weedingIdleFullDays  = 5 * 365 // 이만큼 대출이 없으면 미이용 점수 만점
+	weedingAgeFullYears  = 30      // 출판 후 이만큼 지나면 출판 연도 점수 만점
+	weedingDupFullCopies = 5       // 같은 도서 복본이 이만큼이면 복본 수 점수 만점
+)
+
+// 제적 후보 점수.
+// 미이용 기간(마지막 대출 또는 입수일부터) 40점, 대출 횟수가 적을수록 25점, 출판 연도가 오래될수록 20점,
+// 같은 도서의 복본이 많을수록 15점.
+func weedingScore(idleDays, circulations, year, titleCopies int, now time.Time) WeedingFactors {
+	f := WeedingFactors{
+		Idle:        40 * math.Min(float64(idleDays)/weedingIdleFullDays, 1),
+		Circulation: 25 / float64(1+circulations),
+	}
+	if year > 0 && year <= now.Year() {
+		f.Age = 20 * math.Min(float64(now.Year()-year)/weedingAgeFullYears, 1)
+	}
+	if titleCopies > 1 {
+		f.Duplicates = 15 * math.Min(float64(titleCopies-1)/(weedingDupFullCopies-1), 1)
+	}
+	return f
+}
+
+func (f WeedingFactors) total() float64 {
+	return math.Round((f.Idle+f.Circulation+f.Age+f.Duplicates)*10) / 10
+}
+
+// 복본 한 권 제적. 제적할 수 없으면 그 이유를 돌려준다 (문제가 없으면 빈 문자열, 복본이 없으면 sql.ErrNoRows).
+// 발송 전인 이관 요청은 함께 취소한다.
+func withdrawCopy(tx *sql.Tx, copyID int, reason, note, date, actor string) (string, error) {
+	var status string
+	err := tx.QueryRow("SELECT status FROM book_copies WHERE id = ? FOR UPDATE", copyID).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	if status == "withdrawn" {
+		return "이미 제적된 복본입니다", nil
+	}
+	if !copyTransitionAllowed(status, "withdrawn") {
+		return copyStatuses[status] + " 상태의 복본은 제적할 수 없습니다", nil
+	}
+
+	_, err = tx.Exec(`UPDATE book_copies
+	                  SET status = 'withdrawn', withdrawn_at = ?, withdrawn_reason = ?, withdrawn_note = ?, withdrawn_by = ?
+	                  WHERE id = ?`, date, reason, nullIfEmpty(note), actor, copyID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec("UPDATE copy_transfers SET status = 'cancelled' WHERE copy_id = ? AND status = 'requested'", copyID); err != nil {
+		return "", err
+	}
+
+	historyNote := withdrawalReasons[reason]
+	if note != "" {
+		historyNote += ": " + note
+	}
+	return "", recordCopyStatus(tx, int64(copyID), status, "withdrawn", "weeded", truncateRunes(historyNote, 255), actor)
+}
+
+// 제적 요청 확인: 사유 코드, 메모 길이, 제적일(생략하면 오늘)
+func validateWithdrawal(reason, note, date *string) map[string]string {
+	errs := map[string]string{}
+	*note = strings.TrimSpace(*note)
+	if _, ok := withdrawalReasons[*reason]; !ok {
+		errs["reason"] = "알 수 없는 제적 사유입니다"
+	}
+	if len([]rune(*note)) > 255 {
+		errs["note"] = "메모는 255자 이하여야 합니다"
+	}
+	if *date == "" {
+		*date = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", *date); err != nil {
+		errs["withdrawn_date"] = "날짜는 YYYY-MM-DD 형식이어야 합니다"
+	}
+	return errs
+}
+
+// 관리자: 복본 여러 권 제적 (제적 후보 목록에서 고른 복본 등).
+// 제적할 수 없는 복본은 건너뛰고 이유를 돌려준다.
+func handleWithdrawCopies(c *gin.Context) {
+	actor := c.GetString("user_id")
+
+	var req struct {
+		CopyIDs       []int  `json:"copy_ids" binding:"required"`
+		Reason        string `json:"reason" binding:"required"`
+		Note          string `json:"note"`
+		WithdrawnDate string `json:"withdrawn_date"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if errs := validateWithdrawal(&req.Reason, &req.Note, &req.WithdrawnDate); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "입력값이 올바르지 않습니다", "fields": errs})
+		return
+	}
+	if len(req.CopyIDs) > maxWeedingCandidates {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "한 번에 " + strconv.Itoa(maxWeedingCandidates) + "권까지 제적할 수 있습니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "제적 처리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	withdrawn := []int{}
+	skipped := map[string]string{}
+	for _, id := range req.CopyIDs {
+		message, err := withdrawCopy(tx, id, req.Reason, req.Note, req.WithdrawnDate, actor)
+		if err == sql.ErrNoRows {
+			message, err = "복본을 찾을 수 없습니다", nil
+		}
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "제적 처리에 실패했습니다"})
+			return
+		}
+		if message != "" {
+			skipped[strconv.Itoa(id)] = message
+			continue
+		}
+		withdrawn = append(withdrawn, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "제적 처리에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s withdrew %d copies (%s)", actor, len(withdrawn), req.Reason)
+	c.JSON(http.StatusOK, gin.H{"withdrawn": withdrawn, "skipped": skipped})
+}
+
+// 관리자: 제적 후보 목록 (점수 높은 순).
+// 대여 가능, 수선 중, 분실 상태의 복본이 대상이며 branch_id, min_score, limit(기본 50, 최대 500)로 거른다.
+func handleGetWeedingCandidates(c *gin.Context) {
+	limit := 50
+	if value, err := strconv.Atoi(c.Query("limit")); err == nil && value >= 1 && value <= maxWeedingCandidates {
+		limit = value
+	}
+	minScore, _ := strconv.ParseFloat(c.Query("min_score"), 64)
+
+	where := " WHERE bc.status IN ('available', 'maintenance', 'lost')"
+	args := []interface{}{}
+	if branchID := c.Query("branch_id"); branchID != "" {
+		where += " AND bc.branch_id = ?"
+		args = append(args, branchID)
+	}
+
+	_, byID, err := loadBranchTree()
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "제적 후보 조회에 실패했습니다"})
+		return
+	}
+
+	rows, err := db.Query(`SELECT bc.id, COALESCE(bc.barcode, ''), bc.book_id, b.title, b.author, COALESCE(b.year, 0), bc.copy_number,
+	                              bc.status, bc.branch_id, bc.location_id, bc.acquired_date,
+	                              (SELECT MAX(br.borrowed_at) FROM borrows br WHERE br.copy_id = bc.id),
+	                              (SELECT COUNT(*) FROM borrows br WHERE br.copy_id = bc.id),
+	                              (SELECT COUNT(*) FROM borrows br WHERE br.book_id = bc.book_id),
+	                              (SELECT COUNT(*) FROM book_copies d WHERE d.book_id = bc.book_id AND d.status <> 'withdrawn')
+	                       FROM book_copies bc
+	                       JOIN books b ON b.id = bc.book_id`+where, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "제적 후보 조회에 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	candidates := []WeedingCandidate{}
+	for rows.Next() {
+		var w WeedingCandidate
+		var locationID *int
+		if err := rows.Scan(&w.CopyID, &w.Barcode, &w.BookID, &w.BookTitle, &w.BookAuthor, &w.Year, &w.CopyNumber,
+			&w.Status, &w.BranchID, &locationID, &w.AcquiredDate, &w.LastBorrowedAt,
+			&w.Circulations, &w.TitleCirculations, &w.TitleCopies); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+
+		// 마지막 대출이 없으면 입수일부터, 입수일도 없으면 미이용 기간을 모르는 것으로 본다
+		since := w.LastBorrowedAt
+		if since == nil {
+			since = w.AcquiredDate
+		}
+		if since != nil && since.Before(now) {
+			w.IdleDays = int(now.Sub(*since).Hours() / 24)
+		}
+		w.Factors = weedingScore(w.IdleDays, w.Circulations, w.Year, w.TitleCopies, now)
+		w.Score = w.Factors.total()
+		if w.Score < minScore {
+			continue
+		}
+
+		if w.BranchID != nil {
+			if branch, ok := byID[*w.BranchID]; ok {
+				w.BranchName = branch.Name
+			}
+		}
+		if locationID != nil {
+			w.LocationPath = branchPath(byID, *locationID)
+		}
+		candidates = append(candidates, w)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].IdleDays != candidates[j].IdleDays {
+			return candidates[i].IdleDays > candidates[j].IdleDays
+		}
+		return candidates[i].CopyID < candidates[j].CopyID
+	})
+	total := len(candidates)
+	if total > limit {
+		candidates = candidates[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"candidates": candidates, "total": total, "reasons": withdrawalReasons})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	errCopyNotFound     = errors.New("복본을 찾을 수 없습니다")
+	errCopyNotAvailable = errors.New("대여 가능한 복본이 아닙니다")
+)
+
+// 복본 상태 변경 이력 기록 (사유 코드와 허용 전이는 book-service의 copy_status.go 참고)
+func recordCopyStatus(tx *sql.Tx, copyID int, from, to, reason, note, actor string) error {
+	_, err := tx.Exec(`INSERT INTO copy_status_history (copy_id, from_status, to_status, reason, note, actor)
+	                   VALUES (?, ?, ?, ?, ?, ?)`, copyID, from, to, reason, nullIfEmpty(note), actor)
+	return err
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// 대여할 복본을 잠근다. 바코드가 있으면 그 복본을, 없으면 이 이용자의 예약으로 보관 중(on_hold)인 복본,
+// 그다음 대여 가능한 아무 복본을 고른다. 고른 복본의 현재 상태도 돌려준다.
+// branchID가 있으면 그 분관 소속 복본 중에서 고른다 (바코드로 지정한 복본과 예약 보관 복본은 분관을 따지지 않는다).
+func lockCopyForCheckout(tx *sql.Tx, bookID, barcode, userID string, branchID int) (int, string, error) {
+	var copyID int
+	if barcode == "" {
+		err := tx.QueryRow(heldCopyQuery+" LIMIT 1 FOR UPDATE", bookID, userID).Scan(&copyID)
+		if err == nil {
+			return copyID, "on_hold", nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, "", err
+		}
+
+		query := `SELECT id FROM book_copies WHERE book_id = ? AND status = 'available'`
+		args := []interface{}{bookID}
+		if branchID > 0 {
+			query += " AND branch_id = ?"
+			args = append(args, branchID)
+		}
+		err = tx.QueryRow(query+" LIMIT 1 FOR UPDATE", args...).Scan(&copyID)
+		if err == sql.ErrNoRows {
+			return 0, "", errCopyNotAvailable
+		}
+		return copyID, "available", err
+	}
+
+	var copyBookID, status string
+	err := tx.QueryRow("SELECT id, book_id, status FROM book_copies WHERE barcode = ? FOR UPDATE", normalizeBarcode(barcode)).
+		Scan(&copyID, &copyBookID, &status)
+	if err == sql.ErrNoRows {
+		return 0, "", errCopyNotFound
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if copyBookID != bookID {
+		return 0, "", errCopyNotAvailable
+	}
+	if status == "on_hold" {
+		var heldID int
+		err := tx.QueryRow(heldCopyQuery+" AND bc.id = ?", bookID, userID, copyID).Scan(&heldID)
+		if err == sql.ErrNoRows {
+			return 0, "", errCopyNotAvailable
+		}
+		return copyID, status, err
+	}
+	if status != "available" {
+		return 0, "", errCopyNotAvailable
+	}
+	return copyID, status, nil
+}
+
+// 이용자의 진행 중인 예약으로 이관되어 보관 중인 복본 (인자: 도서 ID, 이용자)
+const heldCopyQuery = `SELECT bc.id FROM book_copies bc
+                       JOIN copy_transfers t ON t.copy_id = bc.id AND t.kind = 'hold' AND t.status = 'received'
+                       JOIN reservations r ON r.id = t.reservation_id AND r.status = 'active'
+                       WHERE bc.book_id = ? AND bc.status = 'on_hold' AND r.user_id = ?`
+
+// 대출한 복본을 'borrowed' 상태로 바꾸고 이력을 남긴다.
+// 이용자가 이 도서에 걸어 둔 진행 중인 예약은 대출과 함께 완료(fulfilled) 처리한다.
+func checkoutCopy(tx *sql.Tx, copyID int, from, userID, bookID, actor string) error {
+	if _, err := tx.Exec("UPDATE book_copies SET status = 'borrowed' WHERE id = ?", copyID); err != nil {
+		return err
+	}
+	if err := recordCopyStatus(tx, copyID, from, "borrowed", "checkout", "", actor); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE reservations SET status = 'fulfilled' WHERE user_id = ? AND book_id = ? AND status = 'active'", userID, bookID)
+	return err
+}
+
+// 반납 처리 결과. 다른 분관으로 보내야 하는 복본은 이관(TransferID)과 함께 이동 중(in_transit)이 된다.
+type returnResult struct {
+	CopyID        int  `json:"copy_id,omitempty"`
+	InTransit     bool `json:"in_transit"`
+	HomeBranchID  int  `json:"home_branch_id,omitempty"`
+	TransferID    int  `json:"transfer_id,omitempty"`
+	ToBranchID    int  `json:"to_branch_id,omitempty"`
+	ReservationID int  `json:"reservation_id,omitempty"`
+}
+
+// 대출 기록을 반납 처리하고 빌려 간 복본을 돌려놓는다.
+// copy_id가 없는 예전 대출은 다른 대출에 연결되지 않은, 대여 중인 같은 도서의 복본을 대신 돌려놓는다.
+// returnBranch(반납 분관, 0이면 모름)를 알면 복본을 보낼 곳을 정한다.
+//   - 가장 먼저 들어온 (아직 알림을 받지 않은) 예약의 받을 분관이 반납 분관과 다르면 그 분관으로 예약 도서 이관
+//   - 아니면 소속 분관과 다를 때 소속 분관으로 복귀 이관
+//
+// 이관하는 복본은 'in_transit', 나머지는 'available'이 된다.
+func returnLoan(tx *sql.Tx, borrowID int, bookID string, copyID sql.NullInt64, returnBranch int, actor string) (returnResult, error) {
+	result := returnResult{}
+	released := 0
+	if copyID.Valid {
+		var status string
+		err := tx.QueryRow("SELECT status FROM book_copies WHERE id = ? FOR UPDATE", copyID.Int64).Scan(&status)
+		if err != nil && err != sql.ErrNoRows {
+			return result, err
+		}
+		if status == "borrowed" {
+			released = int(copyID.Int64)
+		} else if err == nil {
+			// 분실 처리 등으로 이미 상태가 바뀐 복본은 그대로 둔다
+			log.Printf("Borrow %d returned but copy %d is %s", borrowID, copyID.Int64, status)
+		}
+	} else {
+		err := tx.QueryRow(`SELECT bc.id FROM book_copies bc
+		                    WHERE bc.book_id = ? AND bc.status = 'borrowed'
+		                      AND NOT EXISTS (SELECT 1 FROM borrows b
+		                                      WHERE b.copy_id = bc.id AND b.status = 'borrowed' AND b.id <> ?)
+		                    ORDER BY bc.id
+		                    LIMIT 1 FOR UPDATE`, bookID, borrowID).Scan(&released)
+		if err != nil && err != sql.ErrNoRows {
+			return result, err
+		}
+	}
+
+	_, err := tx.Exec(`UPDATE borrows SET status = 'returned', returned_at = NOW(), copy_id = COALESCE(copy_id, ?),
+	                          return_branch_id = ?
+	                   WHERE id = ?`, nullIfZero(released), nullIfZero(returnBranch), borrowID)
+	if err != nil {
+		return result, err
+	}
+	if released == 0 {
+		return result, nil
+	}
+
+	result.CopyID = released
+	var homeBranch sql.NullInt64
+	if err := tx.QueryRow("SELECT branch_id FROM book_copies WHERE id = ?", released).Scan(&homeBranch); err != nil {
+		return result, err
+	}
+	if homeBranch.Valid {
+		result.HomeBranchID = int(homeBranch.Int64)
+	}
+
+	kind, note := "", ""
+	if returnBranch > 0 {
+		var reservationID int
+		var pickup sql.NullInt64
+		err := tx.QueryRow(`SELECT r.id, r.pickup_branch_id FROM reservations r
+		                    WHERE r.book_id = ? AND r.status = 'active' AND r.notified = FALSE
+		                      AND NOT EXISTS (SELECT 1 FROM copy_transfers t
+		                                      WHERE t.reservation_id = r.id AND t.status IN ('requested', 'in_transit', 'received'))
+		                    ORDER BY r.reserved_at, r.id
+		                    LIMIT 1 FOR UPDATE`, bookID).Scan(&reservationID, &pickup)
+		if err != nil && err != sql.ErrNoRows {
+			return result, err
+		}
+		if pickup.Valid && int(pickup.Int64) != returnBranch {
+			kind, note = "hold", "예약 도서를 받을 분관으로 보냄"
+			result.ToBranchID = int(pickup.Int64)
+			result.ReservationID = reservationID
+		} else if homeBranch.Valid && int(homeBranch.Int64) != returnBranch {
+			kind, note = "return", "다른 분관에 반납됨"
+			result.ToBranchID = int(homeBranch.Int64)
+		}
+	}
+
+	to := "available"
+	if kind != "" {
+		to = "in_transit"
+		result.InTransit = true
+		transferID, err := startTransfer(tx, released, returnBranch, result.ToBranchID, kind, result.ReservationID, actor)
+		if err != nil {
+			return result, err
+		}
+		result.TransferID = transferID
+	}
+	if _, err := tx.Exec("UPDATE book_copies SET status = ? WHERE id = ?", to, released); err != nil {
+		return result, err
+	}
+	if err := recordCopyStatus(tx, released, "borrowed", to, "checkin", note, actor); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// 반납 데스크에서 바로 발송하는 이관을 만든다 (migrations/015_copy_transfers.sql 참고).
+func startTransfer(tx *sql.Tx, copyID, from, to int, kind string, reservationID int, actor string) (int, error) {
+	result, err := tx.Exec(`INSERT INTO copy_transfers (copy_id, from_branch_id, to_branch_id, kind, reservation_id, status, requested_by, shipped_at)
+	                        VALUES (?, ?, ?, ?, ?, 'in_transit', ?, NOW())`,
+		copyID, from, to, kind, nullIfZero(reservationID), actor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func nullIfZero(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// 스캐너 입력의 공백을 없애고 대문자로 맞춘다 (book-service의 바코드 형식과 같다).
+func normalizeBarcode(raw string) string {
+	return strings.ToUpper(strings.TrimSpace(raw))
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +20,7 @@ type BorrowItem struct {
 	ID         int        `json:"id"`
 	UserID     string     `json:"user_id"`
 	BookID     string     `json:"book_id"`
+	CopyID     *int       `json:"copy_id"`
 	Title      string     `json:"title"`
 	Author     string     `json:"author"`
 	BorrowedAt time.Time  `json:"borrowed_at"`
@@ -30,6 +33,8 @@ type BorrowRequest struct {
 	BookID string `json:"book_id" binding:"required"`
 	Title  string `json:"title" binding:"required"`
 	Author string `json:"author" binding:"required"`
+	// 대출하는 분관 (생략하면 어느 분관의 복본이든)
+	BranchID int `json:"branch_id"`
 }
 
 var (
@@ -38,6 +43,9 @@ var (
 	ctx         = context.Background()
 )
 
+// 역할별 권한 캐시 유효 시간
+const permissionCacheTTL = 5 * time.Minute
+
 func main() {
 	if err := godotenv.Load("../../.env.local"); err != nil {
 		log.Println("No .env.local file found, using environment variables or defaults")
@@ -82,12 +90,16 @@ func main() {
 	router.GET("/borrows/history", authMiddleware(), handleGetBorrowHistory)
 	router.POST("/borrows/borrow", authMiddleware(), handleBorrowBook)
 	router.POST("/borrows/return/:book_id", authMiddleware(), handleReturnBook)
+	router.POST("/borrows/renew/:book_id", authMiddleware(), handleRenewBook)
 
 	// 관리자 전용 API
-	router.POST("/borrows/admin/borrow", authMiddleware(), adminMiddleware(), handleAdminBorrowBook)
-	router.POST("/borrows/admin/return/:borrow_id", authMiddleware(), adminMiddleware(), handleAdminReturnBook)
-	router.GET("/borrows/admin/all", authMiddleware(), adminMiddleware(), handleGetAllBorrows)
-	router.GET("/borrows/admin/history", authMiddleware(), adminMiddleware(), handleGetAllBorrowHistory)
+	router.POST("/borrows/admin/borrow", authMiddleware(), requirePermission("circulation:checkout-for-others"), handleAdminBorrowBook)
+	router.POST("/borrows/admin/return/:borrow_id", authMiddleware(), requirePermission("circulation:return-for-others"), handleAdminReturnBook)
+	router.POST("/borrows/admin/return-barcode", authMiddleware(), requirePermission("circulation:return-for-others"), handleReturnByBarcode)
+	router.GET("/borrows/admin/reconcile", authMiddleware(), requirePermission("circulation:reconcile"), handleReconcileBorrows)
+	router.POST("/borrows/admin/reconcile", authMiddleware(), requirePermission("circulation:reconcile"), handleReconcileBorrows)
+	router.GET("/borrows/admin/all", authMiddleware(), requirePermission("circulation:view-all"), handleGetAllBorrows)
+	router.GET("/borrows/admin/history", authMiddleware(), requirePermission("circulation:view-all"), handleGetAllBorrowHistory)
 
 	port := getEnv("BORROW_SERVICE_PORT", getEnv("PORT", "8083"))
 	log.Printf("Borrow service starting on port %s", port)
@@ -123,10 +135,10 @@ func authMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 사용자 역할 조회 (username으로 조회)
-		var role string
-		query := "SELECT role FROM users WHERE username = ?"
-		err = db.QueryRow(query, username).Scan(&role)
+		// 사용자 역할 및 상태 조회 (username으로 조회)
+		var role, status string
+		query := "SELECT role, status FROM users WHERE username = ?"
+		err = db.QueryRow(query, username).Scan(&role, &status)
 		if err != nil {
 			log.Printf("Database error: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user role"})
@@ -134,13 +146,24 @@ func authMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 정지된 계정의 세션은 유효하지 않음
+		if status != "active" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", username)
 		c.Set("role", role)
 		c.Next()
 	}
 }
 
-func adminMiddleware() gin.HandlerFunc {
+// 역할에 지정된 권한이 있는지 확인 (authMiddleware 다음에 사용)
+// requirePermission, getRolePermissions, permissionSet은 book-service, borrow-service, user-service에
+// 같은 코드로 들어 있다 (서비스마다 Go 모듈이 따로여서 공유 패키지가 없다).
+// 세 서비스가 Redis 캐시 키(role_permissions:<역할>)를 함께 쓰므로 고칠 때는 세 곳을 똑같이 고친다.
+func requirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
@@ -149,8 +172,16 @@ func adminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "관리자 권한이 필요합니다"})
+		permissions, err := getRolePermissions(role.(string))
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "권한 확인에 실패했습니다"})
+			c.Abort()
+			return
+		}
+
+		if !permissions[permission] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "권한이 없습니다", "permission": permission})
 			c.Abort()
 			return
 		}
@@ -159,11 +190,57 @@ func adminMiddleware() gin.HandlerFunc {
 	}
 }
 
+// 역할별 권한 목록 조회 (Redis 캐시 우선, 없으면 DB 조회 후 캐시)
+func getRolePermissions(role string) (map[string]bool, error) {
+	cacheKey := "role_permissions:" + role
+	cached, err := redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		return permissionSet(strings.Split(cached, ",")), nil
+	}
+	if err != redis.Nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	rows, err := db.Query("SELECT permission FROM role_permissions WHERE role = ?", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := redisClient.Set(ctx, cacheKey, strings.Join(names, ","), permissionCacheTTL).Err(); err != nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	return permissionSet(names), nil
+}
+
+func permissionSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
 func handleGetBorrows(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDStr := userID.(string)
 
-	query := `SELECT id, user_id, book_id, title, author, borrowed_at, due_date, returned_at, status
+	query := `SELECT id, user_id, book_id, copy_id, title, author, borrowed_at, due_date, returned_at, status
 	          FROM borrows WHERE user_id = ? AND status = 'borrowed' ORDER BY borrowed_at DESC`
 
 	rows, err := db.Query(query, userIDStr)
@@ -177,7 +254,7 @@ func handleGetBorrows(c *gin.Context) {
 	borrows := []BorrowItem{}
 	for rows.Next() {
 		var item BorrowItem
-		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.Title, &item.Author,
+		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.CopyID, &item.Title, &item.Author,
 			&item.BorrowedAt, &item.DueDate, &item.ReturnedAt, &item.Status)
 		if err != nil {
 			log.Printf("Scan error: %v", err)
@@ -194,32 +271,22 @@ func handleGetBorrowHistory(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDStr := userID.(string)
 
-	// 모든 대여 이력 조회 (현재 대여중 + 반납 완료)
-	query := `SELECT id, user_id, book_id, title, author, borrowed_at, due_date, returned_at, status
-	          FROM borrows WHERE user_id = ? ORDER BY borrowed_at DESC`
+	pageReq, err := parsePageRequest(c, "history", historySort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
 
-	rows, err := db.Query(query, userIDStr)
+	// 모든 대여 이력 조회 (현재 대여중 + 반납 완료)
+	page, err := queryBorrowPage(" WHERE user_id = ?", []interface{}{userIDStr}, pageReq)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "대여 이력을 불러오는데 실패했습니다"})
 		return
 	}
-	defer rows.Close()
-
-	history := []BorrowItem{}
-	for rows.Next() {
-		var item BorrowItem
-		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.Title, &item.Author,
-			&item.BorrowedAt, &item.DueDate, &item.ReturnedAt, &item.Status)
-		if err != nil {
-			log.Printf("Scan error: %v", err)
-			continue
-		}
-		history = append(history, item)
-	}
 
-	log.Printf("User %s retrieved %d borrow history records (대여 이력)", userIDStr, len(history))
-	c.JSON(http.StatusOK, history)
+	log.Printf("User %s retrieved borrow history page (대여 이력, total %d)", userIDStr, page.Total)
+	c.JSON(http.StatusOK, page)
 }
 
 func handleBorrowBook(c *gin.Context) {
@@ -241,14 +308,42 @@ func handleBorrowBook(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 대여 가능한 복본 찾기 (FOR UPDATE로 잠금)
-	var copyID int
-	findCopyQuery := `SELECT id FROM book_copies
-	                  WHERE book_id = ? AND status = 'available'
-	                  LIMIT 1 FOR UPDATE`
-	err = tx.QueryRow(findCopyQuery, req.BookID).Scan(&copyID)
+	// 회원 유형별 대출 한도 확인
+	limits, err := lockMembershipLimits(tx, userIDStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		if err == errMembershipMissing {
+			log.Printf("Membership type missing for user %s", userIDStr)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 정보가 없습니다. 관리자에게 문의하세요"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 한도 확인에 실패했습니다"})
+		return
+	}
+
+	withinLimit, currentLoans, err := checkLoanLimit(tx, userIDStr, limits)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 한도 확인에 실패했습니다"})
+		return
+	}
+	if !withinLimit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "대출 가능 권수를 초과했습니다",
+			"current_loans": currentLoans,
+			"max_loans":     limits.MaxLoans,
+		})
+		return
+	}
+
+	// 대여 가능한 복본 찾기 (FOR UPDATE로 잠금)
+	copyID, copyStatus, err := lockCopyForCheckout(tx, req.BookID, "", userIDStr, req.BranchID)
+	if err != nil {
+		if err == errCopyNotAvailable {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "대여 가능한 복본이 없습니다"})
 			return
 		}
@@ -258,20 +353,18 @@ func handleBorrowBook(c *gin.Context) {
 	}
 
 	// 복본 상태를 'borrowed'로 변경
-	updateCopyQuery := `UPDATE book_copies SET status = 'borrowed' WHERE id = ?`
-	_, err = tx.Exec(updateCopyQuery, copyID)
-	if err != nil {
+	if err := checkoutCopy(tx, copyID, copyStatus, userIDStr, req.BookID, userIDStr); err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 상태 업데이트에 실패했습니다"})
 		return
 	}
 
-	dueDate := time.Now().AddDate(0, 0, 14).Format("2006-01-02")
+	dueDate := time.Now().AddDate(0, 0, limits.LoanDays).Format("2006-01-02")
 
 	// 대여 기록 생성
-	insertBorrowQuery := `INSERT INTO borrows (user_id, book_id, title, author, due_date, status)
-	                      VALUES (?, ?, ?, ?, ?, 'borrowed')`
-	_, err = tx.Exec(insertBorrowQuery, userIDStr, req.BookID, req.Title, req.Author, dueDate)
+	insertBorrowQuery := `INSERT INTO borrows (user_id, book_id, copy_id, branch_id, title, author, due_date, status)
+	                      VALUES (?, ?, ?, ?, ?, ?, ?, 'borrowed')`
+	_, err = tx.Exec(insertBorrowQuery, userIDStr, req.BookID, copyID, nullIfZero(req.BranchID), req.Title, req.Author, dueDate)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 대여에 실패했습니다"})
@@ -287,8 +380,9 @@ func handleBorrowBook(c *gin.Context) {
 
 	log.Printf("User %s rented book %s (copy %d)", userIDStr, req.BookID, copyID)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "도서가 대여되었습니다",
+		"message":  "도서가 대여되었습니다",
 		"due_date": dueDate,
+		"copy_id":  copyID,
 	})
 }
 
@@ -296,6 +390,7 @@ func handleReturnBook(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDStr := userID.(string)
 	bookID := c.Param("book_id")
+	returnBranch, _ := strconv.Atoi(c.Query("branch_id")) // 반납하는 분관 (선택)
 
 	// 트랜잭션 시작
 	tx, err := db.Begin()
@@ -306,30 +401,29 @@ func handleReturnBook(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 대여 기록 업데이트
-	updateBorrowQuery := `UPDATE borrows SET status = 'returned', returned_at = NOW()
-	                      WHERE user_id = ? AND book_id = ? AND status = 'borrowed'`
-	result, err := tx.Exec(updateBorrowQuery, userIDStr, bookID)
+	// 가장 먼저 빌린 대여 기록부터 반납
+	var borrowID int
+	var copyID sql.NullInt64
+	findBorrowQuery := `SELECT id, copy_id FROM borrows
+	                    WHERE user_id = ? AND book_id = ? AND status = 'borrowed'
+	                    ORDER BY borrowed_at, id
+	                    LIMIT 1 FOR UPDATE`
+	err = tx.QueryRow(findBorrowQuery, userIDStr, bookID).Scan(&borrowID, &copyID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "대여 목록에서 도서를 찾을 수 없습니다"})
+			return
+		}
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납에 실패했습니다"})
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "대여 목록에서 도서를 찾을 수 없습니다"})
-		return
-	}
-
-	// 대여 중인 복본 하나를 'available'로 변경
-	updateCopyQuery := `UPDATE book_copies SET status = 'available'
-	                    WHERE book_id = ? AND status = 'borrowed'
-	                    LIMIT 1`
-	_, err = tx.Exec(updateCopyQuery, bookID)
+	// 대여 기록을 반납 처리하고 빌려 간 복본을 'available'로 변경
+	returned, err := returnLoan(tx, borrowID, bookID, copyID, returnBranch, userIDStr)
 	if err != nil {
 		log.Printf("Database error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 상태 업데이트에 실패했습니다"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납에 실패했습니다"})
 		return
 	}
 
@@ -341,7 +435,7 @@ func handleReturnBook(c *gin.Context) {
 	}
 
 	log.Printf("User %s returned book %s (반납)", userIDStr, bookID)
-	c.JSON(http.StatusOK, gin.H{"message": "도서가 반납되었습니다"})
+	c.JSON(http.StatusOK, gin.H{"message": "도서가 반납되었습니다", "copy": returned})
 }
 
 // 관리자: 특정 사용자를 위한 도서 대여 등록
@@ -350,10 +444,13 @@ func handleAdminBorrowBook(c *gin.Context) {
 	adminIDStr := adminID.(string)
 
 	var req struct {
-		UserID string `json:"user_id" binding:"required"`
-		BookID string `json:"book_id" binding:"required"`
-		Title  string `json:"title" binding:"required"`
-		Author string `json:"author" binding:"required"`
+		UserID     string `json:"user_id"`     // username 또는 카드 번호
+		CardNumber string `json:"card_number"` // 이용증 바코드
+		BookID     string `json:"book_id" binding:"required"`
+		Title      string `json:"title" binding:"required"`
+		Author     string `json:"author" binding:"required"`
+		Barcode    string `json:"barcode"`   // 복본 바코드 (생략하면 대여 가능한 복본 중 하나)
+		BranchID   int    `json:"branch_id"` // 대출 데스크가 있는 분관
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -361,6 +458,15 @@ func handleAdminBorrowBook(c *gin.Context) {
 		return
 	}
 
+	patron := req.CardNumber
+	if patron == "" {
+		patron = req.UserID
+	}
+	if patron == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id 또는 card_number가 필요합니다"})
+		return
+	}
+
 	// 트랜잭션 시작
 	tx, err := db.Begin()
 	if err != nil {
@@ -370,14 +476,57 @@ func handleAdminBorrowBook(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 대여 가능한 복본 찾기
-	var copyID int
-	findCopyQuery := `SELECT id FROM book_copies
-	                  WHERE book_id = ? AND status = 'available'
-	                  LIMIT 1 FOR UPDATE`
-	err = tx.QueryRow(findCopyQuery, req.BookID).Scan(&copyID)
+	// 카드 번호 또는 username으로 이용자 확인
+	req.UserID, err = resolvePatron(tx, patron)
+	if err != nil {
+		respondPatronError(c, err)
+		return
+	}
+
+	// 회원 유형별 대출 한도 확인
+	limits, err := lockMembershipLimits(tx, req.UserID)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		if err == errMembershipMissing {
+			log.Printf("Membership type missing for user %s", req.UserID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 정보가 없습니다. 관리자에게 문의하세요"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 한도 확인에 실패했습니다"})
+		return
+	}
+
+	withinLimit, currentLoans, err := checkLoanLimit(tx, req.UserID, limits)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 한도 확인에 실패했습니다"})
+		return
+	}
+	if !withinLimit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "대출 가능 권수를 초과했습니다",
+			"current_loans": currentLoans,
+			"max_loans":     limits.MaxLoans,
+		})
+		return
+	}
+
+	// 대여할 복본 찾기 (스캔한 바코드가 있으면 그 복본)
+	copyID, copyStatus, err := lockCopyForCheckout(tx, req.BookID, req.Barcode, req.UserID, req.BranchID)
+	if err != nil {
+		if err == errCopyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "바코드에 해당하는 복본이 없습니다"})
+			return
+		}
+		if err == errCopyNotAvailable {
+			if req.Barcode != "" {
+				c.JSON(http.StatusConflict, gin.H{"error": "이 도서의 대여 가능한 복본이 아닙니다"})
+				return
+			}
 			c.JSON(http.StatusBadRequest, gin.H{"error": "대여 가능한 복본이 없습니다"})
 			return
 		}
@@ -387,19 +536,17 @@ func handleAdminBorrowBook(c *gin.Context) {
 	}
 
 	// 복본 상태를 'borrowed'로 변경
-	updateCopyQuery := `UPDATE book_copies SET status = 'borrowed' WHERE id = ?`
-	_, err = tx.Exec(updateCopyQuery, copyID)
-	if err != nil {
+	if err := checkoutCopy(tx, copyID, copyStatus, req.UserID, req.BookID, adminIDStr); err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 상태 업데이트에 실패했습니다"})
 		return
 	}
 
-	dueDate := time.Now().AddDate(0, 0, 14).Format("2006-01-02")
+	dueDate := time.Now().AddDate(0, 0, limits.LoanDays).Format("2006-01-02")
 
-	insertBorrowQuery := `INSERT INTO borrows (user_id, book_id, title, author, due_date, status)
-	                      VALUES (?, ?, ?, ?, ?, 'borrowed')`
-	_, err = tx.Exec(insertBorrowQuery, req.UserID, req.BookID, req.Title, req.Author, dueDate)
+	insertBorrowQuery := `INSERT INTO borrows (user_id, book_id, copy_id, branch_id, title, author, due_date, status)
+	                      VALUES (?, ?, ?, ?, ?, ?, ?, 'borrowed')`
+	_, err = tx.Exec(insertBorrowQuery, req.UserID, req.BookID, copyID, nullIfZero(req.BranchID), req.Title, req.Author, dueDate)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 대여 등록에 실패했습니다"})
@@ -415,8 +562,9 @@ func handleAdminBorrowBook(c *gin.Context) {
 
 	log.Printf("Admin %s registered borrow for user %s, book %s (copy %d)", adminIDStr, req.UserID, req.BookID, copyID)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "도서 대여가 등록되었습니다",
+		"message":  "도서 대여가 등록되었습니다",
 		"due_date": dueDate,
+		"copy_id":  copyID,
 	})
 }
 
@@ -425,6 +573,7 @@ func handleAdminReturnBook(c *gin.Context) {
 	adminID, _ := c.Get("user_id")
 	adminIDStr := adminID.(string)
 	borrowID := c.Param("borrow_id")
+	returnBranch, _ := strconv.Atoi(c.Query("branch_id")) // 반납 데스크가 있는 분관 (선택)
 
 	// 트랜잭션 시작
 	tx, err := db.Begin()
@@ -435,10 +584,11 @@ func handleAdminReturnBook(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 대여 기록에서 book_id 조회
+	// 대여 기록에서 book_id, copy_id 조회
 	var bookID string
-	getBookIDQuery := `SELECT book_id FROM borrows WHERE id = ? AND status = 'borrowed'`
-	err = tx.QueryRow(getBookIDQuery, borrowID).Scan(&bookID)
+	var copyID sql.NullInt64
+	getBookIDQuery := `SELECT book_id, copy_id FROM borrows WHERE id = ? AND status = 'borrowed' FOR UPDATE`
+	err = tx.QueryRow(getBookIDQuery, borrowID).Scan(&bookID, &copyID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "대여 기록을 찾을 수 없습니다"})
@@ -449,33 +599,15 @@ func handleAdminReturnBook(c *gin.Context) {
 		return
 	}
 
-	// 대여 기록 업데이트
-	updateBorrowQuery := `UPDATE borrows SET status = 'returned', returned_at = NOW()
-	                      WHERE id = ? AND status = 'borrowed'`
-	result, err := tx.Exec(updateBorrowQuery, borrowID)
+	// 대여 기록을 반납 처리하고 빌려 간 복본을 'available'로 변경
+	id, _ := strconv.Atoi(borrowID)
+	returned, err := returnLoan(tx, id, bookID, copyID, returnBranch, adminIDStr)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납 처리에 실패했습니다"})
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "대여 기록을 찾을 수 없습니다"})
-		return
-	}
-
-	// 대여 중인 복본 하나를 'available'로 변경
-	updateCopyQuery := `UPDATE book_copies SET status = 'available'
-	                    WHERE book_id = ? AND status = 'borrowed'
-	                    LIMIT 1`
-	_, err = tx.Exec(updateCopyQuery, bookID)
-	if err != nil {
-		log.Printf("Database error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 상태 업데이트에 실패했습니다"})
-		return
-	}
-
 	// 트랜잭션 커밋
 	if err := tx.Commit(); err != nil {
 		log.Printf("Commit error: %v", err)
@@ -484,7 +616,7 @@ func handleAdminReturnBook(c *gin.Context) {
 	}
 
 	log.Printf("Admin %s processed return for borrow ID %s, book %s", adminIDStr, borrowID, bookID)
-	c.JSON(http.StatusOK, gin.H{"message": "도서 반납이 처리되었습니다"})
+	c.JSON(http.StatusOK, gin.H{"message": "도서 반납이 처리되었습니다", "copy": returned})
 }
 
 // 관리자: 모든 사용자의 대여 목록 조회 (대여 중인 것만)
@@ -492,10 +624,31 @@ func handleGetAllBorrows(c *gin.Context) {
 	adminID, _ := c.Get("user_id")
 	adminIDStr := adminID.(string)
 
-	query := `SELECT id, user_id, book_id, title, author, borrowed_at, due_date, returned_at, status
-	          FROM borrows WHERE status = 'borrowed' ORDER BY borrowed_at DESC`
+	query := `SELECT id, user_id, book_id, copy_id, title, author, borrowed_at, due_date, returned_at, status
+	          FROM borrows WHERE status = 'borrowed'`
+	args := []interface{}{}
+
+	// 선택적 필터: username 또는 카드 번호 (카드 상태와 관계없이 소유자로 조회)
+	if patron := c.Query("patron"); patron != "" {
+		username := patron
+		if isValidCardNumber(patron) {
+			holder, _, _, err := findCardHolder(db, patron)
+			if err != nil && err != sql.ErrNoRows {
+				log.Printf("Database error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 확인에 실패했습니다"})
+				return
+			}
+			if err == nil {
+				username = holder
+			}
+		}
+		query += " AND user_id = ?"
+		args = append(args, username)
+	}
+
+	query += " ORDER BY borrowed_at DESC"
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "대여 목록을 불러오는데 실패했습니다"})
@@ -506,7 +659,7 @@ func handleGetAllBorrows(c *gin.Context) {
 	borrows := []BorrowItem{}
 	for rows.Next() {
 		var item BorrowItem
-		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.Title, &item.Author,
+		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.CopyID, &item.Title, &item.Author,
 			&item.BorrowedAt, &item.DueDate, &item.ReturnedAt, &item.Status)
 		if err != nil {
 			log.Printf("Scan error: %v", err)
@@ -524,31 +677,85 @@ func handleGetAllBorrowHistory(c *gin.Context) {
 	adminID, _ := c.Get("user_id")
 	adminIDStr := adminID.(string)
 
-	query := `SELECT id, user_id, book_id, title, author, borrowed_at, due_date, returned_at, status
-	          FROM borrows ORDER BY borrowed_at DESC`
+	pageReq, err := parsePageRequest(c, "history", historySort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 커서입니다"})
+		return
+	}
+
+	// 상태 필터 (borrowed, overdue, returned)
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if status := c.Query("status"); status != "" {
+		if status != "borrowed" && status != "overdue" && status != "returned" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status는 borrowed, overdue, returned 중 하나여야 합니다"})
+			return
+		}
+		where += " AND status = ?"
+		args = append(args, status)
+	}
 
-	rows, err := db.Query(query)
+	page, err := queryBorrowPage(where, args, pageReq)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "대여 이력을 불러오는데 실패했습니다"})
 		return
 	}
+
+	log.Printf("Admin %s retrieved borrow history page (total %d records)", adminIDStr, page.Total)
+	c.JSON(http.StatusOK, page)
+}
+
+// 대여 이력 정렬 기준: 최근 대여 순
+var historySort = sortSpec{Columns: []string{"borrowed_at", "id"}, Desc: true}
+
+// 대여 이력 한 페이지 조회 (where는 " WHERE ..." 형태)
+func queryBorrowPage(where string, args []interface{}, pageReq pageRequest) (PageResponse, error) {
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM borrows"+where, args...).Scan(&total); err != nil {
+		return PageResponse{}, err
+	}
+
+	query := `SELECT id, user_id, book_id, copy_id, title, author, borrowed_at, due_date, returned_at, status
+	          FROM borrows` + where
+	if pageReq.Cursor != nil {
+		query += " AND " + historySort.after()
+		args = append(args, pageReq.cursorArgs()...)
+	}
+	limitClause, limitArgs := pageReq.limitClause()
+	query += historySort.orderBy() + limitClause
+	args = append(args, limitArgs...)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return PageResponse{}, err
+	}
 	defer rows.Close()
 
-	borrows := []BorrowItem{}
+	history := []BorrowItem{}
 	for rows.Next() {
 		var item BorrowItem
-		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.Title, &item.Author,
+		err := rows.Scan(&item.ID, &item.UserID, &item.BookID, &item.CopyID, &item.Title, &item.Author,
 			&item.BorrowedAt, &item.DueDate, &item.ReturnedAt, &item.Status)
 		if err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
-		borrows = append(borrows, item)
+		history = append(history, item)
+	}
+	if err := rows.Err(); err != nil {
+		return PageResponse{}, err
 	}
 
-	log.Printf("Admin %s retrieved %d total borrow history records", adminIDStr, len(borrows))
-	c.JSON(http.StatusOK, borrows)
+	count := len(history)
+	if count > pageReq.Limit {
+		history = history[:pageReq.Limit]
+	}
+
+	return newPageResponse(history, count, total, pageReq, "history", func() []string {
+		last := history[len(history)-1]
+		return []string{last.BorrowedAt.Format("2006-01-02 15:04:05.999999"), strconv.Itoa(last.ID)}
+	}), nil
 }
 
 func corsMiddleware() gin.HandlerFunc {
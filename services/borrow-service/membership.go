@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 회원 유형별 대출 한도
+type MembershipLimits struct {
+	MembershipType  string `json:"membership_type"`
+	MaxLoans        int    `json:"max_loans"`
+	MaxReservations int    `json:"max_reservations"`
+	LoanDays        int    `json:"loan_days"`
+	MaxRenewals     int    `json:"max_renewals"`
+}
+
+// 이용자의 회원 유형이 membership_types에 없을 때 (이용자를 찾지 못한 sql.ErrNoRows와 구분한다)
+var errMembershipMissing = errors.New("membership type not found")
+
+// 대출 트랜잭션 안에서 이용자의 회원 유형 한도를 조회하고 이용자 행을 잠근다.
+// 같은 이용자의 동시 대출 요청이 한도를 넘지 않도록 직렬화한다.
+func lockMembershipLimits(tx *sql.Tx, username string) (MembershipLimits, error) {
+	var limits MembershipLimits
+	var name sql.NullString
+	var maxLoans, maxReservations, loanDays, maxRenewals sql.NullInt64
+	query := `SELECT mt.name, mt.max_loans, mt.max_reservations, mt.loan_days, mt.max_renewals
+	          FROM users u
+	          LEFT JOIN membership_types mt ON u.membership_type = mt.name
+	          WHERE u.username = ?
+	          FOR UPDATE`
+	err := tx.QueryRow(query, username).Scan(&name, &maxLoans, &maxReservations, &loanDays, &maxRenewals)
+	if err != nil {
+		return limits, err
+	}
+	if !name.Valid {
+		return limits, errMembershipMissing
+	}
+	limits.MembershipType = name.String
+	limits.MaxLoans = int(maxLoans.Int64)
+	limits.MaxReservations = int(maxReservations.Int64)
+	limits.LoanDays = int(loanDays.Int64)
+	limits.MaxRenewals = int(maxRenewals.Int64)
+	return limits, nil
+}
+
+// 이용자의 현재 대출 수가 한도 미만인지 확인
+func checkLoanLimit(tx *sql.Tx, username string, limits MembershipLimits) (bool, int, error) {
+	var current int
+	query := `SELECT COUNT(*) FROM borrows WHERE user_id = ? AND status = 'borrowed'`
+	if err := tx.QueryRow(query, username).Scan(&current); err != nil {
+		return false, 0, err
+	}
+	return current < limits.MaxLoans, current, nil
+}
+
+// 대출 연장 (회원 유형별 연장 횟수 한도 적용)
+func handleRenewBook(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDStr := userID.(string)
+	bookID := c.Param("book_id")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	limits, err := lockMembershipLimits(tx, userIDStr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		if err == errMembershipMissing {
+			log.Printf("Membership type missing for user %s", userIDStr)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 정보가 없습니다. 관리자에게 문의하세요"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+
+	var borrowID, renewalCount int
+	var dueDate time.Time
+	query := `SELECT id, due_date, renewal_count FROM borrows
+	          WHERE user_id = ? AND book_id = ? AND status = 'borrowed'
+	          LIMIT 1 FOR UPDATE`
+	err = tx.QueryRow(query, userIDStr, bookID).Scan(&borrowID, &dueDate, &renewalCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "대여 목록에서 도서를 찾을 수 없습니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+
+	if renewalCount >= limits.MaxRenewals {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "연장 가능 횟수를 초과했습니다", "max_renewals": limits.MaxRenewals})
+		return
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	if dueDate.Before(today) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "연체 중인 도서는 연장할 수 없습니다"})
+		return
+	}
+
+	// 다른 이용자의 예약이 있으면 연장 불가
+	var reservationCount int
+	reservationQuery := `SELECT COUNT(*) FROM reservations
+	                     WHERE book_id = ? AND user_id <> ? AND status = 'active'`
+	if err := tx.QueryRow(reservationQuery, bookID, userIDStr).Scan(&reservationCount); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+	if reservationCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "예약자가 있는 도서는 연장할 수 없습니다"})
+		return
+	}
+
+	newDueDate := dueDate.AddDate(0, 0, limits.LoanDays).Format("2006-01-02")
+	updateQuery := `UPDATE borrows SET due_date = ?, renewal_count = renewal_count + 1 WHERE id = ?`
+	if _, err := tx.Exec(updateQuery, newDueDate, borrowID); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 연장에 실패했습니다"})
+		return
+	}
+
+	log.Printf("User %s renewed book %s until %s (%d/%d)", userIDStr, bookID, newDueDate, renewalCount+1, limits.MaxRenewals)
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "대출이 연장되었습니다",
+		"due_date":      newDueDate,
+		"renewal_count": renewalCount + 1,
+		"max_renewals":  limits.MaxRenewals,
+	})
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 관리자: 스캔한 복본 바코드로 반납 처리
+func handleReturnByBarcode(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	adminIDStr := adminID.(string)
+
+	var req struct {
+		Barcode  string `json:"barcode" binding:"required"`
+		BranchID int    `json:"branch_id"` // 반납 데스크가 있는 분관 (선택)
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납 처리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	var copyID int
+	var bookID, status string
+	err = tx.QueryRow("SELECT id, book_id, status FROM book_copies WHERE barcode = ? FOR UPDATE", normalizeBarcode(req.Barcode)).
+		Scan(&copyID, &bookID, &status)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "바코드에 해당하는 복본이 없습니다"})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "복본 조회에 실패했습니다"})
+		return
+	}
+
+	// 이 복본이 연결된 대여 기록, 없으면 복본이 연결되지 않은 같은 도서의 예전 대여 기록 중 가장 오래된 것
+	var borrowID int
+	var userID string
+	var loanCopy sql.NullInt64
+	err = tx.QueryRow(`SELECT id, user_id, copy_id FROM borrows
+	                   WHERE status = 'borrowed' AND (copy_id = ? OR (copy_id IS NULL AND book_id = ?))
+	                   ORDER BY copy_id IS NULL, borrowed_at, id
+	                   LIMIT 1 FOR UPDATE`, copyID, bookID).Scan(&borrowID, &userID, &loanCopy)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "이 복본으로 진행 중인 대출이 없습니다", "copy_status": status})
+		return
+	}
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대여 기록 조회에 실패했습니다"})
+		return
+	}
+	if !loanCopy.Valid && status != "borrowed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "대여 중인 복본이 아닙니다", "copy_status": status})
+		return
+	}
+
+	// 예전 대여 기록이면 스캔한 복본을 연결한 뒤 반납한다
+	if !loanCopy.Valid {
+		loanCopy = sql.NullInt64{Int64: int64(copyID), Valid: true}
+		if _, err := tx.Exec("UPDATE borrows SET copy_id = ? WHERE id = ?", copyID, borrowID); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납 처리에 실패했습니다"})
+			return
+		}
+	}
+
+	returned, err := returnLoan(tx, borrowID, bookID, loanCopy, req.BranchID, adminIDStr)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납 처리에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 반납 처리에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s processed return for borrow ID %d by barcode (copy %d)", adminIDStr, borrowID, copyID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "도서 반납이 처리되었습니다",
+		"borrow_id": borrowID,
+		"user_id":   userID,
+		"book_id":   bookID,
+		"copy_id":   copyID,
+		"copy":      returned,
+	})
+}
+
+// 대출 정리 중 발견한 불일치 한 건
+type ReconcileIssue struct {
+	Type     string `json:"type"`
+	BorrowID int    `json:"borrow_id,omitempty"`
+	CopyID   int    `json:"copy_id,omitempty"`
+	BookID   string `json:"book_id"`
+	Action   string `json:"action"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type reconcileLoan struct {
+	ID     int
+	BookID string
+	CopyID int
+}
+
+type reconcileCopy struct {
+	ID     int
+	BookID string
+	Status string
+}
+
+// 관리자: 대출 기록과 복본 상태 정리.
+// GET은 바꿀 내용만 보여 주고, POST는 한 트랜잭션으로 적용한다.
+//   - duplicate_copy: 한 복본이 여러 대출에 연결됨 -> 가장 오래된 대출만 남기고 나머지는 연결 해제 후 다시 배정
+//   - wrong_copy: 다른 도서의 복본에 연결됨 -> 연결 해제 후 다시 배정
+//   - missing_copy: copy_id가 없는 대출 -> 어느 대출에도 연결되지 않은 대여 중 복본, 없으면 대여 가능 복본을 배정
+//   - copy_not_borrowed: 연결된 복본이 대여 가능 상태 -> 대여 중으로 변경 (분실 등 다른 상태는 보고만 한다)
+//   - orphan_copy: 어느 대출에도 연결되지 않은 대여 중 복본 -> 대여 가능으로 변경
+//   - unresolved: 배정할 복본이 없는 대출 (수동 확인 필요)
+func handleReconcileBorrows(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	adminIDStr := adminID.(string)
+	apply := c.Request.Method == http.MethodPost
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 정리에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	issues, err := reconcileBorrows(tx, adminIDStr)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 정리에 실패했습니다"})
+		return
+	}
+
+	summary := map[string]int{}
+	for _, issue := range issues {
+		summary[issue.Type]++
+	}
+
+	if apply {
+		if err := tx.Commit(); err != nil {
+			log.Printf("Commit error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 정리에 실패했습니다"})
+			return
+		}
+		log.Printf("Admin %s reconciled borrows (%d issues)", adminIDStr, len(issues))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dry_run": !apply, "issues": issues, "summary": summary})
+}
+
+// 대출 기록과 복본 상태를 맞춘다. 호출한 쪽에서 커밋하지 않으면 아무것도 바뀌지 않는다.
+func reconcileBorrows(tx *sql.Tx, actor string) ([]ReconcileIssue, error) {
+	loans := []reconcileLoan{}
+	rows, err := tx.Query(`SELECT id, book_id, COALESCE(copy_id, 0) FROM borrows
+	                       WHERE status = 'borrowed'
+	                       ORDER BY borrowed_at, id
+	                       FOR UPDATE`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var l reconcileLoan
+		if err := rows.Scan(&l.ID, &l.BookID, &l.CopyID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		loans = append(loans, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	copies := map[int]*reconcileCopy{}
+	order := []int{}
+	rows, err = tx.Query(`SELECT id, book_id, status FROM book_copies
+	                      WHERE status = 'borrowed'
+	                         OR book_id IN (SELECT book_id FROM borrows WHERE status = 'borrowed')
+	                      ORDER BY id
+	                      FOR UPDATE`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		cp := &reconcileCopy{}
+		if err := rows.Scan(&cp.ID, &cp.BookID, &cp.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		copies[cp.ID] = cp
+		order = append(order, cp.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	issues := []ReconcileIssue{}
+	linked := map[int]bool{}
+
+	setCopyStatus := func(cp *reconcileCopy, to string) error {
+		if _, err := tx.Exec("UPDATE book_copies SET status = ? WHERE id = ?", to, cp.ID); err != nil {
+			return err
+		}
+		if err := recordCopyStatus(tx, cp.ID, cp.Status, to, "correction", "대출 기록 정리", actor); err != nil {
+			return err
+		}
+		cp.Status = to
+		return nil
+	}
+	linkLoan := func(l *reconcileLoan, copyID int) error {
+		_, err := tx.Exec("UPDATE borrows SET copy_id = ? WHERE id = ?", nullIfZero(copyID), l.ID)
+		l.CopyID = copyID
+		return err
+	}
+
+	// 연결된 복본 확인 (먼저 빌린 대출이 우선)
+	for i := range loans {
+		l := &loans[i]
+		if l.CopyID == 0 {
+			continue
+		}
+		cp, ok := copies[l.CopyID]
+		if !ok || linked[l.CopyID] || cp.BookID != l.BookID {
+			issueType := "duplicate_copy"
+			if !ok || cp.BookID != l.BookID {
+				issueType = "wrong_copy"
+			}
+			issues = append(issues, ReconcileIssue{Type: issueType, BorrowID: l.ID, CopyID: l.CopyID, BookID: l.BookID, Action: "unlink"})
+			if err := linkLoan(l, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		linked[cp.ID] = true
+		switch cp.Status {
+		case "borrowed":
+		case "available":
+			issues = append(issues, ReconcileIssue{Type: "copy_not_borrowed", BorrowID: l.ID, CopyID: cp.ID, BookID: l.BookID, Action: "mark_borrowed"})
+			if err := setCopyStatus(cp, "borrowed"); err != nil {
+				return nil, err
+			}
+		default:
+			issues = append(issues, ReconcileIssue{Type: "copy_not_borrowed", BorrowID: l.ID, CopyID: cp.ID, BookID: l.BookID, Action: "none", Detail: cp.Status})
+		}
+	}
+
+	// 복본이 없는 대출에 복본 배정: 대여 중인 복본부터, 없으면 대여 가능한 복본
+	pick := func(bookID, status string) *reconcileCopy {
+		for _, id := range order {
+			cp := copies[id]
+			if cp.BookID == bookID && cp.Status == status && !linked[id] {
+				return cp
+			}
+		}
+		return nil
+	}
+	for i := range loans {
+		l := &loans[i]
+		if l.CopyID != 0 {
+			continue
+		}
+		cp := pick(l.BookID, "borrowed")
+		action := "link"
+		if cp == nil {
+			cp = pick(l.BookID, "available")
+			action = "link_and_mark_borrowed"
+		}
+		if cp == nil {
+			issues = append(issues, ReconcileIssue{Type: "unresolved", BorrowID: l.ID, BookID: l.BookID, Action: "none", Detail: "배정할 복본이 없습니다"})
+			continue
+		}
+		issues = append(issues, ReconcileIssue{Type: "missing_copy", BorrowID: l.ID, CopyID: cp.ID, BookID: l.BookID, Action: action})
+		linked[cp.ID] = true
+		if err := linkLoan(l, cp.ID); err != nil {
+			return nil, err
+		}
+		if cp.Status != "borrowed" {
+			if err := setCopyStatus(cp, "borrowed"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 어느 대출에도 연결되지 않은 대여 중 복본
+	for _, id := range order {
+		cp := copies[id]
+		if cp.Status != "borrowed" || linked[id] {
+			continue
+		}
+		issues = append(issues, ReconcileIssue{Type: "orphan_copy", CopyID: cp.ID, BookID: cp.BookID, Action: "mark_available"})
+		if err := setCopyStatus(cp, "available"); err != nil {
+			return nil, err
+		}
+	}
+
+	return issues, nil
+}
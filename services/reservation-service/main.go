@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,14 +14,17 @@ import (
 )
 
 type Reservation struct {
-	ID         int       `json:"id"`
-	UserID     string    `json:"user_id"`
-	BookID     string    `json:"book_id"`
-	BookTitle  string    `json:"book_title"`
-	BookAuthor string    `json:"book_author"`
-	ReservedAt time.Time `json:"reserved_at"`
-	ExpiresAt  time.Time `json:"expires_at"`
-	Status     string    `json:"status"`
+	ID         int    `json:"id"`
+	UserID     string `json:"user_id"`
+	BookID     string `json:"book_id"`
+	BookTitle  string `json:"book_title"`
+	BookAuthor string `json:"book_author"`
+	// 예약 도서를 받을 분관 (없으면 어느 분관이든)
+	PickupBranchID   *int      `json:"pickup_branch_id"`
+	PickupBranchName string    `json:"pickup_branch_name"`
+	ReservedAt       time.Time `json:"reserved_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	Status           string    `json:"status"`
 }
 
 var db *sql.DB
@@ -73,8 +77,9 @@ func main() {
 // 예약 생성
 func handleCreateReservation(c *gin.Context) {
 	var req struct {
-		UserID string `json:"user_id" binding:"required"`
-		BookID string `json:"book_id" binding:"required"`
+		UserID         string `json:"user_id" binding:"required"`
+		BookID         string `json:"book_id" binding:"required"`
+		PickupBranchID *int   `json:"pickup_branch_id"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -82,11 +87,26 @@ func handleCreateReservation(c *gin.Context) {
 		return
 	}
 
-	// 도서가 대여 가능한지 확인
+	// 받을 분관 확인
+	if req.PickupBranchID != nil {
+		var branchCount int
+		err := db.QueryRow("SELECT COUNT(*) FROM branches WHERE id = ? AND level = 'branch' AND active = TRUE", *req.PickupBranchID).Scan(&branchCount)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "분관 확인에 실패했습니다"})
+			return
+		}
+		if branchCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "받을 분관을 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	// 도서가 (받을 분관에서) 대여 가능한지 확인
 	var availableCopies int
 	checkQuery := `SELECT COALESCE(SUM(CASE WHEN status = 'available' THEN 1 ELSE 0 END), 0)
-	               FROM book_copies WHERE book_id = ?`
-	err := db.QueryRow(checkQuery, req.BookID).Scan(&availableCopies)
+	               FROM book_copies WHERE book_id = ? AND (? IS NULL OR branch_id = ?)`
+	err := db.QueryRow(checkQuery, req.BookID, req.PickupBranchID, req.PickupBranchID).Scan(&availableCopies)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "도서 확인에 실패했습니다"})
@@ -98,29 +118,81 @@ func handleCreateReservation(c *gin.Context) {
 		return
 	}
 
-	// 이미 예약한 도서인지 확인
-	var existingCount int
-	existQuery := `SELECT COUNT(*) FROM reservations
-	               WHERE user_id = ? AND book_id = ? AND status = 'active'`
-	db.QueryRow(existQuery, req.UserID, req.BookID).Scan(&existingCount)
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 생성에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 회원 유형별 예약 한도 (이용자 행을 잠가 같은 이용자의 동시 예약이 한도를 넘지 않게 한다)
+	// 회원 유형이 membership_types에 없으면 이용자 없음과 구분해 알린다
+	var maxReservations sql.NullInt64
+	limitQuery := `SELECT mt.max_reservations
+	               FROM users u
+	               LEFT JOIN membership_types mt ON u.membership_type = mt.name
+	               WHERE u.username = ?
+	               FOR UPDATE`
+	err = tx.QueryRow(limitQuery, req.UserID).Scan(&maxReservations)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 한도 확인에 실패했습니다"})
+		return
+	}
+	if !maxReservations.Valid {
+		log.Printf("Membership type missing for user %s", req.UserID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 정보가 없습니다. 관리자에게 문의하세요"})
+		return
+	}
+
+	// 이미 예약한 도서인지, 현재 예약 건수는 몇 건인지 확인
+	var existingCount, activeCount int
+	countQuery := `SELECT COALESCE(SUM(CASE WHEN book_id = ? THEN 1 ELSE 0 END), 0), COUNT(*)
+	               FROM reservations
+	               WHERE user_id = ? AND status = 'active'`
+	if err := tx.QueryRow(countQuery, req.BookID, req.UserID).Scan(&existingCount, &activeCount); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 한도 확인에 실패했습니다"})
+		return
+	}
 
 	if existingCount > 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "이미 예약한 도서입니다"})
 		return
 	}
 
+	if int64(activeCount) >= maxReservations.Int64 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":               "예약 가능 건수를 초과했습니다",
+			"active_reservations": activeCount,
+			"max_reservations":    maxReservations.Int64,
+		})
+		return
+	}
+
 	// 예약 생성 (7일 후 만료)
 	expiresAt := time.Now().AddDate(0, 0, 7)
-	insertQuery := `INSERT INTO reservations (user_id, book_id, expires_at, status)
-	                VALUES (?, ?, ?, 'active')`
+	insertQuery := `INSERT INTO reservations (user_id, book_id, pickup_branch_id, expires_at, status)
+	                VALUES (?, ?, ?, ?, 'active')`
 
-	result, err := db.Exec(insertQuery, req.UserID, req.BookID, expiresAt)
+	result, err := tx.Exec(insertQuery, req.UserID, req.BookID, req.PickupBranchID, expiresAt)
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 생성에 실패했습니다"})
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 생성에 실패했습니다"})
+		return
+	}
+
 	id, _ := result.LastInsertId()
 	log.Printf("User %s reserved book %s (reservation ID: %d)", req.UserID, req.BookID, id)
 	c.JSON(http.StatusOK, gin.H{
@@ -139,9 +211,11 @@ func handleGetReservations(c *gin.Context) {
 		return
 	}
 
-	query := `SELECT r.id, r.user_id, r.book_id, b.title, b.author, r.reserved_at, r.expires_at, r.status
+	query := `SELECT r.id, r.user_id, r.book_id, b.title, b.author, r.pickup_branch_id, COALESCE(br.name, ''),
+	          r.reserved_at, r.expires_at, r.status
 	          FROM reservations r
 	          JOIN books b ON r.book_id = b.id
+	          LEFT JOIN branches br ON br.id = r.pickup_branch_id
 	          WHERE r.user_id = ?
 	          ORDER BY r.reserved_at DESC`
 
@@ -162,6 +236,8 @@ func handleGetReservations(c *gin.Context) {
 			&reservation.BookID,
 			&reservation.BookTitle,
 			&reservation.BookAuthor,
+			&reservation.PickupBranchID,
+			&reservation.PickupBranchName,
 			&reservation.ReservedAt,
 			&reservation.ExpiresAt,
 			&reservation.Status,
@@ -214,10 +290,12 @@ func scheduleReservationChecks() {
 
 	// 서버 시작 시 즉시 한 번 실행
 	expireReservations()
+	releaseEndedHolds()
 	notifyAvailableReservations()
 
 	for range ticker.C {
 		expireReservations()
+		releaseEndedHolds()
 		notifyAvailableReservations()
 	}
 }
@@ -240,16 +318,102 @@ func expireReservations() {
 	}
 }
 
+// 취소/만료된 예약을 위해 보관 중(on_hold)이던 복본을 돌려놓는다.
+// 예약 도서 이관으로 받을 분관에 와 있던 복본은 소속 분관으로 복귀 이관을 만들고, 소속 분관이면 대여 가능으로 바꾼다.
+func releaseEndedHolds() {
+	query := `SELECT bc.id, bc.branch_id, t.to_branch_id, t.reservation_id
+	          FROM book_copies bc
+	          JOIN copy_transfers t ON t.copy_id = bc.id AND t.kind = 'hold' AND t.status = 'received'
+	          JOIN reservations r ON r.id = t.reservation_id
+	          WHERE bc.status = 'on_hold' AND r.status <> 'active'
+	          AND t.id = (SELECT MAX(t2.id) FROM copy_transfers t2 WHERE t2.copy_id = bc.id)`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		log.Printf("Failed to check ended holds: %v", err)
+		return
+	}
+
+	type heldCopy struct {
+		CopyID        int
+		HomeBranchID  sql.NullInt64
+		AtBranchID    int
+		ReservationID int
+	}
+	held := []heldCopy{}
+	for rows.Next() {
+		var h heldCopy
+		if err := rows.Scan(&h.CopyID, &h.HomeBranchID, &h.AtBranchID, &h.ReservationID); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		held = append(held, h)
+	}
+	rows.Close()
+
+	for _, h := range held {
+		if err := releaseHold(h.CopyID, h.HomeBranchID, h.AtBranchID, h.ReservationID); err != nil {
+			log.Printf("Failed to release held copy %d: %v", h.CopyID, err)
+			continue
+		}
+		log.Printf("Released held copy %d (reservation %d ended)", h.CopyID, h.ReservationID)
+	}
+}
+
+func releaseHold(copyID int, home sql.NullInt64, at, reservationID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	note := "예약 #" + strconv.Itoa(reservationID) + " 종료"
+	to, reason := "available", "hold_expired"
+	if home.Valid && int(home.Int64) != at {
+		to, reason = "in_transit", "transfer"
+	}
+
+	// 그사이 대출 등으로 상태가 바뀌었으면 건너뛴다
+	result, err := tx.Exec("UPDATE book_copies SET status = ? WHERE id = ? AND status = 'on_hold'", to, copyID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	if to == "in_transit" {
+		_, err := tx.Exec(`INSERT INTO copy_transfers (copy_id, from_branch_id, to_branch_id, kind, status, note, requested_by, shipped_at)
+		                   VALUES (?, ?, ?, 'return', 'in_transit', ?, 'system', NOW())`, copyID, at, home.Int64, note)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO copy_status_history (copy_id, from_status, to_status, reason, note, actor)
+	                  VALUES (?, 'on_hold', ?, ?, ?, 'system')`, copyID, to, reason, note)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // 도서가 대여 가능해졌을 때 예약자에게 알림
 func notifyAvailableReservations() {
-	// 대여 가능한 복본이 있는 도서 중 예약이 있는 도서 찾기
-	query := `SELECT DISTINCT r.id, r.user_id, r.book_id, b.title
+	// 받을 분관에 대여 가능한 복본이 있거나, 예약 도서 이관으로 보관 중인 복본이 도착한 예약 찾기
+	query := `SELECT r.id, r.user_id, r.book_id, b.title, COALESCE(br.name, ''), r.reserved_at
 	          FROM reservations r
 	          JOIN books b ON r.book_id = b.id
-	          JOIN book_copies bc ON r.book_id = bc.book_id
+	          LEFT JOIN branches br ON br.id = r.pickup_branch_id
 	          WHERE r.status = 'active'
-	          AND bc.status = 'available'
 	          AND r.notified = FALSE
+	          AND (EXISTS (SELECT 1 FROM book_copies bc
+	                       WHERE bc.book_id = r.book_id AND bc.status = 'available'
+	                       AND (r.pickup_branch_id IS NULL OR bc.branch_id = r.pickup_branch_id))
+	               OR EXISTS (SELECT 1 FROM copy_transfers t
+	                          JOIN book_copies bc ON bc.id = t.copy_id
+	                          WHERE t.reservation_id = r.id AND t.kind = 'hold' AND t.status = 'received'
+	                          AND bc.status = 'on_hold'))
 	          ORDER BY r.reserved_at ASC`
 
 	rows, err := db.Query(query)
@@ -261,8 +425,9 @@ func notifyAvailableReservations() {
 
 	for rows.Next() {
 		var reservationID int
-		var userID, bookID, title string
-		if err := rows.Scan(&reservationID, &userID, &bookID, &title); err != nil {
+		var userID, bookID, title, branchName string
+		var reservedAt time.Time
+		if err := rows.Scan(&reservationID, &userID, &bookID, &title, &branchName, &reservedAt); err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
@@ -271,6 +436,9 @@ func notifyAvailableReservations() {
 		insertQuery := `INSERT INTO notifications (user_id, type, title, message, related_id)
 		                VALUES (?, 'reservation_available', '예약 도서 대여 가능', ?, ?)`
 		message := title + " 도서를 대여할 수 있습니다. 3일 이내에 대여해 주세요."
+		if branchName != "" {
+			message = title + " 도서를 " + branchName + "에서 대여할 수 있습니다. 3일 이내에 대여해 주세요."
+		}
 		_, err := db.Exec(insertQuery, userID, message, reservationID)
 		if err != nil {
 			log.Printf("Failed to create notification: %v", err)
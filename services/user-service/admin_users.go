@@ -0,0 +1,578 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserSummary struct {
+	ID             string    `json:"id"`
+	Username       string    `json:"username"`
+	DisplayName    string    `json:"display_name"`
+	Email          string    `json:"email"`
+	Phone          string    `json:"phone"`
+	Role           string    `json:"role"`
+	MembershipType string    `json:"membership_type"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type UserBlock struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserLoan struct {
+	ID         int       `json:"id"`
+	BookID     string    `json:"book_id"`
+	Title      string    `json:"title"`
+	BorrowedAt time.Time `json:"borrowed_at"`
+	DueDate    string    `json:"due_date"`
+}
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// 관리자: 이용자 검색 및 목록 조회 (페이지 단위)
+func handleAdminListUsers(c *gin.Context) {
+	q := c.Query("q")           // username, 이름, 이메일 검색
+	role := c.Query("role")     // 역할 필터
+	status := c.Query("status") // 상태 필터
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultUserPageSize)))
+	if limit < 1 || limit > maxUserPageSize {
+		limit = defaultUserPageSize
+	}
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if q != "" {
+		where += " AND (username LIKE ? OR display_name LIKE ? OR email LIKE ?)"
+		like := "%" + q + "%"
+		args = append(args, like, like, like)
+	}
+	if role != "" {
+		where += " AND role = ?"
+		args = append(args, role)
+	}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users"+where, args...).Scan(&total); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 목록을 불러오는데 실패했습니다"})
+		return
+	}
+
+	query := `SELECT id, username, display_name, email, phone, role, membership_type, status, created_at
+	          FROM users` + where + ` ORDER BY created_at DESC, username LIMIT ? OFFSET ?`
+	rows, err := db.Query(query, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 목록을 불러오는데 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	users := []UserSummary{}
+	for rows.Next() {
+		var user UserSummary
+		err := rows.Scan(&user.ID, &user.Username, &user.DisplayName, &user.Email, &user.Phone,
+			&user.Role, &user.MembershipType, &user.Status, &user.CreatedAt)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		users = append(users, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// 관리자: 이용자 상세 조회 (현재 대여 및 이용 제한 포함)
+func handleAdminGetUser(c *gin.Context) {
+	username := c.Param("username")
+
+	user, err := getUserSummary(username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 조회에 실패했습니다"})
+		return
+	}
+
+	loans, err := getCurrentLoans(username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대여 목록을 불러오는데 실패했습니다"})
+		return
+	}
+
+	blocks, err := getActiveBlocks(username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용 제한 내역을 불러오는데 실패했습니다"})
+		return
+	}
+
+	limits, err := getMembershipLimits(user.MembershipType)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대출 한도를 불러오는데 실패했습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"current_loans": loans,
+		"blocks":        blocks,
+		"limits":        limits,
+	})
+}
+
+// 관리자: 이용자 등록
+func handleAdminCreateUser(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	var req struct {
+		Username       string `json:"username" binding:"required"`
+		Password       string `json:"password" binding:"required"`
+		DisplayName    string `json:"display_name"`
+		Email          string `json:"email"`
+		Phone          string `json:"phone"`
+		Role           string `json:"role"`
+		MembershipType string `json:"membership_type"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = "member"
+	}
+	if req.MembershipType == "" {
+		req.MembershipType = "general"
+	}
+
+	if _, err := getMembershipLimits(req.MembershipType); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 회원 유형입니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+
+	exists, err := roleExists(req.Role)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 역할입니다"})
+		return
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", req.Username).Scan(&count); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 아이디입니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	// 비밀번호는 로그인과 동일한 방식으로 저장 (실제 환경에서는 bcrypt 등 사용)
+	id := uuid.New().String()
+	query := `INSERT INTO users (id, username, password, display_name, email, phone, role, membership_type, status)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'active')`
+	_, err = tx.Exec(query, id, req.Username, req.Password, req.DisplayName, req.Email, req.Phone,
+		req.Role, req.MembershipType)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+
+	// 신규 이용자에게 이용증 발급
+	card, _, err := issueLibraryCard(tx, req.Username, adminID.(string), "replaced")
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용증 발급에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 등록에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v created user %s (role: %s, card: %s)", adminID, req.Username, req.Role, card.CardNumber)
+	c.JSON(http.StatusOK, gin.H{"message": "이용자가 등록되었습니다", "id": id, "card_number": card.CardNumber})
+}
+
+// 관리자: 이용자 정보 수정
+func handleAdminUpdateUser(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	username := c.Param("username")
+
+	var req struct {
+		DisplayName string `json:"display_name"`
+		Email       string `json:"email"`
+		Phone       string `json:"phone"`
+		Password    string `json:"password"` // 비어 있으면 변경하지 않음
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	query := `UPDATE users SET display_name = ?, email = ?, phone = ? WHERE username = ?`
+	args := []interface{}{req.DisplayName, req.Email, req.Phone, username}
+	if req.Password != "" {
+		query = `UPDATE users SET display_name = ?, email = ?, phone = ?, password = ? WHERE username = ?`
+		args = []interface{}{req.DisplayName, req.Email, req.Phone, req.Password, username}
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 수정에 실패했습니다"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := getUserSummary(username); err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	log.Printf("Admin %v updated user %s", adminID, username)
+	c.JSON(http.StatusOK, gin.H{"message": "이용자 정보가 수정되었습니다"})
+}
+
+// 관리자: 이용자 역할 변경
+func handleAdminChangeRole(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	username := c.Param("username")
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if username == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "자신의 역할은 변경할 수 없습니다"})
+		return
+	}
+
+	exists, err := roleExists(req.Role)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "역할 변경에 실패했습니다"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 역할입니다"})
+		return
+	}
+
+	result, err := db.Exec("UPDATE users SET role = ? WHERE username = ?", req.Role, username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "역할 변경에 실패했습니다"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := getUserSummary(username); err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	log.Printf("Admin %v changed role of user %s to %s", adminID, username, req.Role)
+	c.JSON(http.StatusOK, gin.H{"message": "역할이 변경되었습니다", "role": req.Role})
+}
+
+// 관리자: 이용자 회원 유형 변경
+func handleAdminChangeMembership(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	username := c.Param("username")
+
+	var req struct {
+		MembershipType string `json:"membership_type" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	limits, err := getMembershipLimits(req.MembershipType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "존재하지 않는 회원 유형입니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 변경에 실패했습니다"})
+		return
+	}
+
+	result, err := db.Exec("UPDATE users SET membership_type = ? WHERE username = ?", req.MembershipType, username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 변경에 실패했습니다"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := getUserSummary(username); err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	log.Printf("Admin %v changed membership of user %s to %s", adminID, username, req.MembershipType)
+	c.JSON(http.StatusOK, gin.H{"message": "회원 유형이 변경되었습니다", "membership_type": req.MembershipType, "limits": limits})
+}
+
+// 관리자: 이용자 정지 (모든 세션 즉시 무효화)
+func handleAdminSuspendUser(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	adminIDStr := adminID.(string)
+	username := c.Param("username")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&req)
+
+	if username == adminIDStr {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "자신의 계정은 정지할 수 없습니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 정지에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE users SET status = 'suspended' WHERE username = ? AND status = 'active'", username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 정지에 실패했습니다"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := getUserSummary(username); err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "이미 정지된 이용자입니다"})
+		return
+	}
+
+	insertBlockQuery := `INSERT INTO user_blocks (user_id, type, reason, created_by)
+	                     VALUES (?, 'suspension', ?, ?)`
+	if _, err := tx.Exec(insertBlockQuery, username, req.Reason, adminIDStr); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 정지에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 정지에 실패했습니다"})
+		return
+	}
+
+	revoked := invalidateUserSessions(username)
+
+	log.Printf("Admin %s suspended user %s (%d sessions revoked)", adminIDStr, username, revoked)
+	c.JSON(http.StatusOK, gin.H{"message": "이용자가 정지되었습니다", "revoked_sessions": revoked})
+}
+
+// 관리자: 정지된 이용자 재활성화
+func handleAdminReactivateUser(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	adminIDStr := adminID.(string)
+	username := c.Param("username")
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 재활성화에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE users SET status = 'active' WHERE username = ? AND status = 'suspended'", username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 재활성화에 실패했습니다"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := getUserSummary(username); err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "정지 상태가 아닌 이용자입니다"})
+		return
+	}
+
+	clearBlockQuery := `UPDATE user_blocks SET cleared_at = NOW(), cleared_by = ?
+	                    WHERE user_id = ? AND type = 'suspension' AND cleared_at IS NULL`
+	if _, err := tx.Exec(clearBlockQuery, adminIDStr, username); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 재활성화에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 재활성화에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s reactivated user %s", adminIDStr, username)
+	c.JSON(http.StatusOK, gin.H{"message": "이용자가 재활성화되었습니다"})
+}
+
+func getUserSummary(username string) (UserSummary, error) {
+	var user UserSummary
+	query := `SELECT id, username, display_name, email, phone, role, membership_type, status, created_at
+	          FROM users WHERE username = ?`
+	err := db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.DisplayName, &user.Email,
+		&user.Phone, &user.Role, &user.MembershipType, &user.Status, &user.CreatedAt)
+	return user, err
+}
+
+func getCurrentLoans(username string) ([]UserLoan, error) {
+	query := `SELECT id, book_id, title, borrowed_at, due_date
+	          FROM borrows WHERE user_id = ? AND status = 'borrowed' ORDER BY borrowed_at DESC`
+	rows, err := db.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := []UserLoan{}
+	for rows.Next() {
+		var loan UserLoan
+		if err := rows.Scan(&loan.ID, &loan.BookID, &loan.Title, &loan.BorrowedAt, &loan.DueDate); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		loans = append(loans, loan)
+	}
+	return loans, rows.Err()
+}
+
+func getActiveBlocks(username string) ([]UserBlock, error) {
+	query := `SELECT id, type, reason, created_by, created_at
+	          FROM user_blocks WHERE user_id = ? AND cleared_at IS NULL ORDER BY created_at DESC`
+	rows, err := db.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []UserBlock{}
+	for rows.Next() {
+		var block UserBlock
+		if err := rows.Scan(&block.ID, &block.Type, &block.Reason, &block.CreatedBy, &block.CreatedAt); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, rows.Err()
+}
+
+func roleExists(role string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM roles WHERE name = ?", role).Scan(&count)
+	return count > 0, err
+}
+
+// 사용자의 모든 세션을 Redis에서 삭제하고 삭제한 세션 수를 반환
+func invalidateUserSessions(username string) int {
+	userSessionsKey := "user_sessions:" + username
+	tokens, err := redisClient.SMembers(ctx, userSessionsKey).Result()
+	if err != nil {
+		log.Printf("Redis error: %v", err)
+		return 0
+	}
+
+	keys := []string{userSessionsKey}
+	for _, token := range tokens {
+		keys = append(keys, "session:"+token)
+	}
+
+	if err := redisClient.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("Redis error: %v", err)
+		return 0
+	}
+	return len(tokens)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LibraryCard struct {
+	CardNumber string    `json:"card_number"`
+	UserID     string    `json:"user_id"`
+	Status     string    `json:"status"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  string    `json:"expires_at"`
+	IssuedBy   string    `json:"issued_by"`
+	ReplacedBy *string   `json:"replaced_by,omitempty"`
+}
+
+const (
+	// 카드 번호: 기관 코드 2자리 + 일련번호 10자리 + 체크 숫자 1자리
+	cardNumberPrefix = "29"
+	cardNumberLength = 13
+	cardSerialDigits = 10
+	// 카드 유효 기간 (년)
+	cardValidYears = 5
+)
+
+// 관리자: 카드 번호(바코드)로 이용자 조회
+func handleAdminLookupCard(c *gin.Context) {
+	cardNumber := c.Param("card_number")
+
+	if !isValidCardNumber(cardNumber) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "올바른 카드 번호가 아닙니다"})
+		return
+	}
+
+	card, err := getLibraryCard(cardNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "등록되지 않은 카드입니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 조회에 실패했습니다"})
+		return
+	}
+
+	user, err := getUserSummary(card.UserID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이용자 조회에 실패했습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"card": card, "user": user})
+}
+
+// 관리자: 이용자 카드 목록 조회 (교체/분실 이력 포함)
+func handleAdminGetUserCards(c *gin.Context) {
+	username := c.Param("username")
+
+	query := `SELECT card_number, user_id, status, issued_at, expires_at, issued_by, replaced_by
+	          FROM library_cards WHERE user_id = ? ORDER BY issued_at DESC`
+	rows, err := db.Query(query, username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 목록을 불러오는데 실패했습니다"})
+		return
+	}
+	defer rows.Close()
+
+	cards := []LibraryCard{}
+	for rows.Next() {
+		var card LibraryCard
+		err := rows.Scan(&card.CardNumber, &card.UserID, &card.Status, &card.IssuedAt,
+			&card.ExpiresAt, &card.IssuedBy, &card.ReplacedBy)
+		if err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		cards = append(cards, card)
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+// 관리자: 카드 발급 또는 재발급 (기존 active 카드는 무효화)
+func handleAdminIssueCard(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	adminIDStr := adminID.(string)
+	username := c.Param("username")
+
+	var req struct {
+		// 기존 카드 처리 사유: replaced(교체) 또는 lost(분실)
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&req)
+
+	if req.Reason == "" {
+		req.Reason = "replaced"
+	}
+	if req.Reason != "replaced" && req.Reason != "lost" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason은 replaced 또는 lost여야 합니다"})
+		return
+	}
+
+	if _, err := getUserSummary(username); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 발급에 실패했습니다"})
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 발급에 실패했습니다"})
+		return
+	}
+	defer tx.Rollback()
+
+	card, previous, err := issueLibraryCard(tx, username, adminIDStr, req.Reason)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 발급에 실패했습니다"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 발급에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %s issued card %s for user %s (previous: %v)", adminIDStr, card.CardNumber, username, previous)
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "카드가 발급되었습니다",
+		"card":             card,
+		"invalidated_card": previous,
+	})
+}
+
+// 관리자: 카드 상태 변경 (active, lost, expired)
+func handleAdminUpdateCardStatus(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+	cardNumber := c.Param("card_number")
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+		// 유효 기간이 지난 카드를 다시 active로 바꿀 때 새 만료일 (YYYY-MM-DD)
+		ExpiresAt string `json:"expires_at"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.Status != "active" && req.Status != "lost" && req.Status != "expired" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status는 active, lost, expired 중 하나여야 합니다"})
+		return
+	}
+
+	card, err := getLibraryCard(cardNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "등록되지 않은 카드입니다"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 상태 변경에 실패했습니다"})
+		return
+	}
+
+	// 교체된 카드는 다시 사용할 수 없음
+	if card.Status == "replaced" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "교체된 카드는 상태를 변경할 수 없습니다"})
+		return
+	}
+
+	if req.Status == "active" {
+		var otherActive int
+		query := `SELECT COUNT(*) FROM library_cards WHERE user_id = ? AND status = 'active' AND card_number <> ?`
+		if err := db.QueryRow(query, card.UserID, cardNumber).Scan(&otherActive); err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 상태 변경에 실패했습니다"})
+			return
+		}
+		if otherActive > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "이미 사용 중인 다른 카드가 있습니다"})
+			return
+		}
+	}
+
+	// 유효 기간이 지난 카드는 새 만료일을 함께 지정해야 다시 사용할 수 있다 (만료일이 지나면 조회에서 거부된다)
+	expiresAt := card.ExpiresAt
+	if req.ExpiresAt != "" {
+		if req.Status != "active" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "만료일은 카드를 active로 바꿀 때만 지정할 수 있습니다"})
+			return
+		}
+		_, err := time.Parse("2006-01-02", req.ExpiresAt)
+		if err != nil || req.ExpiresAt <= time.Now().Format("2006-01-02") ||
+			req.ExpiresAt > time.Now().AddDate(cardValidYears, 0, 0).Format("2006-01-02") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "만료일은 오늘 이후, 발급 유효 기간(5년) 이내의 날짜(YYYY-MM-DD)여야 합니다"})
+			return
+		}
+		expiresAt = req.ExpiresAt
+	}
+	if req.Status == "active" && expiresAt < time.Now().Format("2006-01-02") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효 기간이 지난 카드입니다. 새 만료일(expires_at)을 지정하거나 카드를 재발급하세요", "expires_at": card.ExpiresAt})
+		return
+	}
+
+	if _, err := db.Exec("UPDATE library_cards SET status = ?, expires_at = ? WHERE card_number = ?", req.Status, expiresAt, cardNumber); err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "카드 상태 변경에 실패했습니다"})
+		return
+	}
+
+	log.Printf("Admin %v changed card %s status to %s", adminID, cardNumber, req.Status)
+	c.JSON(http.StatusOK, gin.H{"message": "카드 상태가 변경되었습니다", "status": req.Status, "expires_at": expiresAt})
+}
+
+// 새 카드를 발급하고, 기존 active 카드가 있으면 지정한 사유로 무효화한다.
+// 무효화된 카드 번호를 함께 반환한다.
+func issueLibraryCard(tx *sql.Tx, username, issuedBy, reason string) (LibraryCard, *string, error) {
+	var previous *string
+	var oldNumber string
+	err := tx.QueryRow(`SELECT card_number FROM library_cards
+	                    WHERE user_id = ? AND status = 'active' FOR UPDATE`, username).Scan(&oldNumber)
+	if err != nil && err != sql.ErrNoRows {
+		return LibraryCard{}, nil, err
+	}
+
+	var cardNumber string
+	for {
+		cardNumber, err = generateCardNumber()
+		if err != nil {
+			return LibraryCard{}, nil, err
+		}
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM library_cards WHERE card_number = ?", cardNumber).Scan(&exists); err != nil {
+			return LibraryCard{}, nil, err
+		}
+		if exists == 0 {
+			break
+		}
+	}
+
+	expiresAt := time.Now().AddDate(cardValidYears, 0, 0).Format("2006-01-02")
+	insertQuery := `INSERT INTO library_cards (card_number, user_id, status, expires_at, issued_by)
+	                VALUES (?, ?, 'active', ?, ?)`
+	if _, err := tx.Exec(insertQuery, cardNumber, username, expiresAt, issuedBy); err != nil {
+		return LibraryCard{}, nil, err
+	}
+
+	if oldNumber != "" {
+		updateQuery := `UPDATE library_cards SET status = ?, replaced_by = ? WHERE card_number = ?`
+		if _, err := tx.Exec(updateQuery, reason, cardNumber, oldNumber); err != nil {
+			return LibraryCard{}, nil, err
+		}
+		previous = &oldNumber
+	}
+
+	card := LibraryCard{
+		CardNumber: cardNumber,
+		UserID:     username,
+		Status:     "active",
+		IssuedAt:   time.Now(),
+		ExpiresAt:  expiresAt,
+		IssuedBy:   issuedBy,
+	}
+	return card, previous, nil
+}
+
+// 카드 조회 (유효 기간이 지난 active 카드는 expired로 표시)
+func getLibraryCard(cardNumber string) (LibraryCard, error) {
+	var card LibraryCard
+	var expiresAt time.Time
+	query := `SELECT card_number, user_id, status, issued_at, expires_at, issued_by, replaced_by
+	          FROM library_cards WHERE card_number = ?`
+	err := db.QueryRow(query, cardNumber).Scan(&card.CardNumber, &card.UserID, &card.Status,
+		&card.IssuedAt, &expiresAt, &card.IssuedBy, &card.ReplacedBy)
+	if err != nil {
+		return card, err
+	}
+
+	card.ExpiresAt = expiresAt.Format("2006-01-02")
+	if card.Status == "active" && expiresAt.Before(time.Now()) {
+		card.Status = "expired"
+	}
+	return card, nil
+}
+
+// 이용자의 현재 active 카드 번호 조회 (없으면 nil)
+func getActiveCardNumber(username string) (*string, error) {
+	var cardNumber string
+	query := `SELECT card_number FROM library_cards
+	          WHERE user_id = ? AND status = 'active' AND expires_at >= CURDATE()`
+	err := db.QueryRow(query, username).Scan(&cardNumber)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cardNumber, nil
+}
+
+func generateCardNumber() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(cardSerialDigits), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	serial := n.String()
+	for len(serial) < cardSerialDigits {
+		serial = "0" + serial
+	}
+
+	body := cardNumberPrefix + serial
+	return body + string(luhnCheckDigit(body)), nil
+}
+
+// Luhn 체크 숫자 계산 (body는 숫자 문자열)
+func luhnCheckDigit(body string) byte {
+	sum := 0
+	double := true
+	for i := len(body) - 1; i >= 0; i-- {
+		d := int(body[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isValidCardNumber(cardNumber string) bool {
+	if len(cardNumber) != cardNumberLength {
+		return false
+	}
+	for i := 0; i < len(cardNumber); i++ {
+		if cardNumber[i] < '0' || cardNumber[i] > '9' {
+			return false
+		}
+	}
+	body := cardNumber[:len(cardNumber)-1]
+	return luhnCheckDigit(body) == cardNumber[len(cardNumber)-1]
+}
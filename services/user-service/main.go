@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +33,13 @@ var (
 	ctx         = context.Background()
 )
 
+const (
+	// 세션 유효 시간
+	sessionTTL = 24 * time.Hour
+	// 역할별 권한 캐시 유효 시간
+	permissionCacheTTL = 5 * time.Minute
+)
+
 func main() {
 	// .env.local 파일 로드 (파일이 없어도 에러 무시)
 	if err := godotenv.Load("../../.env.local"); err != nil {
@@ -86,6 +94,26 @@ func main() {
 	router.POST("/users/login", handleLogin)
 	router.POST("/users/logout", handleLogout)
 
+	// 내 계정 API
+	router.GET("/users/me", authMiddleware(), handleGetMe)
+	router.PUT("/users/me", authMiddleware(), handleUpdateMe)
+
+	// 이용자 관리 API (관리자용)
+	router.GET("/users/admin/users", authMiddleware(), requirePermission("users:read"), handleAdminListUsers)
+	router.GET("/users/admin/users/:username", authMiddleware(), requirePermission("users:read"), handleAdminGetUser)
+	router.POST("/users/admin/users", authMiddleware(), requirePermission("users:manage"), handleAdminCreateUser)
+	router.PUT("/users/admin/users/:username", authMiddleware(), requirePermission("users:manage"), handleAdminUpdateUser)
+	router.PUT("/users/admin/users/:username/role", authMiddleware(), requirePermission("users:manage"), handleAdminChangeRole)
+	router.PUT("/users/admin/users/:username/membership", authMiddleware(), requirePermission("users:manage"), handleAdminChangeMembership)
+	router.POST("/users/admin/users/:username/suspend", authMiddleware(), requirePermission("users:manage"), handleAdminSuspendUser)
+	router.POST("/users/admin/users/:username/reactivate", authMiddleware(), requirePermission("users:manage"), handleAdminReactivateUser)
+
+	// 도서관 이용증(카드) API (관리자용)
+	router.GET("/users/admin/cards/:card_number", authMiddleware(), requirePermission("users:read"), handleAdminLookupCard)
+	router.PUT("/users/admin/cards/:card_number/status", authMiddleware(), requirePermission("users:manage"), handleAdminUpdateCardStatus)
+	router.GET("/users/admin/users/:username/cards", authMiddleware(), requirePermission("users:read"), handleAdminGetUserCards)
+	router.POST("/users/admin/users/:username/cards", authMiddleware(), requirePermission("users:manage"), handleAdminIssueCard)
+
 	// 서버 시작
 	port := getEnv("USER_SERVICE_PORT", getEnv("PORT", "8081"))
 	log.Printf("User service starting on port %s", port)
@@ -102,9 +130,9 @@ func handleLogin(c *gin.Context) {
 	}
 
 	// MariaDB에서 사용자 조회
-	var userID, username, storedPassword, role string
-	query := "SELECT id, username, password, role FROM users WHERE username = ?"
-	err := db.QueryRow(query, req.ID).Scan(&userID, &username, &storedPassword, &role)
+	var userID, username, storedPassword, role, status string
+	query := "SELECT id, username, password, role, status FROM users WHERE username = ?"
+	err := db.QueryRow(query, req.ID).Scan(&userID, &username, &storedPassword, &role, &status)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
@@ -121,18 +149,31 @@ func handleLogin(c *gin.Context) {
 		return
 	}
 
+	// 정지된 계정은 로그인 불가
+	if status != "active" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "이용이 정지된 계정입니다"})
+		return
+	}
+
 	// 세션 토큰 생성
 	token := uuid.New().String()
 
 	// Redis에 세션 저장 (24시간 유효) - username을 저장
 	sessionKey := "session:" + token
-	err = redisClient.Set(ctx, sessionKey, username, 24*time.Hour).Err()
+	err = redisClient.Set(ctx, sessionKey, username, sessionTTL).Err()
 	if err != nil {
 		log.Printf("Redis error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
+	// 사용자별 세션 목록 기록 (계정 정지 시 일괄 무효화용)
+	userSessionsKey := "user_sessions:" + username
+	if err := redisClient.SAdd(ctx, userSessionsKey, token).Err(); err != nil {
+		log.Printf("Redis error: %v", err)
+	}
+	redisClient.Expire(ctx, userSessionsKey, sessionTTL)
+
 	log.Printf("User %s logged in successfully with token %s (role: %s)", username, token, role)
 
 	c.JSON(http.StatusOK, LoginResponse{
@@ -156,6 +197,7 @@ func handleLogout(c *gin.Context) {
 
 	// Redis에서 세션 삭제
 	sessionKey := "session:" + token
+	username, _ := redisClient.Get(ctx, sessionKey).Result()
 	err := redisClient.Del(ctx, sessionKey).Err()
 	if err != nil {
 		log.Printf("Redis error: %v", err)
@@ -163,11 +205,143 @@ func handleLogout(c *gin.Context) {
 		return
 	}
 
+	if username != "" {
+		redisClient.SRem(ctx, "user_sessions:"+username, token)
+	}
+
 	log.Printf("Session %s logged out successfully", token)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization token"})
+			c.Abort()
+			return
+		}
+
+		if len(token) > 7 && token[:7] == "Bearer " {
+			token = token[7:]
+		}
+
+		sessionKey := "session:" + token
+		username, err := redisClient.Get(ctx, sessionKey).Result()
+		if err != nil {
+			if err == redis.Nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+				c.Abort()
+				return
+			}
+			log.Printf("Redis error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+			c.Abort()
+			return
+		}
+
+		// 사용자 역할 및 상태 조회 (username으로 조회)
+		var role, status string
+		query := "SELECT role, status FROM users WHERE username = ?"
+		err = db.QueryRow(query, username).Scan(&role, &status)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user role"})
+			c.Abort()
+			return
+		}
+
+		// 정지된 계정의 세션은 유효하지 않음
+		if status != "active" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", username)
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// 역할에 지정된 권한이 있는지 확인 (authMiddleware 다음에 사용)
+// requirePermission, getRolePermissions, permissionSet은 book-service, borrow-service, user-service에
+// 같은 코드로 들어 있다 (서비스마다 Go 모듈이 따로여서 공유 패키지가 없다).
+// 세 서비스가 Redis 캐시 키(role_permissions:<역할>)를 함께 쓰므로 고칠 때는 세 곳을 똑같이 고친다.
+func requirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
+			c.Abort()
+			return
+		}
+
+		permissions, err := getRolePermissions(role.(string))
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "권한 확인에 실패했습니다"})
+			c.Abort()
+			return
+		}
+
+		if !permissions[permission] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "권한이 없습니다", "permission": permission})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// 역할별 권한 목록 조회 (Redis 캐시 우선, 없으면 DB 조회 후 캐시)
+func getRolePermissions(role string) (map[string]bool, error) {
+	cacheKey := "role_permissions:" + role
+	cached, err := redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		return permissionSet(strings.Split(cached, ",")), nil
+	}
+	if err != redis.Nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	rows, err := db.Query("SELECT permission FROM role_permissions WHERE role = ?", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := redisClient.Set(ctx, cacheKey, strings.Join(names, ","), permissionCacheTTL).Err(); err != nil {
+		log.Printf("Redis error: %v", err)
+	}
+
+	return permissionSet(names), nil
+}
+
+func permissionSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
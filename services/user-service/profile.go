@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 내 계정 정보 (UI가 계정 상태를 표시할 때 사용하는 단일 응답)
+type MyProfile struct {
+	Username           string           `json:"username"`
+	DisplayName        string           `json:"display_name"`
+	Email              string           `json:"email"`
+	Phone              string           `json:"phone"`
+	PreferredLanguage  string           `json:"preferred_language"`
+	MembershipType     string           `json:"membership_type"`
+	CardNumber         *string          `json:"card_number"`
+	Role               string           `json:"role"`
+	Status             string           `json:"status"`
+	Permissions        []string         `json:"permissions"`
+	CurrentLoans       int              `json:"current_loans"`
+	OverdueLoans       int              `json:"overdue_loans"`
+	ActiveReservations int              `json:"active_reservations"`
+	Limits             MembershipLimits `json:"limits"`
+	OutstandingFines   float64          `json:"outstanding_fines"`
+	Blocks             []UserBlock      `json:"blocks"`
+}
+
+// 회원 유형별 대출 한도
+type MembershipLimits struct {
+	MaxLoans        int `json:"max_loans"`
+	MaxReservations int `json:"max_reservations"`
+	LoanDays        int `json:"loan_days"`
+	MaxRenewals     int `json:"max_renewals"`
+}
+
+// 이용자의 회원 유형이 membership_types에 없을 때 (이용자를 찾지 못한 sql.ErrNoRows와 구분한다)
+var errMembershipMissing = errors.New("membership type not found")
+
+// 지원하는 선호 언어
+var supportedLanguages = map[string]bool{
+	"ko": true,
+	"en": true,
+}
+
+// 내 계정 정보 조회
+func handleGetMe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	username := userID.(string)
+
+	profile, err := loadMyProfile(username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "이용자를 찾을 수 없습니다"})
+			return
+		}
+		if err == errMembershipMissing {
+			log.Printf("Membership type missing for user %s", username)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "회원 유형 정보가 없습니다. 관리자에게 문의하세요"})
+			return
+		}
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "계정 정보를 불러오는데 실패했습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// 내 계정 정보 수정 (이름, 연락처, 선호 언어)
+func handleUpdateMe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	username := userID.(string)
+
+	var req struct {
+		DisplayName       string `json:"display_name"`
+		Email             string `json:"email"`
+		Phone             string `json:"phone"`
+		PreferredLanguage string `json:"preferred_language"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "올바른 이메일 주소가 아닙니다"})
+			return
+		}
+	}
+	if req.PreferredLanguage == "" {
+		req.PreferredLanguage = "ko"
+	}
+	if !supportedLanguages[req.PreferredLanguage] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 언어입니다"})
+		return
+	}
+
+	query := `UPDATE users SET display_name = ?, email = ?, phone = ?, preferred_language = ?
+	          WHERE username = ?`
+	_, err := db.Exec(query, req.DisplayName, req.Email, req.Phone, req.PreferredLanguage, username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "계정 정보 수정에 실패했습니다"})
+		return
+	}
+
+	profile, err := loadMyProfile(username)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "계정 정보를 불러오는데 실패했습니다"})
+		return
+	}
+
+	log.Printf("User %s updated own profile", username)
+	c.JSON(http.StatusOK, profile)
+}
+
+func loadMyProfile(username string) (MyProfile, error) {
+	var profile MyProfile
+	query := `SELECT username, display_name, email, phone, preferred_language, membership_type, role, status
+	          FROM users WHERE username = ?`
+	err := db.QueryRow(query, username).Scan(
+		&profile.Username,
+		&profile.DisplayName,
+		&profile.Email,
+		&profile.Phone,
+		&profile.PreferredLanguage,
+		&profile.MembershipType,
+		&profile.Role,
+		&profile.Status,
+	)
+	if err != nil {
+		return profile, err
+	}
+
+	permissions, err := getRolePermissions(profile.Role)
+	if err != nil {
+		return profile, err
+	}
+	profile.Permissions = []string{}
+	for name := range permissions {
+		profile.Permissions = append(profile.Permissions, name)
+	}
+	sort.Strings(profile.Permissions)
+
+	loanQuery := `SELECT COUNT(*),
+	              COALESCE(SUM(CASE WHEN due_date < CURDATE() THEN 1 ELSE 0 END), 0)
+	              FROM borrows WHERE user_id = ? AND status = 'borrowed'`
+	if err := db.QueryRow(loanQuery, username).Scan(&profile.CurrentLoans, &profile.OverdueLoans); err != nil {
+		return profile, err
+	}
+
+	reservationQuery := `SELECT COUNT(*) FROM reservations WHERE user_id = ? AND status = 'active'`
+	if err := db.QueryRow(reservationQuery, username).Scan(&profile.ActiveReservations); err != nil {
+		return profile, err
+	}
+
+	profile.Limits, err = getMembershipLimits(profile.MembershipType)
+	if err == sql.ErrNoRows {
+		return profile, errMembershipMissing
+	}
+	if err != nil {
+		return profile, err
+	}
+
+	profile.CardNumber, err = getActiveCardNumber(username)
+	if err != nil {
+		return profile, err
+	}
+
+	fineQuery := `SELECT COALESCE(SUM(amount), 0) FROM fines WHERE user_id = ? AND status = 'unpaid'`
+	if err := db.QueryRow(fineQuery, username).Scan(&profile.OutstandingFines); err != nil {
+		return profile, err
+	}
+
+	profile.Blocks, err = getActiveBlocks(username)
+	return profile, err
+}
+
+func getMembershipLimits(membershipType string) (MembershipLimits, error) {
+	var limits MembershipLimits
+	query := `SELECT max_loans, max_reservations, loan_days, max_renewals
+	          FROM membership_types WHERE name = ?`
+	err := db.QueryRow(query, membershipType).Scan(
+		&limits.MaxLoans,
+		&limits.MaxReservations,
+		&limits.LoanDays,
+		&limits.MaxRenewals,
+	)
+	return limits, err
+}